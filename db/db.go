@@ -22,15 +22,27 @@ type User struct {
 	Username string
 	Password string
 
+	// Public profile; a hidden profile is only visible to its owner and cannot be followed
+	DisplayName   string
+	AvatarURL     string
+	Bio           string
+	ProfileHidden bool
+
 	// EXPLICIT TAGS ADDED HERE:
 	SpotifyID    string `gorm:"column:spotify_id"`
 	YouTubeID    string `gorm:"column:youtube_id"`
 	AppleMusicID string `gorm:"column:applemusic_id"`
+	DeezerID     string `gorm:"column:deezer_id"`
+	TidalID      string `gorm:"column:tidal_id"`
+	SoundCloudID string `gorm:"column:soundcloud_id"`
 
 	GoogleToken     string `gorm:"column:google_token"`
 	SpotifyToken    string `gorm:"column:spotify_token"`
 	YouTubeToken    string `gorm:"column:youtube_token"`
 	AppleMusicToken string `gorm:"column:applemusic_token"`
+	DeezerToken     string `gorm:"column:deezer_token"` // offline_access token, does not expire
+	TidalToken      string `gorm:"column:tidal_token"`
+	SoundCloudToken string `gorm:"column:soundcloud_token"`
 
 	SpotifyTokenExpiry  time.Time `gorm:"column:spotify_token_expiry"`
 	SpotifyRefreshToken string    `gorm:"column:spotify_refresh_token"`
@@ -38,6 +50,32 @@ type User struct {
 	YouTubeTokenExpiry  time.Time `gorm:"column:youtube_token_expiry"`
 	YouTubeRefreshToken string    `gorm:"column:youtube_refresh_token"`
 
+	TidalTokenExpiry  time.Time `gorm:"column:tidal_token_expiry"`
+	TidalRefreshToken string    `gorm:"column:tidal_refresh_token"`
+
+	SoundCloudTokenExpiry  time.Time `gorm:"column:soundcloud_token_expiry"`
+	SoundCloudRefreshToken string    `gorm:"column:soundcloud_refresh_token"`
+
+	// Apple Music has no OAuth refresh; AppleMusicToken holds the Music-User-Token
+	AppleMusicStorefront string `gorm:"column:applemusic_storefront"`
+
+	// Subsonic-compatible servers use token auth: md5(password+salt) and the salt, never the password
+	SubsonicURL      string `gorm:"column:subsonic_url"`
+	SubsonicUsername string `gorm:"column:subsonic_username"`
+	SubsonicToken    string `gorm:"column:subsonic_token"`
+	SubsonicSalt     string `gorm:"column:subsonic_salt"`
+
+	// Jellyfin/Emby: JellyfinToken is either an API key or an AuthenticateByName access token
+	JellyfinURL    string `gorm:"column:jellyfin_url"`
+	JellyfinUserID string `gorm:"column:jellyfin_user_id"`
+	JellyfinToken  string `gorm:"column:jellyfin_token"`
+
+	// Listening history sources
+	LastFMUsername       string `gorm:"column:lastfm_username"`
+	LastFMSessionKey     string `gorm:"column:lastfm_session_key"`
+	ListenBrainzUsername string `gorm:"column:listenbrainz_username"`
+	ListenBrainzToken    string `gorm:"column:listenbrainz_token"`
+
 	TokenExpiry  time.Time
 	RefreshToken string
 	CreatedAt    time.Time
@@ -55,23 +93,93 @@ type Playlist struct {
 	SourceID    string
 	IsPublic    bool
 	CoverImage  string
-	Category    string // New field for AI categorization
+	Category    string     // New field for AI categorization
+	ViewCount   int        // page views by anyone but the owner
+	ImportCount int        // imports of the playlist to a platform by other users
+	PublishedAt *time.Time // when the playlist last became public; nil for playlists never published
 	CreatedAt   time.Time
 	Tracks      []Track `gorm:"foreignKey:PlaylistID"`
 }
 
 // Track represents a track in the database
 type Track struct {
-	ID           uuid.UUID `gorm:"type:uuid;primaryKey"`
-	PlaylistID   uuid.UUID `gorm:"type:uuid"`
-	Title        string
-	Artist       string
-	Album        string
-	SpotifyID    string
-	YouTubeID    string
-	AppleMusicID string
-	PreviewURL   string // URL to 30s preview (from Spotify)
-	CreatedAt    time.Time
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey"`
+	PlaylistID    uuid.UUID `gorm:"type:uuid;index"`
+	Title         string
+	Artist        string
+	Album         string
+	SpotifyID     string
+	YouTubeID     string
+	AppleMusicID  string
+	DeezerID      string
+	TidalID       string
+	SubsonicID    string
+	JellyfinID    string
+	SoundCloudID  string `gorm:"column:soundcloud_id"`
+	SoundCloudURL string `gorm:"column:soundcloud_url"`       // permalink; SoundCloud has no ID-based public track URL
+	ISRC          string `gorm:"index"`                       // International Standard Recording Code, when the source provides it
+	MusicBrainzID string `gorm:"column:musicbrainz_id;index"` // MusicBrainz recording/track ID, from self-hosted libraries
+	DurationMs    int
+	PreviewURL    string // URL to 30s preview (from Spotify)
+	Position      int    // order within the playlist; imported tracks keep 0 and fall back to created_at
+	CreatedAt     time.Time
+}
+
+// PlaylistMember gives another user access to a playlist. The owner is Playlist.OwnerID
+// and has no member row. UserID stays nil until an invitee without an account signs up.
+type PlaylistMember struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	PlaylistID uuid.UUID  `gorm:"type:uuid;uniqueIndex:idx_playlist_member_email"`
+	UserID     *uuid.UUID `gorm:"type:uuid;index"`
+	Email      string     `gorm:"uniqueIndex:idx_playlist_member_email"` // lowercased invite address
+	Role       string     // "editor" or "viewer"
+	Status     string     // "pending" or "accepted"
+	InvitedBy  uuid.UUID  `gorm:"type:uuid"`
+	SyncOptIn  bool       // the owner may push the playlist to the member's linked accounts
+	AcceptedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// PlaylistActivity records who changed what on a playlist
+type PlaylistActivity struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	PlaylistID uuid.UUID `gorm:"type:uuid;index"`
+	UserID     uuid.UUID `gorm:"type:uuid"`
+	Action     string    // e.g. "track_added", "track_removed", "tracks_reordered", "member_invited"
+	Details    string    // JSON object describing the change
+	CreatedAt  time.Time
+}
+
+// PlaylistVersion is a snapshot of a playlist's tracks taken after each change, so an
+// earlier state can be compared with or restored
+type PlaylistVersion struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	PlaylistID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_playlist_version"`
+	Number     int       `gorm:"uniqueIndex:idx_playlist_version"` // 1-based, increasing per playlist
+	Trigger    string    // "import", "sync", "edit" or "restore"
+	Action     string    // the change, e.g. "imported", "track_added", "version_restored"
+	UserID     uuid.UUID `gorm:"type:uuid"`
+	TrackCount int
+	Tracks     string // JSON array of the tracks in playlist order
+	CreatedAt  time.Time
+}
+
+// PlaylistEvent records a view or import of a playlist, for rankings over recent activity.
+// Playlist.ViewCount and ImportCount hold the all-time totals.
+type PlaylistEvent struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	PlaylistID uuid.UUID `gorm:"type:uuid;index"`
+	Kind       string    `gorm:"index:idx_playlist_event_kind_time"` // "view" or "import"
+	Viewer     string    `gorm:"index"`                              // hash identifying the viewer, for de-duplicating views
+	CreatedAt  time.Time `gorm:"index:idx_playlist_event_kind_time"`
+}
+
+// UserFollow makes a user's newly published and updated public playlists show up in a follower's feed
+type UserFollow struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	FollowerID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_user_follow"`
+	FolloweeID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_user_follow;index"`
+	CreatedAt  time.Time
 }
 
 // Share represents a shared track link
@@ -79,23 +187,78 @@ type Share struct {
 	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
 	TrackID   uuid.UUID `gorm:"type:uuid"`
 	UserID    uuid.UUID `gorm:"type:uuid"`
+	Code      string    `gorm:"uniqueIndex"` // short code served at /s/<code>
 	CustomURL string
+	Views     int // landing page views
 	CreatedAt time.Time
 }
 
+// ShareClick records a visitor following a share link to a platform
+type ShareClick struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ShareID   uuid.UUID `gorm:"type:uuid;index"`
+	Platform  string
+	CreatedAt time.Time
+}
+
+// PlaylistShareToken grants access to a playlist through an unlisted link, even when the
+// playlist is private
+type PlaylistShareToken struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	PlaylistID uuid.UUID `gorm:"type:uuid;index"`
+	Token      string    `gorm:"uniqueIndex"`
+	Permission string    // "view" or "import"
+	ExpiresAt  *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// ResolvedTrack caches a song resolved from a track link, with its ID on each platform
+type ResolvedTrack struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Title        string
+	Artist       string
+	Album        string
+	ISRC         string `gorm:"index"`
+	DurationMs   int
+	ArtworkURL   string
+	SpotifyID    string `gorm:"index"`
+	YouTubeID    string `gorm:"index"`
+	AppleMusicID string `gorm:"column:applemusic_id;index"`
+	DeezerID     string `gorm:"index"`
+	NotFound     bool   // the source platform has no such track; kept so repeated lookups stay local
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
 // SyncJob represents a background sync job
 type SyncJob struct {
 	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
 	UserID      uuid.UUID `gorm:"type:uuid"`
 	PlaylistID  uuid.UUID `gorm:"type:uuid"`
 	Platforms   string    // JSON array: ["spotify", "youtube"]
-	Status      string    // "pending", "processing", "completed", "failed"
+	Status      string    // "pending", "processing", "completed", "failed", "interrupted"
+	Runner      string    // "worker" or "temporal"
 	Result      string    // JSON: {"spotify": "playlist_id", "youtube": "playlist_id"}
 	ErrorMsg    string    // Error message if failed
+	Replace     bool      // clear the destination playlists in Result before the first run fills them
 	CreatedAt   time.Time
 	CompletedAt *time.Time
 }
 
+// SyncCheckpoint records the outcome of one track within a sync job so an
+// interrupted job can be resumed without re-adding tracks
+type SyncCheckpoint struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	JobID      uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_sync_checkpoint"`
+	Platform   string    `gorm:"uniqueIndex:idx_sync_checkpoint"`
+	TrackID    uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_sync_checkpoint"`
+	Status     string    // "added", "not_found", "failed"
+	ExternalID string    // Track or video ID on the destination platform
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
 // ConnectDatabase initializes the database connection
 // ConnectDatabase initializes the database connection
 func ConnectDatabase() error {
@@ -129,5 +292,29 @@ func ConnectDatabase() error {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	return DB.AutoMigrate(&User{}, &Playlist{}, &Track{}, &Share{}, &SyncJob{})
+	if err := DB.AutoMigrate(&User{}, &Playlist{}, &Track{}, &PlaylistMember{}, &PlaylistActivity{}, &PlaylistVersion{}, &PlaylistEvent{}, &UserFollow{}, &Share{}, &ShareClick{}, &PlaylistShareToken{}, &SyncJob{}, &SyncCheckpoint{}, &ResolvedTrack{}); err != nil {
+		return err
+	}
+	return createSearchIndexes()
+}
+
+// Full-text documents searched by playlist browsing. Queries must use the same expressions
+// for Postgres to use the indexes below.
+const (
+	PlaylistSearchDocument = "to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(description, ''))"
+	TrackSearchDocument    = "to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(artist, ''))"
+)
+
+// createSearchIndexes adds the GIN expression indexes for full-text search, which GORM tags
+// cannot express
+func createSearchIndexes() error {
+	for _, stmt := range []string{
+		"CREATE INDEX IF NOT EXISTS idx_playlists_search ON playlists USING gin (" + PlaylistSearchDocument + ")",
+		"CREATE INDEX IF NOT EXISTS idx_tracks_search ON tracks USING gin (" + TrackSearchDocument + ")",
+	} {
+		if err := DB.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to create search index: %w", err)
+		}
+	}
+	return nil
 }
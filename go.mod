@@ -14,6 +14,7 @@ require (
 	go.temporal.io/sdk v1.39.0
 	golang.org/x/crypto v0.42.0
 	golang.org/x/oauth2 v0.31.0
+	golang.org/x/time v0.13.0
 	google.golang.org/api v0.251.0
 	gorm.io/driver/postgres v1.6.0
 	gorm.io/gorm v1.31.0
@@ -80,7 +81,6 @@ require (
 	golang.org/x/sync v0.17.0 // indirect
 	golang.org/x/sys v0.36.0 // indirect
 	golang.org/x/text v0.29.0 // indirect
-	golang.org/x/time v0.13.0 // indirect
 	golang.org/x/tools v0.36.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20250707201910-8d1bb00bc6a7 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250929231259-57b25ae835d4 // indirect
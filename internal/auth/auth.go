@@ -7,9 +7,11 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"EchoBridge/db"
+	"EchoBridge/internal/services"
 	"EchoBridge/internal/worker"
 
 	"github.com/gin-gonic/gin"
@@ -34,10 +36,23 @@ var (
 	googleOAuthConfig  *oauth2.Config
 	spotifyAuth        *spotifyauth.Authenticator
 	youtubeOAuthConfig *oauth2.Config
+	tidalOAuthConfig   *oauth2.Config
+	soundCloudConfig   *oauth2.Config
 	state              = "random-state-string"
 	WorkerPool         *worker.WorkerPool
+
+	// Pending OAuth links, keyed by the state nonce. TIDAL and SoundCloud also keep their PKCE
+	// verifier here; other providers only use the nonce to find the user again in the callback.
+	pkceMu      sync.Mutex
+	pkcePending = map[string]pkceSession{}
 )
 
+type pkceSession struct {
+	UserID   uuid.UUID
+	Verifier string
+	Expires  time.Time
+}
+
 // InitAuth initializes OAuth configurations - must be called after .env is loaded
 func InitAuth() {
 	if url := os.Getenv("FRONTEND_URL"); url != "" {
@@ -98,6 +113,12 @@ func InitAuth() {
 		},
 		Endpoint: google.Endpoint,
 	}
+
+	// 4. Setup TIDAL Config
+	tidalOAuthConfig = services.GetTidalOAuthConfig()
+
+	// 5. Setup SoundCloud Config
+	soundCloudConfig = services.GetSoundCloudOAuthConfig()
 }
 
 // --- MIDDLEWARE ---
@@ -121,35 +142,59 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method")
-			}
-			return jwtSecret, nil
-		})
-		if err != nil || !token.Valid {
+		userID, err := parseUserToken(tokenString)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid JWT", "details": err.Error()})
 			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid JWT claims"})
-			c.Abort()
-			return
-		}
+		c.Set("userID", userID.String())
+		c.Next()
+	}
+}
 
-		userIDStr, ok := claims["user_id"].(string)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user_id in JWT"})
-			c.Abort()
-			return
-		}
+// OptionalUserID returns the user of a valid Bearer token, for public routes that show more
+// to a logged-in user
+func OptionalUserID(c *gin.Context) (uuid.UUID, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return uuid.Nil, false
+	}
+	userID, err := parseUserToken(strings.TrimPrefix(authHeader, "Bearer "))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return userID, true
+}
 
-		c.Set("userID", userIDStr)
-		c.Next()
+// parseUserToken verifies a JWT from GenerateJWT and returns its user ID
+func parseUserToken(tokenString string) (uuid.UUID, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return jwtSecret, nil
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !token.Valid {
+		return uuid.Nil, fmt.Errorf("token is not valid")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return uuid.Nil, fmt.Errorf("invalid JWT claims")
 	}
+	userIDStr, ok := claims["user_id"].(string)
+	if !ok {
+		return uuid.Nil, fmt.Errorf("missing user_id in JWT")
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid user_id in JWT: %w", err)
+	}
+	return userID, nil
 }
 
 func GenerateJWT(userID uuid.UUID) (string, error) {
@@ -346,7 +391,6 @@ func SpotifyCallback(c *gin.Context) {
 	if err := updateUserService(c.Request.Context(), userID, "spotify", user.ID, user.Email, user.DisplayName, token); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link Spotify", "details": err.Error()})
 		return
-		return
 	}
 
 	// Redirect to settings with success parameter
@@ -409,15 +453,499 @@ func YouTubeCallback(c *gin.Context) {
 	if err := updateUserService(c.Request.Context(), userID, "youtube", channel.Id, "", channel.Snippet.Title, token); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link YouTube", "details": err.Error()})
 		return
-		return
 	}
 
 	// Redirect to settings with success parameter
 	c.Redirect(http.StatusFound, FrontendURL+"/settings?connected=youtube")
 }
 
+// TidalLink initiates TIDAL OAuth flow (authorization code with PKCE)
+func TidalLink(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	nonce, verifier := startPKCESession(userID)
+	url := tidalOAuthConfig.AuthCodeURL(nonce, oauth2.S256ChallengeOption(verifier))
+	c.Redirect(http.StatusFound, url)
+}
+
+// TidalCallback handles TIDAL OAuth callback
+func TidalCallback(c *gin.Context) {
+	if authError := c.Request.URL.Query().Get("error"); authError != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "TIDAL authorization failed", "details": authError})
+		return
+	}
+	nonce := c.Request.URL.Query().Get("state")
+	if nonce == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing state parameter"})
+		return
+	}
+
+	session, ok := takePKCESession(nonce)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired state parameter"})
+		return
+	}
+
+	code := c.Request.URL.Query().Get("code")
+	token, err := tidalOAuthConfig.Exchange(c.Request.Context(), code, oauth2.VerifierOption(session.Verifier))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Failed to get token", "details": err.Error()})
+		return
+	}
+
+	client := services.NewTidalClient(tidalOAuthConfig.Client(c.Request.Context(), token))
+	tidalUserID, err := services.GetTidalUser(c.Request.Context(), client)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user info", "details": err.Error()})
+		return
+	}
+
+	if err := updateUserService(c.Request.Context(), session.UserID, "tidal", tidalUserID, "", "", token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link TIDAL", "details": err.Error()})
+		return
+	}
+
+	// Redirect to settings with success parameter
+	c.Redirect(http.StatusFound, FrontendURL+"/settings?connected=tidal")
+}
+
+// SoundCloudLink initiates SoundCloud OAuth flow (authorization code with PKCE)
+func SoundCloudLink(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	nonce, verifier := startPKCESession(userID)
+	url := soundCloudConfig.AuthCodeURL(nonce, oauth2.S256ChallengeOption(verifier))
+	c.Redirect(http.StatusFound, url)
+}
+
+// SoundCloudCallback handles SoundCloud OAuth callback
+func SoundCloudCallback(c *gin.Context) {
+	if authError := c.Request.URL.Query().Get("error"); authError != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "SoundCloud authorization failed", "details": authError})
+		return
+	}
+	nonce := c.Request.URL.Query().Get("state")
+	if nonce == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing state parameter"})
+		return
+	}
+
+	session, ok := takePKCESession(nonce)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired state parameter"})
+		return
+	}
+
+	code := c.Request.URL.Query().Get("code")
+	token, err := soundCloudConfig.Exchange(c.Request.Context(), code, oauth2.VerifierOption(session.Verifier))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Failed to get token", "details": err.Error()})
+		return
+	}
+
+	client := services.NewSoundCloudClient(soundCloudConfig.Client(c.Request.Context(), token))
+	soundCloudID, _, err := services.GetSoundCloudUser(c.Request.Context(), client)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user info", "details": err.Error()})
+		return
+	}
+
+	if err := updateUserService(c.Request.Context(), session.UserID, "soundcloud", soundCloudID, "", "", token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link SoundCloud", "details": err.Error()})
+		return
+	}
+
+	// Redirect to settings with success parameter
+	c.Redirect(http.StatusFound, FrontendURL+"/settings?connected=soundcloud")
+}
+
+// DeezerLink initiates Deezer OAuth flow
+func DeezerLink(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	// Deezer has no PKCE; the session only ties the callback's state back to the user
+	nonce, _ := startPKCESession(userID)
+	c.Redirect(http.StatusFound, services.DeezerAuthURL(nonce))
+}
+
+// DeezerCallback handles Deezer OAuth callback
+func DeezerCallback(c *gin.Context) {
+	if reason := c.Request.URL.Query().Get("error_reason"); reason != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Deezer authorization failed", "details": reason})
+		return
+	}
+	nonce := c.Request.URL.Query().Get("state")
+	if nonce == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing state parameter"})
+		return
+	}
+	session, ok := takePKCESession(nonce)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired state parameter"})
+		return
+	}
+
+	accessToken, err := services.ExchangeDeezerCode(c.Request.Context(), c.Request.URL.Query().Get("code"))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Failed to get token", "details": err.Error()})
+		return
+	}
+
+	deezerID, _, err := services.GetDeezerUser(c.Request.Context(), services.NewDeezerClient(accessToken))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user info", "details": err.Error()})
+		return
+	}
+
+	updateData := map[string]interface{}{
+		"deezer_id":    deezerID,
+		"deezer_token": accessToken,
+		"updated_at":   time.Now(),
+	}
+	if err := db.DB.Model(&db.User{}).Where("id = ?", session.UserID).Updates(updateData).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link Deezer", "details": err.Error()})
+		return
+	}
+
+	// Redirect to settings with success parameter
+	c.Redirect(http.StatusFound, FrontendURL+"/settings?connected=deezer")
+}
+
+// AppleMusicLink returns the developer token the frontend needs to run MusicKit JS authorization.
+// Apple Music has no server-side redirect flow; MusicKit hands the Music-User-Token back to the
+// frontend, which posts it to AppleMusicCallback.
+func AppleMusicLink(c *gin.Context) {
+	if _, err := uuid.Parse(c.GetString("userID")); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	developerToken, err := services.GetAppleMusicDeveloperToken()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Apple Music is not configured", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"developer_token": developerToken,
+		"callback_url":    "/api/callback/applemusic",
+	})
+}
+
+// AppleMusicCallback stores the Music-User-Token obtained by MusicKit JS
+func AppleMusicCallback(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input struct {
+		MusicUserToken string `json:"music_user_token" binding:"required"`
+		Storefront     string `json:"storefront"` // Optional override, e.g. "gb"
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
+	}
+
+	developerToken, err := services.GetAppleMusicDeveloperToken()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Apple Music is not configured", "details": err.Error()})
+		return
+	}
+
+	// Verify the token and pick up the account's storefront
+	client := services.NewAppleMusicClient(developerToken, input.MusicUserToken, "")
+	storefront, err := services.GetAppleMusicStorefront(c.Request.Context(), client)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Failed to verify Apple Music token", "details": err.Error()})
+		return
+	}
+	if input.Storefront != "" {
+		storefront = strings.ToLower(input.Storefront)
+	}
+
+	updateData := map[string]interface{}{
+		"applemusic_token":      input.MusicUserToken,
+		"applemusic_storefront": storefront,
+		"updated_at":            time.Now(),
+	}
+	result := db.DB.Model(&db.User{}).Where("id = ?", userID).Updates(updateData)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link Apple Music", "details": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Apple Music linked",
+		"storefront": storefront,
+	})
+}
+
+// SubsonicLink links a self-hosted Subsonic-compatible server (Navidrome, Airsonic, Gonic, ...).
+// The password is only used to derive the token/salt pair and is not stored.
+func SubsonicLink(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input struct {
+		ServerURL string `json:"server_url" binding:"required"`
+		Username  string `json:"username" binding:"required"`
+		Password  string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
+	}
+
+	serverURL, err := services.NormalizeSubsonicURL(input.ServerURL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server URL", "details": err.Error()})
+		return
+	}
+	token, salt, err := services.NewSubsonicCredentials(input.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to derive credentials", "details": err.Error()})
+		return
+	}
+
+	client := services.NewSubsonicClient(serverURL, input.Username, token, salt)
+	if err := services.PingSubsonic(c.Request.Context(), client); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Failed to connect to Subsonic server", "details": err.Error()})
+		return
+	}
+
+	updateData := map[string]interface{}{
+		"subsonic_url":      serverURL,
+		"subsonic_username": input.Username,
+		"subsonic_token":    token,
+		"subsonic_salt":     salt,
+		"updated_at":        time.Now(),
+	}
+	result := db.DB.Model(&db.User{}).Where("id = ?", userID).Updates(updateData)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link Subsonic server", "details": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Subsonic server linked",
+		"server_url": serverURL,
+	})
+}
+
+// JellyfinLink links a Jellyfin or Emby server, either with an API key plus the username to
+// act as, or with a username and password exchanged for an access token.
+func JellyfinLink(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input struct {
+		ServerURL string `json:"server_url" binding:"required"`
+		Username  string `json:"username" binding:"required"`
+		Password  string `json:"password"`
+		APIKey    string `json:"api_key"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
+	}
+	if input.APIKey == "" && input.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Either api_key or password is required"})
+		return
+	}
+
+	serverURL, err := services.NormalizeJellyfinURL(input.ServerURL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server URL", "details": err.Error()})
+		return
+	}
+
+	var jellyfinUserID, token string
+	if input.APIKey != "" {
+		token = input.APIKey
+		jellyfinUserID, err = services.GetJellyfinUserID(c.Request.Context(), services.NewJellyfinClient(serverURL, "", token), input.Username)
+	} else {
+		jellyfinUserID, token, err = services.AuthenticateJellyfin(c.Request.Context(), serverURL, input.Username, input.Password)
+	}
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Failed to connect to Jellyfin server", "details": err.Error()})
+		return
+	}
+
+	updateData := map[string]interface{}{
+		"jellyfin_url":     serverURL,
+		"jellyfin_user_id": jellyfinUserID,
+		"jellyfin_token":   token,
+		"updated_at":       time.Now(),
+	}
+	result := db.DB.Model(&db.User{}).Where("id = ?", userID).Updates(updateData)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link Jellyfin server", "details": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Jellyfin server linked",
+		"server_url": serverURL,
+	})
+}
+
+// LastFMLink initiates Last.fm web authentication
+func LastFMLink(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	callbackURL := os.Getenv("LASTFM_REDIRECT_URL")
+	if callbackURL == "" {
+		callbackURL = "http://127.0.0.1:8000/callback/lastfm"
+	}
+	// Last.fm has no state parameter, but keeps query parameters on the callback URL
+	nonce, _ := startPKCESession(userID)
+	callbackURL += "?state=" + nonce
+	c.Redirect(http.StatusFound, services.LastFMAuthURL(callbackURL))
+}
+
+// LastFMCallback exchanges the Last.fm token for a session
+func LastFMCallback(c *gin.Context) {
+	session, ok := takePKCESession(c.Request.URL.Query().Get("state"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired state parameter"})
+		return
+	}
+	token := c.Request.URL.Query().Get("token")
+	if token == "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Last.fm authorization failed"})
+		return
+	}
+
+	username, sessionKey, err := services.GetLastFMSession(c.Request.Context(), token)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Failed to get session", "details": err.Error()})
+		return
+	}
+
+	updateData := map[string]interface{}{
+		"lastfm_username":    username,
+		"lastfm_session_key": sessionKey,
+		"updated_at":         time.Now(),
+	}
+	if err := db.DB.Model(&db.User{}).Where("id = ?", session.UserID).Updates(updateData).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link Last.fm", "details": err.Error()})
+		return
+	}
+
+	// Redirect to settings with success parameter
+	c.Redirect(http.StatusFound, FrontendURL+"/settings?connected=lastfm")
+}
+
+// ListenBrainzLink stores a ListenBrainz user token after validating it
+func ListenBrainzLink(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
+	}
+
+	username, err := services.ValidateListenBrainzToken(c.Request.Context(), input.Token)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Failed to verify ListenBrainz token", "details": err.Error()})
+		return
+	}
+
+	updateData := map[string]interface{}{
+		"listenbrainz_username": username,
+		"listenbrainz_token":    input.Token,
+		"updated_at":            time.Now(),
+	}
+	result := db.DB.Model(&db.User{}).Where("id = ?", userID).Updates(updateData)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link ListenBrainz", "details": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "ListenBrainz linked",
+		"username": username,
+	})
+}
+
 // --- HELPER FUNCTIONS ---
 
+// startPKCESession stores a new verifier for the user. The returned nonce is used as the
+// OAuth state, since the callback must find the verifier again.
+func startPKCESession(userID uuid.UUID) (string, string) {
+	nonce := uuid.New().String()
+	verifier := oauth2.GenerateVerifier()
+
+	pkceMu.Lock()
+	defer pkceMu.Unlock()
+	for k, v := range pkcePending {
+		if time.Now().After(v.Expires) {
+			delete(pkcePending, k)
+		}
+	}
+	pkcePending[nonce] = pkceSession{UserID: userID, Verifier: verifier, Expires: time.Now().Add(10 * time.Minute)}
+	return nonce, verifier
+}
+
+// takePKCESession removes and returns the session for a state, if it has not expired
+func takePKCESession(nonce string) (pkceSession, bool) {
+	pkceMu.Lock()
+	session, ok := pkcePending[nonce]
+	delete(pkcePending, nonce)
+	pkceMu.Unlock()
+	if !ok || time.Now().After(session.Expires) {
+		return pkceSession{}, false
+	}
+	return session, true
+}
+
 func saveUser(ctx context.Context, authType, authID, email, username string, token *oauth2.Token) (db.User, error) {
 	var dbUser db.User
 	tokenJSON, _ := json.Marshal(token)
@@ -497,6 +1025,22 @@ func updateUserService(ctx context.Context, userID uuid.UUID, platform, platform
 		if token.RefreshToken != "" {
 			updateData["youtube_refresh_token"] = token.RefreshToken
 		}
+	} else if platform == "tidal" {
+		updateData["tidal_id"] = platformID
+		updateData["tidal_token"] = string(tokenJSON)
+		updateData["tidal_token_expiry"] = token.Expiry
+		// Check for empty refresh token
+		if token.RefreshToken != "" {
+			updateData["tidal_refresh_token"] = token.RefreshToken
+		}
+	} else if platform == "soundcloud" {
+		updateData["soundcloud_id"] = platformID
+		updateData["soundcloud_token"] = string(tokenJSON)
+		updateData["soundcloud_token_expiry"] = token.Expiry
+		// Check for empty refresh token
+		if token.RefreshToken != "" {
+			updateData["soundcloud_refresh_token"] = token.RefreshToken
+		}
 	}
 
 	if err := db.DB.Model(&dbUser).Updates(updateData).Error; err != nil {
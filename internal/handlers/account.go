@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"EchoBridge/db"
+	"EchoBridge/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ExportAccount downloads a zip archive of all the user's data, for backups and data portability
+func ExportAccount(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var user db.User
+	if err := db.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	archive, err := services.BuildAccountArchive(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export account", "details": err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := services.WriteAccountArchive(&buf, archive); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write archive", "details": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("echobridge-%s.zip", time.Now().UTC().Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// ImportAccount restores an archive from ExportAccount into the current account.
+// The account must not have any playlists yet, so a restore never mixes with existing data.
+func ImportAccount(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing archive file", "details": err.Error()})
+		return
+	}
+	if fileHeader.Size > services.MaxAccountArchiveSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Archive is too large"})
+		return
+	}
+
+	var playlistCount int64
+	if err := db.DB.Model(&db.Playlist{}).Where("owner_id = ?", userID).Count(&playlistCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check account", "details": err.Error()})
+		return
+	}
+	if playlistCount > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Archives can only be restored into an account without playlists"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read archive", "details": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxAccountArchiveSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read archive", "details": err.Error()})
+		return
+	}
+
+	archive, err := services.ReadAccountArchive(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid archive", "details": err.Error()})
+		return
+	}
+
+	summary, err := services.RestoreAccountArchive(userID, archive, shareBaseURL())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore archive", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Account restored. Re-link your music services to continue syncing.",
+		"restored": summary,
+	})
+}
@@ -18,10 +18,25 @@ func RegisterAuthRoutes(r *gin.Engine) {
 	r.GET("/callback/google", auth.GoogleCallback)
 	r.GET("/callback/spotify", auth.SpotifyCallback)
 	r.GET("/callback/youtube", auth.YouTubeCallback)
+	r.GET("/callback/deezer", auth.DeezerCallback)
+	r.GET("/callback/tidal", auth.TidalCallback)
+	r.GET("/callback/soundcloud", auth.SoundCloudCallback)
+	r.GET("/callback/lastfm", auth.LastFMCallback)
 	protected := r.Group("/api").Use(auth.AuthMiddleware())
 	protected.GET("/link/spotify", auth.SpotifyLink)
 	protected.GET("/link/youtube", auth.YouTubeLink)
+	protected.GET("/link/deezer", auth.DeezerLink)
+	protected.GET("/link/tidal", auth.TidalLink)
+	protected.GET("/link/soundcloud", auth.SoundCloudLink)
+	protected.GET("/link/lastfm", auth.LastFMLink)
+	protected.POST("/link/listenbrainz", auth.ListenBrainzLink)
+	protected.GET("/link/applemusic", auth.AppleMusicLink)
+	protected.POST("/callback/applemusic", auth.AppleMusicCallback)
+	protected.POST("/link/subsonic", auth.SubsonicLink)
+	protected.POST("/link/jellyfin", auth.JellyfinLink)
 	protected.GET("/connection/status", GetConnectionStatus)
+	protected.GET("/me/export", ExportAccount)
+	protected.POST("/me/import", ImportAccount)
 }
 
 // GetConnectionStatus checks which platforms are connected
@@ -39,8 +54,16 @@ func GetConnectionStatus(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"spotify_connected": user.SpotifyToken != "",
-		"youtube_connected": user.YouTubeToken != "",
-		"username":          user.Username,
+		"spotify_connected":      user.SpotifyToken != "",
+		"youtube_connected":      user.YouTubeToken != "",
+		"applemusic_connected":   user.AppleMusicToken != "",
+		"deezer_connected":       user.DeezerToken != "",
+		"tidal_connected":        user.TidalToken != "",
+		"subsonic_connected":     user.SubsonicToken != "",
+		"jellyfin_connected":     user.JellyfinToken != "",
+		"soundcloud_connected":   user.SoundCloudToken != "",
+		"lastfm_connected":       user.LastFMSessionKey != "",
+		"listenbrainz_connected": user.ListenBrainzToken != "",
+		"username":               user.Username,
 	})
 }
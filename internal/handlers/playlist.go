@@ -12,6 +12,9 @@ var WorkerPool *worker.WorkerPool
 // RegisterPlaylistRoutes sets up playlist-related routes
 func RegisterPlaylistRoutes(r *gin.Engine) {
 	r.GET("/playlists/public", GetPublicPlaylists)
+	r.GET("/playlists/trending", GetTrendingPlaylists)
+	r.GET("/playlists/popular", GetPopularPlaylists)
+	r.GET("/playlists/:id/related", GetRelatedPlaylists)
 	r.GET("/playlists/:id", GetPlaylist)
 	r.GET("/debug/categorize/:id", DebugCategorizePlaylist) // Debug route
 	r.GET("/debug/categorize-all", DebugCategorizeAll)      // Batch categorize
@@ -19,14 +22,71 @@ func RegisterPlaylistRoutes(r *gin.Engine) {
 	protected.GET("/spotify/playlists", SpotifyPlaylists)
 	protected.GET("/spotify/playlist/:id/tracks", SpotifyPlaylistTracks)
 	protected.GET("/youtube/playlists", YouTubePlaylists)
+	protected.GET("/applemusic/playlists", AppleMusicPlaylists)
+	protected.GET("/applemusic/playlist/:id/tracks", AppleMusicPlaylistTracks)
+	protected.GET("/deezer/playlists", DeezerPlaylists)
+	protected.GET("/deezer/playlist/:id/tracks", DeezerPlaylistTracks)
+	protected.GET("/tidal/playlists", TidalPlaylists)
+	protected.GET("/tidal/playlist/:id/tracks", TidalPlaylistTracks)
+	protected.GET("/subsonic/playlists", SubsonicPlaylists)
+	protected.GET("/subsonic/playlist/:id/tracks", SubsonicPlaylistTracks)
+	protected.GET("/jellyfin/playlists", JellyfinPlaylists)
+	protected.GET("/jellyfin/playlist/:id/tracks", JellyfinPlaylistTracks)
+	protected.GET("/soundcloud/playlists", SoundCloudPlaylists)
+	protected.GET("/soundcloud/playlist/:id/tracks", SoundCloudPlaylistTracks)
+	protected.GET("/lastfm/playlists", LastFMPlaylists)
+	protected.GET("/lastfm/playlist/:id/tracks", LastFMPlaylistTracks)
+	protected.GET("/listenbrainz/playlists", ListenBrainzPlaylists)
+	protected.GET("/listenbrainz/playlist/:id/tracks", ListenBrainzPlaylistTracks)
 	protected.GET("/my/playlists", GetUserPlaylists)
 	protected.POST("/playlists", PostPlaylist)
 	protected.POST("/playlists/batch-import", BatchImportPlaylists)
+	protected.POST("/playlists/upload", UploadPlaylist)
+	protected.POST("/playlists/upload/csv", UploadPlaylistCSV)
+	protected.POST("/playlists/new", CreatePlaylist)
+	protected.PATCH("/playlists/:id", UpdatePlaylist)
+	protected.DELETE("/playlists/:id", DeletePlaylist)
+	protected.POST("/playlists/:id/duplicate", DuplicatePlaylist)
+	protected.POST("/playlists/:id/merge", MergePlaylists)
+	protected.GET("/tracks/search", SearchTracks)
+	protected.GET("/playlists/:id/export", ExportPlaylist)
 	protected.PATCH("/playlists/:id/public", UpdateSinglePlaylistPublic)
+	protected.GET("/playlists/:id/share-tokens", ListPlaylistShareTokens)
+	protected.POST("/playlists/:id/share-tokens", CreatePlaylistShareToken)
+	protected.DELETE("/playlists/:id/share-tokens/:tokenID", RevokePlaylistShareToken)
+	protected.GET("/playlists/:id/members", GetPlaylistMembers)
+	protected.POST("/playlists/:id/members", InvitePlaylistMember)
+	protected.PATCH("/playlists/:id/members/:memberID", UpdatePlaylistMember)
+	protected.DELETE("/playlists/:id/members/:memberID", RemovePlaylistMember)
+	protected.GET("/playlist-invites", GetPlaylistInvites)
+	protected.POST("/playlist-invites/:inviteID/accept", AcceptPlaylistInvite)
+	protected.POST("/playlist-invites/:inviteID/decline", DeclinePlaylistInvite)
+	protected.POST("/playlists/:id/tracks", AddPlaylistTrack)
+	protected.PUT("/playlists/:id/tracks/order", ReorderPlaylistTracks)
+	protected.DELETE("/playlists/:id/tracks/:trackID", RemovePlaylistTrack)
+	protected.GET("/playlists/:id/activity", GetPlaylistActivity)
+	protected.GET("/playlists/:id/diff/live", DiffPlaylistLive)
+	protected.GET("/playlists/:id/diff/:other", DiffPlaylists)
+	protected.GET("/playlists/:id/versions", GetPlaylistVersions)
+	protected.GET("/playlists/:id/versions/:version", GetPlaylistVersion)
+	protected.GET("/playlists/:id/versions/:version/diff/:other", DiffPlaylistVersions)
+	protected.POST("/playlists/:id/versions/:version/restore", RestorePlaylistVersion)
+	protected.GET("/playlists/:id/duplicates", GetPlaylistDuplicates)
+	protected.POST("/playlists/:id/duplicates/remove", RemovePlaylistDuplicates)
+	protected.POST("/playlists/:id/sync-members", SyncPlaylistMembers)
+	protected.PUT("/playlists/:id/members/me/sync", SetPlaylistMemberSync)
 	protected.POST("/playlists/:id/import", ImportPublicPlaylist) // New unified import
 	protected.POST("/import/playlist/:id/to/spotify", ImportToSpotify)
 	protected.POST("/import/playlist/:id/to/youtube", ImportToYouTube)
+	protected.POST("/import/playlist/:id/to/applemusic", ImportToAppleMusic)
+	protected.POST("/import/playlist/:id/to/deezer", ImportToDeezer)
+	protected.POST("/import/playlist/:id/to/tidal", ImportToTidal)
+	protected.POST("/import/playlist/:id/to/subsonic", ImportToSubsonic)
+	protected.POST("/import/playlist/:id/to/jellyfin", ImportToJellyfin)
+	protected.POST("/import/playlist/:id/to/soundcloud", ImportToSoundCloud)
+	protected.POST("/import/playlist/:id/to/listenbrainz", ImportToListenBrainz)
 	protected.GET("/export/spotify/:spotifyPlaylistID/to/youtube", ExportSpotifyToYouTube)
 	protected.POST("/sync/playlist/:id", SyncPlaylist)
 	protected.GET("/sync/status/:jobID", GetSyncStatus)
+	protected.POST("/sync/status/:jobID/resume", ResumeSyncJob)
 }
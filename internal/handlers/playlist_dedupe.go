@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"EchoBridge/db"
+	"EchoBridge/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetPlaylistDuplicates reports repeated songs in a playlist, grouped by the track that is kept
+func GetPlaylistDuplicates(c *gin.Context) {
+	playlist, _, ok := playlistWithRole(c, services.PlaylistRoleViewer)
+	if !ok {
+		return
+	}
+
+	tracks, err := services.PlaylistTracks(playlist.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tracks", "details": err.Error()})
+		return
+	}
+
+	summary := map[string]int{}
+	for _, level := range services.DuplicateLevels {
+		summary[level] = 0
+	}
+	groups := []gin.H{}
+	for _, g := range services.FindDuplicates(tracks) {
+		duplicates := []gin.H{}
+		for _, d := range g.Duplicates {
+			summary[d.Match]++
+			duplicates = append(duplicates, gin.H{
+				"track":      trackJSON(d.Track),
+				"match":      d.Match,
+				"similarity": d.Similarity,
+			})
+		}
+		groups = append(groups, gin.H{"keep": trackJSON(g.Keep), "duplicates": duplicates})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"playlist_id": playlist.ID,
+		"tracks":      len(tracks),
+		"summary":     summary,
+		"groups":      groups,
+	})
+}
+
+// RemovePlaylistDuplicates removes duplicates found by GetPlaylistDuplicates. Levels default to
+// exact and canonical matches; fuzzy matches are only removed when asked for. With
+// remove_on_source the owner's copy on the source platform is cleaned up too.
+func RemovePlaylistDuplicates(c *gin.Context) {
+	var input struct {
+		Levels         []string    `json:"levels"`
+		TrackIDs       []uuid.UUID `json:"track_ids"`
+		RemoveOnSource bool        `json:"remove_on_source"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+			return
+		}
+	}
+	if len(input.Levels) == 0 {
+		input.Levels = []string{services.DuplicateExact, services.DuplicateCanonical}
+	}
+	for _, level := range input.Levels {
+		if !slices.Contains(services.DuplicateLevels, level) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown duplicate level: " + level, "levels": services.DuplicateLevels})
+			return
+		}
+	}
+
+	// Changing the platform playlist needs the owner's account
+	minRole := services.PlaylistRoleEditor
+	if input.RemoveOnSource {
+		minRole = services.PlaylistRoleOwner
+	}
+	playlist, userID, ok := playlistWithRole(c, minRole)
+	if !ok {
+		return
+	}
+
+	removed, remaining, err := services.RemoveDuplicates(playlist.ID, userID, input.Levels, input.TrackIDs)
+	if err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": "Failed to remove duplicates", "details": err.Error()})
+		return
+	}
+
+	removedIDs := []uuid.UUID{}
+	for _, t := range removed {
+		removedIDs = append(removedIDs, t.ID)
+	}
+	response := gin.H{
+		"message":     "Duplicates removed",
+		"removed":     len(removed),
+		"removed_ids": removedIDs,
+		"remaining":   len(remaining),
+	}
+
+	if input.RemoveOnSource && len(removed) > 0 {
+		var user db.User
+		if err := db.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		count, err := services.RemoveDuplicatesOnSource(c.Request.Context(), user, playlist, removed, remaining)
+		source := gin.H{"platform": playlist.Platform, "removed": count}
+		if err != nil {
+			source["error"] = err.Error()
+			source["supported"] = !errors.Is(err, services.ErrSourceCleanupUnsupported)
+		}
+		response["source"] = source
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+
+	"EchoBridge/db"
+	"EchoBridge/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// DeezerPlaylists retrieves user Deezer playlists
+func DeezerPlaylists(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var dbUser db.User
+	if err := db.DB.Where("id = ?", userID).First(&dbUser).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	client, err := services.GetDeezerClient(c.Request.Context(), dbUser)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Deezer not linked"})
+		return
+	}
+
+	playlists, err := services.GetDeezerPlaylists(c.Request.Context(), client)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve Deezer playlists", "details": err.Error()})
+		return
+	}
+
+	result := []gin.H{}
+	for _, p := range playlists {
+		result = append(result, gin.H{
+			"platform":    "deezer",
+			"id":          p.SourceID,
+			"title":       p.Title,
+			"description": p.Description,
+			"cover_image": p.CoverImage,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Deezer playlists retrieved", "playlists": result})
+}
+
+// DeezerPlaylistTracks retrieves tracks from a Deezer playlist (Direct API call, not DB)
+func DeezerPlaylistTracks(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var dbUser db.User
+	if err := db.DB.Where("id = ?", userID).First(&dbUser).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	client, err := services.GetDeezerClient(c.Request.Context(), dbUser)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Deezer not linked"})
+		return
+	}
+
+	tracks, err := services.GetDeezerPlaylistTracks(c.Request.Context(), client, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve Deezer tracks", "details": err.Error()})
+		return
+	}
+
+	result := []gin.H{}
+	for _, t := range tracks {
+		result = append(result, gin.H{
+			"name":    t.Title,
+			"artists": t.Artist,
+			"album":   t.Album,
+			"id":      t.DeezerID,
+			"isrc":    t.ISRC,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"platform":    "deezer",
+		"playlist_id": c.Param("id"),
+		"tracks":      result,
+	})
+}
+
+// ImportToDeezer imports a playlist to Deezer
+func ImportToDeezer(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	playlistID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid playlist ID"})
+		return
+	}
+
+	var dbUser db.User
+	if err := db.DB.Where("id = ?", userID).First(&dbUser).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var playlist db.Playlist
+	if err := db.DB.Where("id = ?", playlistID).First(&playlist).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist not found"})
+		return
+	}
+
+	// Check if user has access (Member or Public)
+	if !services.CanViewPlaylist(playlist, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this playlist"})
+		return
+	}
+
+	var tracks []db.Track
+	if err := db.DB.Where("playlist_id = ?", playlistID).Order(services.TrackOrder).Find(&tracks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tracks"})
+		return
+	}
+
+	client, err := services.GetDeezerClient(c.Request.Context(), dbUser)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Deezer not linked"})
+		return
+	}
+
+	deezerID, err := services.ImportToDeezer(c.Request.Context(), client, playlist, tracks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import to Deezer", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Playlist imported to Deezer",
+		"deezer_playlist_id": deezerID,
+	})
+}
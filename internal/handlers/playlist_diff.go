@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+
+	"EchoBridge/db"
+	"EchoBridge/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// viewablePlaylist loads the playlist with the given ID when the current user can view it
+// (Member or Public), writing the error response otherwise
+func viewablePlaylist(c *gin.Context, userID uuid.UUID, id string) (db.Playlist, bool) {
+	var playlist db.Playlist
+	playlistID, err := uuid.Parse(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid playlist ID"})
+		return playlist, false
+	}
+	if err := db.DB.Where("id = ?", playlistID).First(&playlist).Error; err != nil || !services.CanViewPlaylist(playlist, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist not found"})
+		return playlist, false
+	}
+	return playlist, true
+}
+
+func playlistDiffJSON(a, b gin.H, diff services.PlaylistDiff) gin.H {
+	onlyInA := []gin.H{}
+	for _, t := range diff.OnlyInA {
+		onlyInA = append(onlyInA, gin.H{"position": t.Position, "track": trackJSON(t.Track)})
+	}
+	onlyInB := []gin.H{}
+	for _, t := range diff.OnlyInB {
+		onlyInB = append(onlyInB, gin.H{"position": t.Position, "track": trackJSON(t.Track)})
+	}
+	inBoth := []gin.H{}
+	moved := []gin.H{}
+	for _, m := range diff.InBoth {
+		entry := gin.H{
+			"position_a": m.PositionA,
+			"position_b": m.PositionB,
+			"match":      m.Match,
+			"moved":      m.Moved,
+			"a":          trackJSON(m.A),
+			"b":          trackJSON(m.B),
+		}
+		inBoth = append(inBoth, entry)
+		if m.Moved {
+			moved = append(moved, entry)
+		}
+	}
+
+	return gin.H{
+		"a": a,
+		"b": b,
+		"summary": gin.H{
+			"only_in_a": len(diff.OnlyInA),
+			"only_in_b": len(diff.OnlyInB),
+			"in_both":   len(diff.InBoth),
+			"moved":     diff.Moved,
+			"identical": diff.Identical(),
+		},
+		"only_in_a": onlyInA,
+		"only_in_b": onlyInB,
+		"in_both":   inBoth,
+		"moved":     moved,
+	}
+}
+
+func diffSideJSON(p db.Playlist, tracks int) gin.H {
+	return gin.H{
+		"id":        p.ID,
+		"title":     p.Title,
+		"platform":  p.Platform,
+		"source_id": p.SourceID,
+		"tracks":    tracks,
+	}
+}
+
+// DiffPlaylists compares two playlists the user can view. Songs are matched by canonical
+// identity, so the same song imported from different platforms counts as shared.
+func DiffPlaylists(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	a, ok := viewablePlaylist(c, userID, c.Param("id"))
+	if !ok {
+		return
+	}
+	b, ok := viewablePlaylist(c, userID, c.Param("other"))
+	if !ok {
+		return
+	}
+
+	tracksA, err := services.PlaylistTracks(a.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tracks", "details": err.Error()})
+		return
+	}
+	tracksB, err := services.PlaylistTracks(b.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tracks", "details": err.Error()})
+		return
+	}
+
+	diff := services.DiffTracks(tracksA, tracksB)
+	c.JSON(http.StatusOK, playlistDiffJSON(diffSideJSON(a, len(tracksA)), diffSideJSON(b, len(tracksB)), diff))
+}
+
+// DiffPlaylistLive compares a playlist with the current state of a playlist on a platform.
+// By default that is the playlist it was imported from, showing changes made since the import;
+// pass url, or platform and source_id, to compare with another platform playlist.
+func DiffPlaylistLive(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	playlist, ok := viewablePlaylist(c, user.ID, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	platform, sourceID := playlist.Platform, playlist.SourceID
+	if c.Query("url") != "" || c.Query("platform") != "" || c.Query("source_id") != "" {
+		var urlErr *services.PlaylistURLError
+		platform, sourceID, urlErr = resolvePlaylistSource(c.Query("platform"), c.Query("source_id"), c.Query("url"))
+		if urlErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": urlErr.Message, "code": urlErr.Code})
+			return
+		}
+	} else if platform == services.NativePlatform {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Playlist was created in EchoBridge; provide url, or platform and source_id, to compare with"})
+		return
+	}
+
+	tracks, err := services.PlaylistTracks(playlist.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tracks", "details": err.Error()})
+		return
+	}
+	live, err := services.FetchPlatformPlaylistTracks(c.Request.Context(), user, platform, sourceID)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to fetch platform playlist", "details": err.Error()})
+		return
+	}
+
+	diff := services.DiffTracks(tracks, live)
+	c.JSON(http.StatusOK, playlistDiffJSON(
+		diffSideJSON(playlist, len(tracks)),
+		gin.H{"platform": platform, "source_id": sourceID, "tracks": len(live)},
+		diff,
+	))
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"EchoBridge/db"
+	"EchoBridge/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// discoveryLimit reads the limit query parameter, defaulting to 20 and capped at 50
+func discoveryLimit(c *gin.Context) int {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit <= 0 || limit > 50 {
+		return 20
+	}
+	return limit
+}
+
+func rankedPlaylistsJSON(ranked []services.RankedPlaylist) []gin.H {
+	result := []gin.H{}
+	for _, r := range ranked {
+		entry := publicPlaylistJSON(r.Playlist)
+		entry["score"] = r.Score
+		result = append(result, entry)
+	}
+	return result
+}
+
+// GetTrendingPlaylists ranks public playlists by recent views and imports, newest activity weighing most
+func GetTrendingPlaylists(c *gin.Context) {
+	ranked, err := services.TrendingPlaylists(discoveryLimit(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trending playlists", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Trending playlists retrieved", "playlists": rankedPlaylistsJSON(ranked)})
+}
+
+// GetPopularPlaylists lists the public playlists imported most often in the last week
+func GetPopularPlaylists(c *gin.Context) {
+	ranked, err := services.MostImportedPlaylists(discoveryLimit(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch popular playlists", "details": err.Error()})
+		return
+	}
+	result := []gin.H{}
+	for _, r := range ranked {
+		entry := publicPlaylistJSON(r.Playlist)
+		entry["imports_this_week"] = int(r.Score)
+		result = append(result, entry)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Most imported playlists this week", "playlists": result})
+}
+
+// GetRelatedPlaylists lists public playlists with songs in common with a playlist or in its category
+func GetRelatedPlaylists(c *gin.Context) {
+	playlistID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid playlist ID"})
+		return
+	}
+	var playlist db.Playlist
+	if err := db.DB.Where("id = ?", playlistID).First(&playlist).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist not found"})
+		return
+	}
+	if _, ok := readablePlaylist(c, playlist); !ok {
+		return
+	}
+
+	related, err := services.RelatedPlaylists(playlist, discoveryLimit(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch related playlists", "details": err.Error()})
+		return
+	}
+	result := []gin.H{}
+	for _, r := range related {
+		entry := publicPlaylistJSON(r.Playlist)
+		entry["shared_tracks"] = r.SharedTracks
+		entry["same_category"] = r.SameCategory
+		entry["score"] = r.Score
+		result = append(result, entry)
+	}
+	c.JSON(http.StatusOK, gin.H{"playlist_id": playlist.ID, "playlists": result})
+}
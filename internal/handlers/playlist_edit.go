@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"EchoBridge/db"
+	"EchoBridge/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func playlistJSON(p db.Playlist) gin.H {
+	return gin.H{
+		"id":          p.ID,
+		"title":       p.Title,
+		"description": p.Description,
+		"platform":    p.Platform,
+		"source_id":   p.SourceID,
+		"owner_id":    p.OwnerID,
+		"is_public":   p.IsPublic,
+		"cover_image": p.CoverImage,
+		"category":    p.Category,
+		"created_at":  p.CreatedAt,
+	}
+}
+
+// CreatePlaylist creates an empty playlist in EchoBridge, to be filled with AddPlaylistTrack
+func CreatePlaylist(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input struct {
+		Title       string `json:"title" binding:"required"`
+		Description string `json:"description"`
+		CoverImage  string `json:"cover_image"`
+		IsPublic    bool   `json:"is_public"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
+	}
+
+	input.Title = strings.TrimSpace(input.Title)
+	if input.Title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Title cannot be empty"})
+		return
+	}
+
+	playlist, err := services.CreateNativePlaylist(userID, input.Title, input.Description, input.CoverImage, input.IsPublic)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create playlist", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Playlist created", "playlist": playlistJSON(playlist)})
+}
+
+// UpdatePlaylist edits the title, description or cover image of a playlist. Omitted fields are kept.
+func UpdatePlaylist(c *gin.Context) {
+	playlist, userID, ok := playlistWithRole(c, services.PlaylistRoleEditor)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Title       *string `json:"title"`
+		Description *string `json:"description"`
+		CoverImage  *string `json:"cover_image"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
+	}
+	if input.Title != nil && strings.TrimSpace(*input.Title) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Title cannot be empty"})
+		return
+	}
+
+	playlist, err := services.UpdatePlaylistDetails(playlist, userID, services.PlaylistDetails{
+		Title:       input.Title,
+		Description: input.Description,
+		CoverImage:  input.CoverImage,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update playlist", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Playlist updated", "playlist": playlistJSON(playlist)})
+}
+
+// DeletePlaylist deletes a playlist from EchoBridge. Copies already synced to platforms are kept.
+func DeletePlaylist(c *gin.Context) {
+	playlist, _, ok := playlistWithRole(c, services.PlaylistRoleOwner)
+	if !ok {
+		return
+	}
+
+	if err := services.DeletePlaylist(playlist.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete playlist", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Playlist deleted"})
+}
+
+// DuplicatePlaylist copies a playlist the user can view into a new private playlist they own
+func DuplicatePlaylist(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	playlistID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid playlist ID"})
+		return
+	}
+
+	var input struct {
+		Title string `json:"title"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+			return
+		}
+	}
+
+	var source db.Playlist
+	if err := db.DB.Where("id = ?", playlistID).First(&source).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist not found"})
+		return
+	}
+	// Check if user has access (Member or Public)
+	if !services.CanViewPlaylist(source, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this playlist"})
+		return
+	}
+
+	playlist, err := services.DuplicatePlaylist(source, userID, strings.TrimSpace(input.Title))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to duplicate playlist", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Playlist duplicated", "playlist": playlistJSON(playlist)})
+}
+
+// MergePlaylists adds the tracks of another playlist to this one, skipping songs it already
+// contains. With delete_source, the other playlist is deleted afterwards (owners only).
+func MergePlaylists(c *gin.Context) {
+	target, userID, ok := playlistWithRole(c, services.PlaylistRoleEditor)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		SourcePlaylistID uuid.UUID `json:"source_playlist_id" binding:"required"`
+		DeleteSource     bool      `json:"delete_source"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
+	}
+	if input.SourcePlaylistID == target.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot merge a playlist into itself"})
+		return
+	}
+
+	minRole := services.PlaylistRoleViewer
+	if input.DeleteSource {
+		minRole = services.PlaylistRoleOwner
+	}
+	source, _, err := services.LoadPlaylistWithRole(input.SourcePlaylistID, userID, minRole)
+	if errors.Is(err, services.ErrPlaylistForbidden) && !input.DeleteSource {
+		// Public playlists can be merged by anyone
+		err = nil
+	}
+	if err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": "Source playlist not available", "details": err.Error()})
+		return
+	}
+
+	added, skipped, err := services.MergePlaylists(target, source, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge playlists", "details": err.Error()})
+		return
+	}
+
+	if input.DeleteSource {
+		if err := services.DeletePlaylist(source.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Merged, but failed to delete the source playlist", "details": err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Playlists merged",
+		"added":          added,
+		"skipped":        skipped,
+		"source_deleted": input.DeleteSource,
+	})
+}
+
+// SearchTracks searches for songs to add to a playlist. Platforms default to the user's
+// connected ones that support search; pick a result and add it with its platform and ID.
+func SearchTracks(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing q parameter"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 25 {
+		limit = 10
+	}
+
+	platforms := services.SearchPlatformsFor(user)
+	if p := c.Query("platforms"); p != "" {
+		platforms = strings.Split(p, ",")
+	}
+
+	results, errs := services.SearchTracks(c.Request.Context(), user, query, platforms, limit)
+	response := gin.H{"query": query, "results": results}
+	if len(errs) > 0 {
+		response["errors"] = errs
+	}
+	c.JSON(http.StatusOK, response)
+}
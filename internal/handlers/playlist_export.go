@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"EchoBridge/db"
+	"EchoBridge/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var unsafeFilenameChars = regexp.MustCompile(`[^\w\-. ]+`)
+
+// ExportPlaylist downloads a stored playlist as M3U8, XSPF, JSPF, CSV or full JSON
+func ExportPlaylist(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	playlistID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid playlist ID"})
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	spec, ok := services.ExportFormats[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported format; use m3u8, xspf, jspf, csv or json"})
+		return
+	}
+
+	var playlist db.Playlist
+	if err := db.DB.Where("id = ?", playlistID).First(&playlist).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist not found"})
+		return
+	}
+
+	// Check if user has access (Member or Public)
+	if !services.CanViewPlaylist(playlist, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this playlist"})
+		return
+	}
+
+	var tracks []db.Track
+	if err := db.DB.Where("playlist_id = ?", playlistID).Order(services.TrackOrder).Find(&tracks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tracks"})
+		return
+	}
+
+	data, err := services.ExportPlaylist(format, playlist, tracks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export playlist", "details": err.Error()})
+		return
+	}
+
+	name := strings.TrimSpace(unsafeFilenameChars.ReplaceAllString(playlist.Title, ""))
+	if name == "" {
+		name = "playlist"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, spec.Extension))
+	c.Data(http.StatusOK, spec.ContentType, data)
+}
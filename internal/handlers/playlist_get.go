@@ -2,14 +2,32 @@ package handlers
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 
 	"EchoBridge/db"
+	"EchoBridge/internal/auth"
+	"EchoBridge/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// readablePlaylist checks that the requester may read a playlist, writing the error response
+// otherwise. Private playlists are visible to their members and to holders of a share token.
+// It returns the requester's user ID, or uuid.Nil when not logged in.
+func readablePlaylist(c *gin.Context, playlist db.Playlist) (uuid.UUID, bool) {
+	userID, loggedIn := auth.OptionalUserID(c)
+	if !playlist.IsPublic && (!loggedIn || services.PlaylistRole(playlist, userID) == "") {
+		if _, err := services.CheckPlaylistShareToken(playlist.ID, c.Query("share_token"), services.PlaylistShareView); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This playlist is private", "details": err.Error()})
+			return userID, false
+		}
+	}
+	return userID, true
+}
+
 // GetPlaylist retrieves a single playlist by ID with tracks
 func GetPlaylist(c *gin.Context) {
 	idParam := c.Param("id")
@@ -21,47 +39,41 @@ func GetPlaylist(c *gin.Context) {
 	}
 
 	var playlist db.Playlist
-	if err := db.DB.Preload("Tracks").First(&playlist, "id = ?", playlistID).Error; err != nil {
+	if err := db.DB.Preload("Tracks", func(tx *gorm.DB) *gorm.DB { return tx.Order(services.TrackOrder) }).First(&playlist, "id = ?", playlistID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist not found"})
 		return
 	}
 
-	// Check if public or owned by user (if authenticated)
-	// For now, we'll just check if it's public.
-	// TODO: Add auth check for private playlists if we want to reuse this for private views
-	if !playlist.IsPublic {
-		// Check auth header manually since this is a public route potentially
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "This playlist is private"})
-			return
-		}
-		// In a real app, we'd validate the token here to see if the user owns it.
-		// For simplicity in this step, we will restrict to public only for unauth users.
+	viewerID, ok := readablePlaylist(c, playlist)
+	if !ok {
+		return
+	}
+	if err := services.RecordPlaylistView(playlist, viewerID, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		log.Printf("Failed to record playlist view: %v", err)
+	}
+
+	owners, err := services.PublicProfiles([]uuid.UUID{playlist.OwnerID})
+	if err != nil {
+		log.Printf("Failed to fetch playlist owner: %v", err)
 	}
 
 	tracks := []gin.H{}
 	for _, t := range playlist.Tracks {
-		tracks = append(tracks, gin.H{
-			"title":         t.Title,
-			"artist":        t.Artist,
-			"album":         t.Album,
-			"spotify_id":    t.SpotifyID,
-			"youtube_id":    t.YouTubeID,
-			"applemusic_id": t.AppleMusicID,
-			"preview_url":   t.PreviewURL,
-		})
+		tracks = append(tracks, trackJSON(t))
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"id":          playlist.ID,
-		"title":       playlist.Title,
-		"description": playlist.Description,
-		"platform":    playlist.Platform,
-		"owner_id":    playlist.OwnerID,
-		"tracks":      tracks,
-		"cover_image": playlist.CoverImage,
-		"is_public":   playlist.IsPublic,
-		"category":    playlist.Category,
+		"id":           playlist.ID,
+		"title":        playlist.Title,
+		"description":  playlist.Description,
+		"platform":     playlist.Platform,
+		"owner_id":     playlist.OwnerID,
+		"owner":        ownerJSON(owners, playlist.OwnerID),
+		"tracks":       tracks,
+		"cover_image":  playlist.CoverImage,
+		"is_public":    playlist.IsPublic,
+		"category":     playlist.Category,
+		"view_count":   playlist.ViewCount,
+		"import_count": playlist.ImportCount,
 	})
 }
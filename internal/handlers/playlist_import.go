@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -21,14 +23,21 @@ import (
 // PostPlaylist creates a new playlist AND imports tracks
 func PostPlaylist(c *gin.Context) {
 	var input struct {
-		Platform string `json:"platform" binding:"required"`
-		SourceID string `json:"source_id" binding:"required"`
+		Platform string `json:"platform"`
+		SourceID string `json:"source_id"`
+		URL      string `json:"url"` // playlist link or URI, instead of platform + source_id
 		IsPublic bool   `json:"is_public"`
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
 		return
 	}
+	platform, sourceID, urlErr := resolvePlaylistSource(input.Platform, input.SourceID, input.URL)
+	if urlErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": urlErr.Message, "code": urlErr.Code})
+		return
+	}
+	input.Platform, input.SourceID = platform, sourceID
 
 	userID, err := uuid.Parse(c.GetString("userID"))
 	if err != nil {
@@ -47,9 +56,10 @@ func PostPlaylist(c *gin.Context) {
 
 	switch input.Platform {
 	case "spotify":
-		client, err := services.GetSpotifyClient(c.Request.Context(), dbUser)
+		// Public playlists can be imported without linking Spotify
+		client, err := services.GetSpotifyReadClient(c.Request.Context(), dbUser)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Spotify not linked"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Spotify not linked", "details": err.Error()})
 			return
 		}
 
@@ -95,9 +105,10 @@ func PostPlaylist(c *gin.Context) {
 		}
 
 	case "youtube":
-		client, err := services.GetYouTubeClient(c.Request.Context(), dbUser)
+		// Public playlists can be imported without linking YouTube
+		client, err := services.GetYouTubeReadClient(c.Request.Context(), dbUser)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "YouTube not linked"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "YouTube not linked", "details": err.Error()})
 			return
 		}
 		call := client.Playlists.List([]string{"id", "snippet"}).Id(input.SourceID)
@@ -139,10 +150,251 @@ func PostPlaylist(c *gin.Context) {
 				}
 			}
 		}
+	case "applemusic":
+		client, err := services.GetAppleMusicClient(c.Request.Context(), dbUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Apple Music not linked"})
+			return
+		}
+		amPlaylist, err := services.GetAppleMusicPlaylist(c.Request.Context(), client, input.SourceID)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to fetch Apple Music playlist", "details": err.Error()})
+			return
+		}
+		playlist = amPlaylist
+		playlist.OwnerID = userID
+		playlist.IsPublic = input.IsPublic
+		if err := db.DB.Create(&playlist).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create playlist", "details": err.Error()})
+			return
+		}
+
+		tracks, err := services.GetAppleMusicPlaylistTracks(c.Request.Context(), client, input.SourceID)
+		if err != nil {
+			fmt.Printf("Error fetching Apple Music tracks: %v\n", err)
+		} else {
+			for _, t := range tracks {
+				t.PlaylistID = playlist.ID
+				if err := db.DB.Create(&t).Error; err == nil {
+					importedTracksCount++
+				}
+			}
+		}
+	case "deezer":
+		client, err := services.GetDeezerClient(c.Request.Context(), dbUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Deezer not linked"})
+			return
+		}
+		dzPlaylist, err := services.GetDeezerPlaylist(c.Request.Context(), client, input.SourceID)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to fetch Deezer playlist", "details": err.Error()})
+			return
+		}
+		playlist = dzPlaylist
+		playlist.OwnerID = userID
+		playlist.IsPublic = input.IsPublic
+		if err := db.DB.Create(&playlist).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create playlist", "details": err.Error()})
+			return
+		}
+
+		tracks, err := services.GetDeezerPlaylistTracks(c.Request.Context(), client, input.SourceID)
+		if err != nil {
+			fmt.Printf("Error fetching Deezer tracks: %v\n", err)
+		} else {
+			for _, t := range tracks {
+				t.PlaylistID = playlist.ID
+				if err := db.DB.Create(&t).Error; err == nil {
+					importedTracksCount++
+				}
+			}
+		}
+	case "tidal":
+		client, err := services.GetTidalClient(c.Request.Context(), dbUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "TIDAL not linked"})
+			return
+		}
+		tdPlaylist, err := services.GetTidalPlaylist(c.Request.Context(), client, input.SourceID)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to fetch TIDAL playlist", "details": err.Error()})
+			return
+		}
+		playlist = tdPlaylist
+		playlist.OwnerID = userID
+		playlist.IsPublic = input.IsPublic
+		if err := db.DB.Create(&playlist).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create playlist", "details": err.Error()})
+			return
+		}
+
+		tracks, err := services.GetTidalPlaylistTracks(c.Request.Context(), client, input.SourceID)
+		if err != nil {
+			fmt.Printf("Error fetching TIDAL tracks: %v\n", err)
+		} else {
+			for _, t := range tracks {
+				t.PlaylistID = playlist.ID
+				if err := db.DB.Create(&t).Error; err == nil {
+					importedTracksCount++
+				}
+			}
+		}
+	case "subsonic":
+		client, err := services.GetSubsonicClient(c.Request.Context(), dbUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Subsonic server not linked"})
+			return
+		}
+		ssPlaylist, err := services.GetSubsonicPlaylist(c.Request.Context(), client, input.SourceID)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to fetch Subsonic playlist", "details": err.Error()})
+			return
+		}
+		playlist = ssPlaylist
+		playlist.OwnerID = userID
+		playlist.IsPublic = input.IsPublic
+		if err := db.DB.Create(&playlist).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create playlist", "details": err.Error()})
+			return
+		}
+
+		tracks, err := services.GetSubsonicPlaylistTracks(c.Request.Context(), client, input.SourceID)
+		if err != nil {
+			fmt.Printf("Error fetching Subsonic tracks: %v\n", err)
+		} else {
+			for _, t := range tracks {
+				t.PlaylistID = playlist.ID
+				if err := db.DB.Create(&t).Error; err == nil {
+					importedTracksCount++
+				}
+			}
+		}
+	case "jellyfin":
+		client, err := services.GetJellyfinClient(c.Request.Context(), dbUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Jellyfin server not linked"})
+			return
+		}
+		jfPlaylist, err := services.GetJellyfinPlaylist(c.Request.Context(), client, input.SourceID)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to fetch Jellyfin playlist", "details": err.Error()})
+			return
+		}
+		playlist = jfPlaylist
+		playlist.OwnerID = userID
+		playlist.IsPublic = input.IsPublic
+		if err := db.DB.Create(&playlist).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create playlist", "details": err.Error()})
+			return
+		}
+
+		tracks, err := services.GetJellyfinPlaylistTracks(c.Request.Context(), client, input.SourceID)
+		if err != nil {
+			fmt.Printf("Error fetching Jellyfin tracks: %v\n", err)
+		} else {
+			for _, t := range tracks {
+				t.PlaylistID = playlist.ID
+				if err := db.DB.Create(&t).Error; err == nil {
+					importedTracksCount++
+				}
+			}
+		}
+	case "soundcloud":
+		client, err := services.GetSoundCloudClient(c.Request.Context(), dbUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "SoundCloud not linked"})
+			return
+		}
+		scPlaylist, err := services.GetSoundCloudPlaylist(c.Request.Context(), client, input.SourceID)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to fetch SoundCloud playlist", "details": err.Error()})
+			return
+		}
+		playlist = scPlaylist
+		playlist.OwnerID = userID
+		playlist.IsPublic = input.IsPublic
+		if err := db.DB.Create(&playlist).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create playlist", "details": err.Error()})
+			return
+		}
+
+		tracks, err := services.GetSoundCloudPlaylistTracks(c.Request.Context(), client, input.SourceID)
+		if err != nil {
+			fmt.Printf("Error fetching SoundCloud tracks: %v\n", err)
+		} else {
+			for _, t := range tracks {
+				t.PlaylistID = playlist.ID
+				if err := db.DB.Create(&t).Error; err == nil {
+					importedTracksCount++
+				}
+			}
+		}
+	case "lastfm":
+		client, err := services.GetLastFMClient(c.Request.Context(), dbUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Last.fm not linked"})
+			return
+		}
+		fmPlaylist, err := services.GetLastFMPlaylist(c.Request.Context(), client, input.SourceID)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to fetch Last.fm playlist", "details": err.Error()})
+			return
+		}
+		playlist = fmPlaylist
+		playlist.OwnerID = userID
+		playlist.IsPublic = input.IsPublic
+		if err := db.DB.Create(&playlist).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create playlist", "details": err.Error()})
+			return
+		}
+
+		tracks, err := services.GetLastFMPlaylistTracks(c.Request.Context(), client, input.SourceID)
+		if err != nil {
+			fmt.Printf("Error fetching Last.fm tracks: %v\n", err)
+		} else {
+			for _, t := range tracks {
+				t.PlaylistID = playlist.ID
+				if err := db.DB.Create(&t).Error; err == nil {
+					importedTracksCount++
+				}
+			}
+		}
+	case "listenbrainz":
+		client, err := services.GetListenBrainzClient(c.Request.Context(), dbUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "ListenBrainz not linked"})
+			return
+		}
+		lbPlaylist, err := services.GetListenBrainzPlaylist(c.Request.Context(), client, input.SourceID)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to fetch ListenBrainz playlist", "details": err.Error()})
+			return
+		}
+		playlist = lbPlaylist
+		playlist.OwnerID = userID
+		playlist.IsPublic = input.IsPublic
+		if err := db.DB.Create(&playlist).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create playlist", "details": err.Error()})
+			return
+		}
+
+		tracks, err := services.GetListenBrainzPlaylistTracks(c.Request.Context(), client, input.SourceID)
+		if err != nil {
+			fmt.Printf("Error fetching ListenBrainz tracks: %v\n", err)
+		} else {
+			for _, t := range tracks {
+				t.PlaylistID = playlist.ID
+				if err := db.DB.Create(&t).Error; err == nil {
+					importedTracksCount++
+				}
+			}
+		}
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported platform"})
 		return
 	}
+	services.RecordImportVersion(playlist.ID, userID)
 
 	// Submit Categorization Job
 	if WorkerPool != nil {
@@ -168,6 +420,7 @@ func BatchImportPlaylists(c *gin.Context) {
 		Playlists []struct {
 			Platform string `json:"platform"`
 			SourceID string `json:"source_id"`
+			URL      string `json:"url"`
 		} `json:"playlists" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -188,23 +441,60 @@ func BatchImportPlaylists(c *gin.Context) {
 
 	jobID := uuid.New()
 	count := 0
-	for _, p := range input.Playlists {
+	rejected := []gin.H{}
+	for i, p := range input.Playlists {
+		platform, sourceID, urlErr := resolvePlaylistSource(p.Platform, p.SourceID, p.URL)
+		if urlErr != nil {
+			rejected = append(rejected, gin.H{"index": i, "url": p.URL, "error": urlErr.Message, "code": urlErr.Code})
+			continue
+		}
 		WorkerPool.Submit(worker.Job{
 			Type:       "import_playlist",
 			JobID:      uuid.New(),
 			UserID:     userID,
 			PlaylistID: uuid.Nil,
-			Platforms:  []string{p.Platform, p.SourceID}, // [0]=platform, [1]=sourceID
+			Platforms:  []string{platform, sourceID}, // [0]=platform, [1]=sourceID
 		})
 		count++
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": fmt.Sprintf("Started import for %d playlists", count),
-		"job_id":  jobID,
+		"message":  fmt.Sprintf("Started import for %d playlists", count),
+		"job_id":   jobID,
+		"rejected": rejected,
 	})
 }
 
+// resolvePlaylistSource returns the platform and source ID of an import request given either
+// a link (url, or a link pasted into source_id) or an explicit platform and source_id
+func resolvePlaylistSource(platform, sourceID, link string) (string, string, *services.PlaylistURLError) {
+	if link == "" && services.LooksLikePlaylistURL(sourceID) {
+		link = sourceID
+	}
+	if link == "" {
+		if platform == "" || sourceID == "" {
+			return "", "", &services.PlaylistURLError{Code: services.PlaylistURLMissing, Message: "Provide a playlist URL, or platform and source_id"}
+		}
+		return platform, sourceID, nil
+	}
+
+	resolvedPlatform, resolvedID, err := services.ResolvePlaylistURL(link)
+	if err != nil {
+		var urlErr *services.PlaylistURLError
+		if errors.As(err, &urlErr) {
+			return "", "", urlErr
+		}
+		return "", "", &services.PlaylistURLError{Code: services.PlaylistURLInvalid, Message: err.Error()}
+	}
+	if platform != "" && platform != resolvedPlatform {
+		return "", "", &services.PlaylistURLError{
+			Code:    services.PlaylistURLPlatformMismatch,
+			Message: fmt.Sprintf("URL is a %s playlist but platform is %s", resolvedPlatform, platform),
+		}
+	}
+	return resolvedPlatform, resolvedID, nil
+}
+
 // ImportPublicPlaylist imports a public playlist to the user's chosen platform with validation
 func ImportPublicPlaylist(c *gin.Context) {
 	userID, err := uuid.Parse(c.GetString("userID"))
@@ -220,10 +510,11 @@ func ImportPublicPlaylist(c *gin.Context) {
 	}
 
 	var input struct {
-		Platform string `json:"platform" binding:"required,oneof=spotify youtube"`
+		Platform   string `json:"platform" binding:"required,oneof=spotify youtube applemusic deezer tidal subsonic jellyfin soundcloud listenbrainz"`
+		ShareToken string `json:"share_token"` // grants access to a private playlist shared with "import" permission
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input. Platform must be 'spotify' or 'youtube'", "details": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input. Platform must be 'spotify', 'youtube', 'applemusic', 'deezer', 'tidal', 'subsonic', 'jellyfin', 'soundcloud' or 'listenbrainz'", "details": err.Error()})
 		return
 	}
 
@@ -240,6 +531,20 @@ func ImportPublicPlaylist(c *gin.Context) {
 		platformConnected = true
 	} else if input.Platform == "youtube" && dbUser.YouTubeID != "" {
 		platformConnected = true
+	} else if input.Platform == "applemusic" && dbUser.AppleMusicToken != "" {
+		platformConnected = true
+	} else if input.Platform == "deezer" && dbUser.DeezerToken != "" {
+		platformConnected = true
+	} else if input.Platform == "tidal" && dbUser.TidalToken != "" {
+		platformConnected = true
+	} else if input.Platform == "subsonic" && dbUser.SubsonicToken != "" {
+		platformConnected = true
+	} else if input.Platform == "jellyfin" && dbUser.JellyfinToken != "" {
+		platformConnected = true
+	} else if input.Platform == "soundcloud" && dbUser.SoundCloudToken != "" {
+		platformConnected = true
+	} else if input.Platform == "listenbrainz" && dbUser.ListenBrainzToken != "" {
+		platformConnected = true
 	}
 
 	if !platformConnected {
@@ -258,10 +563,12 @@ func ImportPublicPlaylist(c *gin.Context) {
 		return
 	}
 
-	// Ensure it's public (unless user owns it)
-	if !playlist.IsPublic && playlist.OwnerID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "This playlist is not public"})
-		return
+	// Ensure it's public (unless user is a member or holds an import share token)
+	if !services.CanViewPlaylist(playlist, userID) {
+		if _, err := services.CheckPlaylistShareToken(playlist.ID, input.ShareToken, services.PlaylistShareImport); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This playlist is not public", "details": err.Error()})
+			return
+		}
 	}
 
 	// Check if user already has this playlist on the target platform
@@ -278,7 +585,7 @@ func ImportPublicPlaylist(c *gin.Context) {
 
 	// Get tracks
 	var tracks []db.Track
-	if err := db.DB.Where("playlist_id = ?", playlistID).Find(&tracks).Error; err != nil {
+	if err := db.DB.Where("playlist_id = ?", playlistID).Order(services.TrackOrder).Find(&tracks).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tracks"})
 		return
 	}
@@ -304,6 +611,7 @@ func ImportPublicPlaylist(c *gin.Context) {
 			return
 		}
 
+		recordPlaylistImport(playlist, userID)
 		c.JSON(http.StatusAccepted, gin.H{
 			"message":     fmt.Sprintf("Import to %s started via Temporal workflow", strings.Title(input.Platform)),
 			"workflow_id": we.GetID(),
@@ -346,11 +654,96 @@ func ImportPublicPlaylist(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import to YouTube", "details": err.Error()})
 			return
 		}
+	} else if input.Platform == "applemusic" {
+		amClient, err := services.GetAppleMusicClient(c.Request.Context(), dbUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to connect to Apple Music"})
+			return
+		}
+		targetPlaylistID, err = services.ImportToAppleMusic(c.Request.Context(), amClient, playlist, tracks)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import to Apple Music", "details": err.Error()})
+			return
+		}
+	} else if input.Platform == "deezer" {
+		dzClient, err := services.GetDeezerClient(c.Request.Context(), dbUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to connect to Deezer"})
+			return
+		}
+		targetPlaylistID, err = services.ImportToDeezer(c.Request.Context(), dzClient, playlist, tracks)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import to Deezer", "details": err.Error()})
+			return
+		}
+	} else if input.Platform == "tidal" {
+		tdClient, err := services.GetTidalClient(c.Request.Context(), dbUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to connect to TIDAL"})
+			return
+		}
+		targetPlaylistID, err = services.ImportToTidal(c.Request.Context(), tdClient, playlist, tracks)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import to TIDAL", "details": err.Error()})
+			return
+		}
+	} else if input.Platform == "subsonic" {
+		ssClient, err := services.GetSubsonicClient(c.Request.Context(), dbUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to connect to Subsonic server"})
+			return
+		}
+		targetPlaylistID, err = services.ImportToSubsonic(c.Request.Context(), ssClient, playlist, tracks)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import to Subsonic", "details": err.Error()})
+			return
+		}
+	} else if input.Platform == "jellyfin" {
+		jfClient, err := services.GetJellyfinClient(c.Request.Context(), dbUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to connect to Jellyfin server"})
+			return
+		}
+		targetPlaylistID, err = services.ImportToJellyfin(c.Request.Context(), jfClient, playlist, tracks)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import to Jellyfin", "details": err.Error()})
+			return
+		}
+	} else if input.Platform == "soundcloud" {
+		scClient, err := services.GetSoundCloudClient(c.Request.Context(), dbUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to connect to SoundCloud"})
+			return
+		}
+		targetPlaylistID, err = services.ImportToSoundCloud(c.Request.Context(), scClient, playlist, tracks)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import to SoundCloud", "details": err.Error()})
+			return
+		}
+	} else if input.Platform == "listenbrainz" {
+		lbClient, err := services.GetListenBrainzClient(c.Request.Context(), dbUser)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to connect to ListenBrainz"})
+			return
+		}
+		targetPlaylistID, err = services.ImportToListenBrainz(c.Request.Context(), lbClient, playlist, tracks)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import to ListenBrainz", "details": err.Error()})
+			return
+		}
 	}
 
+	recordPlaylistImport(playlist, userID)
 	c.JSON(http.StatusOK, gin.H{
 		"message":     fmt.Sprintf("Playlist imported to %s successfully", strings.Title(input.Platform)),
 		"platform":    input.Platform,
 		"playlist_id": targetPlaylistID,
 	})
 }
+
+// recordPlaylistImport counts an import started by ImportPublicPlaylist for discovery rankings
+func recordPlaylistImport(playlist db.Playlist, userID uuid.UUID) {
+	if err := services.RecordPlaylistImport(playlist, userID); err != nil {
+		log.Printf("Failed to record playlist import: %v", err)
+	}
+}
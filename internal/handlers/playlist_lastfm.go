@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+
+	"EchoBridge/db"
+	"EchoBridge/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// LastFMPlaylists retrieves user Last.fm history playlists
+func LastFMPlaylists(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var dbUser db.User
+	if err := db.DB.Where("id = ?", userID).First(&dbUser).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	client, err := services.GetLastFMClient(c.Request.Context(), dbUser)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Last.fm not linked"})
+		return
+	}
+
+	playlists, err := services.GetLastFMPlaylists(c.Request.Context(), client)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve Last.fm playlists", "details": err.Error()})
+		return
+	}
+
+	result := []gin.H{}
+	for _, p := range playlists {
+		result = append(result, gin.H{
+			"platform":    "lastfm",
+			"id":          p.SourceID,
+			"title":       p.Title,
+			"description": p.Description,
+			"cover_image": p.CoverImage,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Last.fm playlists retrieved", "playlists": result})
+}
+
+// LastFMPlaylistTracks retrieves tracks from a Last.fm history playlist (Direct API call, not DB)
+func LastFMPlaylistTracks(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var dbUser db.User
+	if err := db.DB.Where("id = ?", userID).First(&dbUser).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	client, err := services.GetLastFMClient(c.Request.Context(), dbUser)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Last.fm not linked"})
+		return
+	}
+
+	tracks, err := services.GetLastFMPlaylistTracks(c.Request.Context(), client, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve Last.fm tracks", "details": err.Error()})
+		return
+	}
+
+	result := []gin.H{}
+	for _, t := range tracks {
+		result = append(result, gin.H{
+			"name":           t.Title,
+			"artists":        t.Artist,
+			"album":          t.Album,
+			"musicbrainz_id": t.MusicBrainzID,
+			"isrc":           t.ISRC,
+			"duration_ms":    t.DurationMs,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"platform":    "lastfm",
+		"playlist_id": c.Param("id"),
+		"tracks":      result,
+	})
+}
@@ -1,38 +1,107 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"EchoBridge/db"
+	"EchoBridge/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-// GetPublicPlaylists retrieves all public playlists
+// playlistQuery reads the browsing parameters: sort, platform and category (comma-separated),
+// q (full-text search), cursor (from next_cursor) and limit
+func playlistQuery(c *gin.Context) services.PlaylistQuery {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	return services.PlaylistQuery{
+		Sort:       c.Query("sort"),
+		Platforms:  splitList(c.Query("platform")),
+		Categories: splitList(c.Query("category")),
+		Search:     c.Query("q"),
+		Cursor:     c.Query("cursor"),
+		Limit:      limit,
+	}
+}
+
+// splitList splits a comma-separated query value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// browseError writes the response for a BrowsePlaylists error
+func browseError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvalidPlaylistSort) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown sort order", "sorts": services.PlaylistSorts})
+		return
+	}
+	if errors.Is(err, services.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch playlists", "details": err.Error()})
+}
+
+// publicPlaylistJSON describes a public playlist in listings
+func publicPlaylistJSON(p db.Playlist) gin.H {
+	return gin.H{
+		"id":           p.ID,
+		"title":        p.Title,
+		"description":  p.Description,
+		"platform":     p.Platform,
+		"owner_id":     p.OwnerID,
+		"cover_image":  p.CoverImage,
+		"category":     p.Category,
+		"created_at":   p.CreatedAt,
+		"view_count":   p.ViewCount,
+		"import_count": p.ImportCount,
+	}
+}
+
+// GetPublicPlaylists retrieves a page of public playlists
 func GetPublicPlaylists(c *gin.Context) {
-	var playlists []db.Playlist
-	if err := db.DB.Where("is_public = ?", true).Find(&playlists).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch public playlists", "details": err.Error()})
+	page, err := services.BrowsePlaylists(db.DB.Where("playlists.is_public = ?", true), playlistQuery(c))
+	if err != nil {
+		browseError(c, err)
+		return
+	}
+
+	ownerIDs := make([]uuid.UUID, len(page.Playlists))
+	for i, p := range page.Playlists {
+		ownerIDs[i] = p.OwnerID
+	}
+	owners, err := services.PublicProfiles(ownerIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch playlist owners", "details": err.Error()})
 		return
 	}
 
 	result := []gin.H{}
-	for _, p := range playlists {
-		result = append(result, gin.H{
-			"id":          p.ID,
-			"title":       p.Title,
-			"description": p.Description,
-			"platform":    p.Platform,
-			"owner_id":    p.OwnerID,
-			"cover_image": p.CoverImage,
-			"category":    p.Category,
-		})
+	for _, p := range page.Playlists {
+		entry := publicPlaylistJSON(p)
+		entry["owner"] = ownerJSON(owners, p.OwnerID)
+		entry["tracks"] = page.TrackCounts[p.ID]
+		entry["popularity"] = page.Popularity[p.ID]
+		result = append(result, entry)
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Public playlists retrieved", "playlists": result})
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Public playlists retrieved",
+		"playlists":   result,
+		"next_cursor": page.NextCursor,
+		"has_more":    page.NextCursor != "",
+	})
 }
 
-// GetUserPlaylists retrieves all playlists for a user
+// GetUserPlaylists retrieves a page of the playlists a user owns or has joined as a member
 func GetUserPlaylists(c *gin.Context) {
 	userID, err := uuid.Parse(c.GetString("userID"))
 	if err != nil {
@@ -40,14 +109,15 @@ func GetUserPlaylists(c *gin.Context) {
 		return
 	}
 
-	var playlists []db.Playlist
-	if err := db.DB.Where("owner_id = ?", userID).Find(&playlists).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch playlists", "details": err.Error()})
+	shared := db.DB.Model(&db.PlaylistMember{}).Select("playlist_id").Where("user_id = ? AND status = ?", userID, "accepted")
+	page, err := services.BrowsePlaylists(db.DB.Where("playlists.owner_id = ? OR playlists.id IN (?)", userID, shared), playlistQuery(c))
+	if err != nil {
+		browseError(c, err)
 		return
 	}
 
 	result := []gin.H{}
-	for _, p := range playlists {
+	for _, p := range page.Playlists {
 		result = append(result, gin.H{
 			"id":          p.ID,
 			"title":       p.Title,
@@ -56,7 +126,17 @@ func GetUserPlaylists(c *gin.Context) {
 			"source_id":   p.SourceID,
 			"is_public":   p.IsPublic,
 			"cover_image": p.CoverImage,
+			"category":    p.Category,
+			"created_at":  p.CreatedAt,
+			"tracks":      page.TrackCounts[p.ID],
+			"popularity":  page.Popularity[p.ID],
+			"role":        services.PlaylistRole(p, userID),
 		})
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Playlists retrieved", "playlists": result})
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Playlists retrieved",
+		"playlists":   result,
+		"next_cursor": page.NextCursor,
+		"has_more":    page.NextCursor != "",
+	})
 }
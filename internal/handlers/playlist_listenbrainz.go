@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"net/http"
+
+	"EchoBridge/db"
+	"EchoBridge/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ListenBrainzPlaylists retrieves user ListenBrainz playlists
+func ListenBrainzPlaylists(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var dbUser db.User
+	if err := db.DB.Where("id = ?", userID).First(&dbUser).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	client, err := services.GetListenBrainzClient(c.Request.Context(), dbUser)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "ListenBrainz not linked"})
+		return
+	}
+
+	playlists, err := services.GetListenBrainzPlaylists(c.Request.Context(), client)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve ListenBrainz playlists", "details": err.Error()})
+		return
+	}
+
+	result := []gin.H{}
+	for _, p := range playlists {
+		result = append(result, gin.H{
+			"platform":    "listenbrainz",
+			"id":          p.SourceID,
+			"title":       p.Title,
+			"description": p.Description,
+			"cover_image": p.CoverImage,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "ListenBrainz playlists retrieved", "playlists": result})
+}
+
+// ListenBrainzPlaylistTracks retrieves tracks from a ListenBrainz playlist (Direct API call, not DB)
+func ListenBrainzPlaylistTracks(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var dbUser db.User
+	if err := db.DB.Where("id = ?", userID).First(&dbUser).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	client, err := services.GetListenBrainzClient(c.Request.Context(), dbUser)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "ListenBrainz not linked"})
+		return
+	}
+
+	tracks, err := services.GetListenBrainzPlaylistTracks(c.Request.Context(), client, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve ListenBrainz tracks", "details": err.Error()})
+		return
+	}
+
+	result := []gin.H{}
+	for _, t := range tracks {
+		result = append(result, gin.H{
+			"name":           t.Title,
+			"artists":        t.Artist,
+			"album":          t.Album,
+			"musicbrainz_id": t.MusicBrainzID,
+			"isrc":           t.ISRC,
+			"duration_ms":    t.DurationMs,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"platform":    "listenbrainz",
+		"playlist_id": c.Param("id"),
+		"tracks":      result,
+	})
+}
+
+// ImportToListenBrainz imports a playlist to ListenBrainz
+func ImportToListenBrainz(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	playlistID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid playlist ID"})
+		return
+	}
+
+	var dbUser db.User
+	if err := db.DB.Where("id = ?", userID).First(&dbUser).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var playlist db.Playlist
+	if err := db.DB.Where("id = ?", playlistID).First(&playlist).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist not found"})
+		return
+	}
+
+	// Check if user has access (Member or Public)
+	if !services.CanViewPlaylist(playlist, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this playlist"})
+		return
+	}
+
+	var tracks []db.Track
+	if err := db.DB.Where("playlist_id = ?", playlistID).Order(services.TrackOrder).Find(&tracks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tracks"})
+		return
+	}
+
+	client, err := services.GetListenBrainzClient(c.Request.Context(), dbUser)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "ListenBrainz not linked"})
+		return
+	}
+
+	listenbrainzID, err := services.ImportToListenBrainz(c.Request.Context(), client, playlist, tracks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import to ListenBrainz", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":                  "Playlist imported to ListenBrainz",
+		"listenbrainz_playlist_id": listenbrainzID,
+	})
+}
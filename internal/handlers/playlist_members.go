@@ -0,0 +1,572 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"EchoBridge/db"
+	"EchoBridge/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// playlistWithRole loads the playlist in the :id param when the current user holds at least
+// minRole on it, writing the error response otherwise
+func playlistWithRole(c *gin.Context, minRole string) (db.Playlist, uuid.UUID, bool) {
+	var playlist db.Playlist
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return playlist, userID, false
+	}
+	playlistID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid playlist ID"})
+		return playlist, userID, false
+	}
+	playlist, _, err = services.LoadPlaylistWithRole(playlistID, userID, minRole)
+	if errors.Is(err, services.ErrPlaylistForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to change this playlist", "required_role": minRole})
+		return playlist, userID, false
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist not found"})
+		return playlist, userID, false
+	}
+	return playlist, userID, true
+}
+
+func trackJSON(t db.Track) gin.H {
+	return gin.H{
+		"id":             t.ID,
+		"position":       t.Position,
+		"title":          t.Title,
+		"artist":         t.Artist,
+		"album":          t.Album,
+		"spotify_id":     t.SpotifyID,
+		"youtube_id":     t.YouTubeID,
+		"applemusic_id":  t.AppleMusicID,
+		"deezer_id":      t.DeezerID,
+		"tidal_id":       t.TidalID,
+		"duration_ms":    t.DurationMs,
+		"isrc":           t.ISRC,
+		"musicbrainz_id": t.MusicBrainzID,
+		"preview_url":    t.PreviewURL,
+	}
+}
+
+func playlistMemberJSON(m db.PlaylistMember) gin.H {
+	return gin.H{
+		"id":          m.ID,
+		"playlist_id": m.PlaylistID,
+		"user_id":     m.UserID,
+		"email":       m.Email,
+		"role":        m.Role,
+		"status":      m.Status,
+		"invited_by":  m.InvitedBy,
+		"sync_opt_in": m.SyncOptIn,
+		"created_at":  m.CreatedAt,
+		"accepted_at": m.AcceptedAt,
+	}
+}
+
+// memberErrorStatus maps membership and edit errors to HTTP statuses
+func memberErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidPlaylistRole),
+		errors.Is(err, services.ErrInvalidInviteEmail),
+		errors.Is(err, services.ErrTrackOrderMismatch),
+		errors.Is(err, services.ErrInvalidTrackLink):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrInviteOwner):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInviteNotFound),
+		errors.Is(err, services.ErrMemberNotFound),
+		errors.Is(err, services.ErrTrackNotFound),
+		errors.Is(err, services.ErrPlaylistNotFound),
+		errors.Is(err, services.ErrVersionNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// --- MEMBERS ---
+
+// GetPlaylistMembers lists the owner, members and pending invitations of a playlist
+func GetPlaylistMembers(c *gin.Context) {
+	playlist, _, ok := playlistWithRole(c, services.PlaylistRoleViewer)
+	if !ok {
+		return
+	}
+
+	var owner db.User
+	if err := db.DB.Where("id = ?", playlist.OwnerID).First(&owner).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch playlist owner", "details": err.Error()})
+		return
+	}
+
+	var members []db.PlaylistMember
+	if err := db.DB.Where("playlist_id = ?", playlist.ID).Order("created_at").Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch members", "details": err.Error()})
+		return
+	}
+
+	result := []gin.H{{
+		"user_id":  owner.ID,
+		"email":    owner.Email,
+		"username": owner.Username,
+		"role":     services.PlaylistRoleOwner,
+		"status":   "accepted",
+	}}
+	for _, m := range members {
+		result = append(result, playlistMemberJSON(m))
+	}
+	c.JSON(http.StatusOK, gin.H{"playlist_id": playlist.ID, "members": result})
+}
+
+// InvitePlaylistMember invites someone to collaborate on a playlist by email
+func InvitePlaylistMember(c *gin.Context) {
+	playlist, userID, ok := playlistWithRole(c, services.PlaylistRoleOwner)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Email string `json:"email" binding:"required"`
+		Role  string `json:"role"` // "editor" (default) or "viewer"
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
+	}
+	if input.Role == "" {
+		input.Role = services.PlaylistRoleEditor
+	}
+
+	member, err := services.InvitePlaylistMember(playlist, userID, input.Email, input.Role)
+	if err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": "Failed to invite member", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Invitation sent", "member": playlistMemberJSON(member)})
+}
+
+// UpdatePlaylistMember changes the role of a member or pending invitation
+func UpdatePlaylistMember(c *gin.Context) {
+	playlist, userID, ok := playlistWithRole(c, services.PlaylistRoleOwner)
+	if !ok {
+		return
+	}
+	memberID, err := uuid.Parse(c.Param("memberID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid member ID"})
+		return
+	}
+
+	var input struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
+	}
+
+	member, err := services.UpdatePlaylistMemberRole(playlist.ID, userID, memberID, input.Role)
+	if err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": "Failed to update member", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"member": playlistMemberJSON(member)})
+}
+
+// RemovePlaylistMember removes a member or cancels an invitation. The owner can remove anyone;
+// members can remove themselves to leave the playlist.
+func RemovePlaylistMember(c *gin.Context) {
+	playlist, userID, ok := playlistWithRole(c, services.PlaylistRoleViewer)
+	if !ok {
+		return
+	}
+	memberID, err := uuid.Parse(c.Param("memberID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid member ID"})
+		return
+	}
+
+	if playlist.OwnerID != userID {
+		var member db.PlaylistMember
+		if err := db.DB.Where("id = ? AND playlist_id = ?", memberID, playlist.ID).First(&member).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+			return
+		}
+		if member.UserID == nil || *member.UserID != userID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can remove other members"})
+			return
+		}
+	}
+
+	if err := services.RemovePlaylistMember(playlist.ID, userID, memberID); err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": "Failed to remove member", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+// --- INVITATIONS ---
+
+// currentUser loads the authenticated user, writing the error response otherwise
+func currentUser(c *gin.Context) (db.User, bool) {
+	var user db.User
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return user, false
+	}
+	if err := db.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return user, false
+	}
+	return user, true
+}
+
+// GetPlaylistInvites lists the pending playlist invitations for the current user
+func GetPlaylistInvites(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	invites, err := services.PendingPlaylistInvites(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations", "details": err.Error()})
+		return
+	}
+
+	result := []gin.H{}
+	for _, invite := range invites {
+		entry := playlistMemberJSON(invite)
+		var playlist db.Playlist
+		if err := db.DB.Select("id", "title", "cover_image").Where("id = ?", invite.PlaylistID).First(&playlist).Error; err == nil {
+			entry["playlist_title"] = playlist.Title
+			entry["cover_image"] = playlist.CoverImage
+		}
+		result = append(result, entry)
+	}
+	c.JSON(http.StatusOK, gin.H{"invites": result})
+}
+
+// AcceptPlaylistInvite joins the playlist of a pending invitation
+func AcceptPlaylistInvite(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	inviteID, err := uuid.Parse(c.Param("inviteID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	member, err := services.AcceptPlaylistInvite(inviteID, user)
+	if err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": "Failed to accept invitation", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation accepted", "member": playlistMemberJSON(member)})
+}
+
+// DeclinePlaylistInvite discards a pending invitation
+func DeclinePlaylistInvite(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	inviteID, err := uuid.Parse(c.Param("inviteID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	if err := services.DeclinePlaylistInvite(inviteID, user); err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": "Failed to decline invitation", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
+}
+
+// --- TRACK EDITS ---
+
+// AddPlaylistTrack adds a track to a playlist, at a 1-based position or at the end. The track is
+// given by a link ("url"), a platform track ID ("platform" and "platform_id"), a search
+// ("query"), or explicit metadata. Links, IDs and searches are resolved to every platform.
+func AddPlaylistTrack(c *gin.Context) {
+	playlist, userID, ok := playlistWithRole(c, services.PlaylistRoleEditor)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		URL           string `json:"url"`
+		Platform      string `json:"platform"`
+		PlatformID    string `json:"platform_id"`
+		Query         string `json:"query"`
+		Title         string `json:"title"`
+		Artist        string `json:"artist"`
+		Album         string `json:"album"`
+		DurationMs    int    `json:"duration_ms"`
+		ISRC          string `json:"isrc"`
+		SpotifyID     string `json:"spotify_id"`
+		YouTubeID     string `json:"youtube_id"`
+		AppleMusicID  string `json:"applemusic_id"`
+		DeezerID      string `json:"deezer_id"`
+		TidalID       string `json:"tidal_id"`
+		SoundCloudURL string `json:"soundcloud_url"`
+		Position      int    `json:"position"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var track db.Track
+	switch {
+	case input.URL != "":
+		resolved, err := services.ResolveTrackURL(ctx, input.URL)
+		if err != nil {
+			var urlErr *services.PlaylistURLError
+			if errors.As(err, &urlErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": urlErr.Message, "code": urlErr.Code})
+				return
+			}
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to resolve track", "details": err.Error()})
+			return
+		}
+		track = resolved.PlaylistTrack()
+
+	case input.Platform != "" && input.PlatformID != "":
+		resolved, err := services.ResolveTrack(ctx, input.Platform, input.PlatformID)
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to resolve track", "details": err.Error()})
+			return
+		}
+		track = resolved.PlaylistTrack()
+
+	case input.Query != "":
+		user, ok := currentUser(c)
+		if !ok {
+			return
+		}
+		found, err := services.FindTrack(ctx, user, input.Query)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No matching track found", "details": err.Error()})
+			return
+		}
+		track = found
+
+	case input.Title != "":
+		track = db.Track{
+			Title:         input.Title,
+			Artist:        input.Artist,
+			Album:         input.Album,
+			DurationMs:    input.DurationMs,
+			ISRC:          input.ISRC,
+			SpotifyID:     input.SpotifyID,
+			YouTubeID:     input.YouTubeID,
+			AppleMusicID:  input.AppleMusicID,
+			DeezerID:      input.DeezerID,
+			TidalID:       input.TidalID,
+			SoundCloudURL: input.SoundCloudURL,
+		}
+		if err := services.ValidateTrackLinks(track); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid track", "details": err.Error()})
+			return
+		}
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide a url, a platform and platform_id, a query, or a title"})
+		return
+	}
+
+	track, err := services.AddPlaylistTrack(playlist.ID, userID, track, input.Position)
+	if err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": "Failed to add track", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Track added", "track": trackJSON(track)})
+}
+
+// RemovePlaylistTrack removes a track from a playlist
+func RemovePlaylistTrack(c *gin.Context) {
+	playlist, userID, ok := playlistWithRole(c, services.PlaylistRoleEditor)
+	if !ok {
+		return
+	}
+	trackID, err := uuid.Parse(c.Param("trackID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid track ID"})
+		return
+	}
+
+	if err := services.RemovePlaylistTrack(playlist.ID, userID, trackID); err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": "Failed to remove track", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Track removed"})
+}
+
+// ReorderPlaylistTracks sets the order of a playlist from the full list of its track IDs
+func ReorderPlaylistTracks(c *gin.Context) {
+	playlist, userID, ok := playlistWithRole(c, services.PlaylistRoleEditor)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		TrackIDs []uuid.UUID `json:"track_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
+	}
+
+	if err := services.ReorderPlaylistTracks(playlist.ID, userID, input.TrackIDs); err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": "Failed to reorder tracks", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Tracks reordered"})
+}
+
+// --- ACTIVITY AND SYNC ---
+
+// GetPlaylistActivity lists recent changes to a playlist, newest first
+func GetPlaylistActivity(c *gin.Context) {
+	playlist, _, ok := playlistWithRole(c, services.PlaylistRoleViewer)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	var entries []db.PlaylistActivity
+	if err := db.DB.Where("playlist_id = ?", playlist.ID).Order("created_at DESC").Limit(limit).Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch activity", "details": err.Error()})
+		return
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(entries))
+	for _, e := range entries {
+		userIDs = append(userIDs, e.UserID)
+	}
+	var users []db.User
+	db.DB.Select("id", "username", "email").Where("id IN ?", userIDs).Find(&users)
+	names := make(map[uuid.UUID]string, len(users))
+	for _, u := range users {
+		names[u.ID] = u.Username
+		if names[u.ID] == "" {
+			names[u.ID] = u.Email
+		}
+	}
+
+	result := []gin.H{}
+	for _, e := range entries {
+		var details map[string]interface{}
+		json.Unmarshal([]byte(e.Details), &details)
+		result = append(result, gin.H{
+			"id":         e.ID,
+			"user_id":    e.UserID,
+			"user":       names[e.UserID],
+			"action":     e.Action,
+			"details":    details,
+			"created_at": e.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"playlist_id": playlist.ID, "activity": result})
+}
+
+// SetPlaylistMemberSync lets a member opt in to (or out of) member syncs started by the owner
+func SetPlaylistMemberSync(c *gin.Context) {
+	playlist, userID, ok := playlistWithRole(c, services.PlaylistRoleViewer)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Enabled *bool `json:"enabled" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
+	}
+
+	member, err := services.SetPlaylistMemberSync(playlist.ID, userID, *input.Enabled)
+	if err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": "Failed to update member sync", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"member": playlistMemberJSON(member)})
+}
+
+// SyncPlaylistMembers pushes the current playlist to the linked platforms of the owner and
+// every member who opted in, with one worker pool job per person. Each job writes into the
+// playlists that person's earlier syncs created, so repeated calls do not create new ones.
+func SyncPlaylistMembers(c *gin.Context) {
+	playlist, userID, ok := playlistWithRole(c, services.PlaylistRoleOwner)
+	if !ok {
+		return
+	}
+	if WorkerPool == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "No worker available"})
+		return
+	}
+
+	users, err := services.PlaylistSyncMembers(playlist)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch members", "details": err.Error()})
+		return
+	}
+
+	jobs := []gin.H{}
+	skipped := []uuid.UUID{}
+	for _, u := range users {
+		platforms := services.LinkedSyncPlatforms(u)
+		if len(platforms) == 0 {
+			skipped = append(skipped, u.ID)
+			continue
+		}
+		destinations, err := services.SyncDestinations(u.ID, playlist.ID)
+		if err != nil {
+			jobs = append(jobs, gin.H{"user_id": u.ID, "platforms": platforms, "error": err.Error()})
+			continue
+		}
+		jobID, err := queueSyncJob(u.ID, playlist.ID, platforms, destinations, false)
+		if err != nil {
+			jobs = append(jobs, gin.H{"user_id": u.ID, "platforms": platforms, "error": err.Error()})
+			continue
+		}
+		jobs = append(jobs, gin.H{
+			"user_id":      u.ID,
+			"platforms":    platforms,
+			"job_id":       jobID,
+			"status":       "pending",
+			"destinations": destinations,
+		})
+	}
+
+	services.LogPlaylistActivity(db.DB, playlist.ID, userID, "members_synced", map[string]interface{}{
+		"jobs":    len(jobs),
+		"skipped": len(skipped),
+	})
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Sync started for playlist members",
+		"jobs":    jobs,
+		"skipped": skipped, // members without a linked platform
+	})
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"EchoBridge/db"
+	"EchoBridge/internal/auth"
+	"EchoBridge/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ownedPlaylist loads a playlist owned by the current user, writing the error response otherwise
+func ownedPlaylist(c *gin.Context) (db.Playlist, bool) {
+	var playlist db.Playlist
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return playlist, false
+	}
+	playlistID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid playlist ID"})
+		return playlist, false
+	}
+	playlist, _, err = services.LoadPlaylistWithRole(playlistID, userID, services.PlaylistRoleOwner)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist not found or unauthorized"})
+		return playlist, false
+	}
+	return playlist, true
+}
+
+func playlistShareTokenJSON(playlist db.Playlist, t db.PlaylistShareToken) gin.H {
+	status := "active"
+	if t.RevokedAt != nil {
+		status = "revoked"
+	} else if t.ExpiresAt != nil && time.Now().After(*t.ExpiresAt) {
+		status = "expired"
+	}
+	return gin.H{
+		"id":         t.ID,
+		"token":      t.Token,
+		"permission": t.Permission,
+		"url":        auth.FrontendURL + "/playlist/" + playlist.ID.String() + "?share_token=" + t.Token,
+		"expires_at": t.ExpiresAt,
+		"revoked_at": t.RevokedAt,
+		"status":     status,
+		"created_at": t.CreatedAt,
+	}
+}
+
+// CreatePlaylistShareToken creates an unlisted link to a playlist, so it can be shared
+// without making it public. Permission is "view" (default) or "import".
+func CreatePlaylistShareToken(c *gin.Context) {
+	playlist, ok := ownedPlaylist(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Permission     string     `json:"permission" binding:"omitempty,oneof=view import"`
+		ExpiresAt      *time.Time `json:"expires_at"`
+		ExpiresInHours int        `json:"expires_in_hours" binding:"min=0"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
+	}
+	if input.Permission == "" {
+		input.Permission = services.PlaylistShareView
+	}
+	if input.ExpiresInHours > 0 {
+		expiresAt := time.Now().Add(time.Duration(input.ExpiresInHours) * time.Hour)
+		input.ExpiresAt = &expiresAt
+	}
+	if input.ExpiresAt != nil && input.ExpiresAt.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expiry must be in the future"})
+		return
+	}
+
+	token, err := services.CreatePlaylistShareToken(playlist.ID, input.Permission, input.ExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, playlistShareTokenJSON(playlist, token))
+}
+
+// ListPlaylistShareTokens lists a playlist's share links, including expired and revoked ones
+func ListPlaylistShareTokens(c *gin.Context) {
+	playlist, ok := ownedPlaylist(c)
+	if !ok {
+		return
+	}
+
+	var tokens []db.PlaylistShareToken
+	if err := db.DB.Where("playlist_id = ?", playlist.ID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch share links"})
+		return
+	}
+
+	result := []gin.H{}
+	for _, t := range tokens {
+		result = append(result, playlistShareTokenJSON(playlist, t))
+	}
+	c.JSON(http.StatusOK, gin.H{"share_tokens": result})
+}
+
+// RevokePlaylistShareToken disables a share link immediately
+func RevokePlaylistShareToken(c *gin.Context) {
+	playlist, ok := ownedPlaylist(c)
+	if !ok {
+		return
+	}
+
+	tokenID, err := uuid.Parse(c.Param("tokenID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share token ID"})
+		return
+	}
+
+	result := db.DB.Model(&db.PlaylistShareToken{}).
+		Where("id = ? AND playlist_id = ? AND revoked_at IS NULL", tokenID, playlist.ID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke share link", "details": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found or already revoked"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Share link revoked"})
+}
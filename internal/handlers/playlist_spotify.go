@@ -65,7 +65,7 @@ func SpotifyPlaylistTracks(c *gin.Context) {
 		return
 	}
 
-	client, err := services.GetSpotifyClient(c.Request.Context(), dbUser)
+	client, err := services.GetSpotifyReadClient(c.Request.Context(), dbUser)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Spotify not linked"})
 		return
@@ -120,14 +120,14 @@ func ImportToSpotify(c *gin.Context) {
 		return
 	}
 
-	// Check if user has access (Owner or Public)
-	if playlist.OwnerID != userID && !playlist.IsPublic {
+	// Check if user has access (Member or Public)
+	if !services.CanViewPlaylist(playlist, userID) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this playlist"})
 		return
 	}
 
 	var tracks []db.Track
-	if err := db.DB.Where("playlist_id = ?", playlistID).Find(&tracks).Error; err != nil {
+	if err := db.DB.Where("playlist_id = ?", playlistID).Order(services.TrackOrder).Find(&tracks).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tracks"})
 		return
 	}
@@ -3,10 +3,12 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 
 	"EchoBridge/db"
+	"EchoBridge/internal/services"
 	"EchoBridge/internal/temporal"
 	"EchoBridge/internal/worker"
 
@@ -38,20 +40,33 @@ func SyncPlaylist(c *gin.Context) {
 		return
 	}
 
+	// Members sync a shared playlist into their own linked accounts
+	if _, _, err := services.LoadPlaylistWithRole(playlistID, userID, services.PlaylistRoleViewer); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist not found or unauthorized"})
+		return
+	}
+
+	status, response := startSyncJob(userID, playlistID, input.Platforms)
+	c.JSON(status, response)
+}
+
+// startSyncJob records a sync job and runs it through Temporal, falling back to the worker pool.
+// It returns the HTTP status and body describing the started job.
+func startSyncJob(userID, playlistID uuid.UUID, platforms []string) (int, gin.H) {
 	// Create SyncJob record
 	jobID := uuid.New()
-	platformsJSON, _ := json.Marshal(input.Platforms)
+	platformsJSON, _ := json.Marshal(platforms)
 	syncJob := db.SyncJob{
 		ID:         jobID,
 		UserID:     userID,
 		PlaylistID: playlistID,
 		Platforms:  string(platformsJSON),
 		Status:     "pending",
+		Runner:     "worker",
 		CreatedAt:  time.Now(),
 	}
 	if err := db.DB.Create(&syncJob).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create sync job", "details": err.Error()})
-		return
+		return http.StatusInternalServerError, gin.H{"error": "Failed to create sync job", "details": err.Error()}
 	}
 
 	// Try to use Temporal if available
@@ -63,25 +78,24 @@ func SyncPlaylist(c *gin.Context) {
 		}
 
 		workflowInput := temporal.PlaylistSyncInput{
+			JobID:      jobID,
 			UserID:     userID,
 			PlaylistID: playlistID,
-			Platforms:  input.Platforms,
+			Platforms:  platforms,
 			TestMode:   true, // Enable test mode for demo
 		}
 
 		we, err := temporalClient.ExecuteWorkflow(context.Background(), workflowOptions, temporal.PlaylistSyncWorkflow, workflowInput)
 		if err != nil {
 			// Fall back to worker pool
-			fallbackToWorkerPool(c, jobID, userID, playlistID, input.Platforms)
-			return
+			return fallbackToWorkerPool(jobID, userID, playlistID, platforms)
 		}
 
-		// Update job with workflow ID
-		db.DB.Model(&syncJob).Updates(map[string]interface{}{
-			"status": "processing",
-		})
+		// Update job with workflow ID. A short workflow may already have finished the job.
+		db.DB.Model(&syncJob).Update("runner", "temporal")
+		db.DB.Model(&db.SyncJob{}).Where("id = ? AND status = ?", jobID, "pending").Update("status", "processing")
 
-		c.JSON(http.StatusAccepted, gin.H{
+		return http.StatusAccepted, gin.H{
 			"message":     "Sync started via Temporal workflow",
 			"job_id":      jobID,
 			"workflow_id": we.GetID(),
@@ -89,18 +103,52 @@ func SyncPlaylist(c *gin.Context) {
 			"playlist_id": playlistID,
 			"status":      "processing",
 			"temporal_ui": "http://localhost:8233",
-		})
-		return
+		}
 	}
 
 	// Fall back to worker pool
-	fallbackToWorkerPool(c, jobID, userID, playlistID, input.Platforms)
+	return fallbackToWorkerPool(jobID, userID, playlistID, platforms)
 }
 
-func fallbackToWorkerPool(c *gin.Context, jobID, userID, playlistID uuid.UUID, platforms []string) {
+// queueSyncJob records a worker pool sync job and submits it. The job starts with the given
+// destinations as its result, so the sync writes into those playlists instead of creating new ones.
+// With replace, the worker empties the destinations before filling them.
+func queueSyncJob(userID, playlistID uuid.UUID, platforms []string, destinations map[string]string, replace bool) (uuid.UUID, error) {
+	result := make(map[string]string)
+	for _, platform := range platforms {
+		if id := destinations[platform]; id != "" {
+			result[platform] = id
+		}
+	}
+	jobID := uuid.New()
+	platformsJSON, _ := json.Marshal(platforms)
+	resultJSON, _ := json.Marshal(result)
+	if err := db.DB.Create(&db.SyncJob{
+		ID:         jobID,
+		UserID:     userID,
+		PlaylistID: playlistID,
+		Platforms:  string(platformsJSON),
+		Status:     "pending",
+		Runner:     "worker",
+		Result:     string(resultJSON),
+		Replace:    replace,
+		CreatedAt:  time.Now(),
+	}).Error; err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create sync job: %w", err)
+	}
+	WorkerPool.Submit(worker.Job{
+		Type:       "sync",
+		JobID:      jobID,
+		UserID:     userID,
+		PlaylistID: playlistID,
+		Platforms:  platforms,
+	})
+	return jobID, nil
+}
+
+func fallbackToWorkerPool(jobID, userID, playlistID uuid.UUID, platforms []string) (int, gin.H) {
 	if WorkerPool == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "No worker available"})
-		return
+		return http.StatusInternalServerError, gin.H{"error": "No worker available"}
 	}
 
 	job := worker.Job{
@@ -112,12 +160,12 @@ func fallbackToWorkerPool(c *gin.Context, jobID, userID, playlistID uuid.UUID, p
 	}
 	WorkerPool.Submit(job)
 
-	c.JSON(http.StatusAccepted, gin.H{
+	return http.StatusAccepted, gin.H{
 		"message":     "Sync started via worker pool (Temporal unavailable)",
 		"job_id":      jobID,
 		"playlist_id": playlistID,
 		"status":      "pending",
-	})
+	}
 }
 
 // GetSyncStatus retrieves the status of a sync job
@@ -150,5 +198,112 @@ func GetSyncStatus(c *gin.Context) {
 		response["error"] = job.ErrorMsg
 	}
 
+	// Per-track checkpoint counts, e.g. {"youtube": {"added": 42, "not_found": 3}}
+	var counts []struct {
+		Platform string
+		Status   string
+		Count    int
+	}
+	db.DB.Model(&db.SyncCheckpoint{}).
+		Select("platform, status, count(*) as count").
+		Where("job_id = ?", job.ID).
+		Group("platform, status").
+		Scan(&counts)
+	if len(counts) > 0 {
+		progress := make(map[string]map[string]int)
+		for _, row := range counts {
+			if progress[row.Platform] == nil {
+				progress[row.Platform] = make(map[string]int)
+			}
+			progress[row.Platform][row.Status] = row.Count
+		}
+		response["progress"] = progress
+	}
+	response["resumable"] = job.Runner != "temporal" && (job.Status == "failed" || job.Status == "interrupted")
+
 	c.JSON(http.StatusOK, response)
 }
+
+// ResumeSyncJob re-queues a failed or interrupted worker pool sync job.
+// Checkpointed tracks are skipped and the existing destination playlists are reused.
+func ResumeSyncJob(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	jobID, err := uuid.Parse(c.Param("jobID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	var job db.SyncJob
+	if err := db.DB.Where("id = ? AND user_id = ?", jobID, userID).First(&job).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	if job.Runner == "temporal" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Temporal workflows resume automatically",
+			"code":  "JOB_NOT_RESUMABLE",
+		})
+		return
+	}
+	if job.Status != "failed" && job.Status != "interrupted" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  fmt.Sprintf("Only failed or interrupted jobs can be resumed (status is %s)", job.Status),
+			"code":   "JOB_NOT_RESUMABLE",
+			"status": job.Status,
+		})
+		return
+	}
+
+	var platforms []string
+	if err := json.Unmarshal([]byte(job.Platforms), &platforms); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid job platforms", "details": err.Error()})
+		return
+	}
+
+	if WorkerPool == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "No worker available"})
+		return
+	}
+
+	// Claim the job in one statement so concurrent resumes cannot both submit it
+	result := db.DB.Model(&db.SyncJob{}).
+		Where("id = ? AND status IN ?", job.ID, []string{"failed", "interrupted"}).
+		Updates(map[string]interface{}{
+			"status":    "pending",
+			"runner":    "worker",
+			"error_msg": "",
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update sync job", "details": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected != 1 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Job is already being resumed",
+			"code":  "JOB_NOT_RESUMABLE",
+		})
+		return
+	}
+
+	WorkerPool.Submit(worker.Job{
+		Type:       "sync",
+		JobID:      job.ID,
+		UserID:     userID,
+		PlaylistID: job.PlaylistID,
+		Platforms:  platforms,
+	})
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":     "Sync resumed via worker pool",
+		"job_id":      job.ID,
+		"playlist_id": job.PlaylistID,
+		"status":      "pending",
+	})
+}
@@ -3,8 +3,10 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"EchoBridge/db"
+	"EchoBridge/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -32,15 +34,20 @@ func UpdateSinglePlaylistPublic(c *gin.Context) {
 		return
 	}
 
-	result := db.DB.Model(&db.Playlist{}).
-		Where("id = ? AND owner_id = ?", playlistID, userID).
-		Update("is_public", input.IsPublic)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update playlist", "details": result.Error.Error()})
+	// Only the owner decides who can see a playlist
+	playlist, _, err := services.LoadPlaylistWithRole(playlistID, userID, services.PlaylistRoleOwner)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist not found or unauthorized"})
 		return
 	}
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist not found or unauthorized"})
+
+	// Publishing a private playlist puts it in followers' feeds
+	updates := map[string]interface{}{"is_public": input.IsPublic}
+	if input.IsPublic && !playlist.IsPublic {
+		updates["published_at"] = time.Now()
+	}
+	if err := db.DB.Model(&db.Playlist{}).Where("id = ?", playlistID).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update playlist", "details": err.Error()})
 		return
 	}
 
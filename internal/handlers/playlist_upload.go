@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"EchoBridge/db"
+	"EchoBridge/internal/services"
+	"EchoBridge/internal/worker"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// UploadPlaylist creates a playlist from an uploaded M3U/M3U8, XSPF or JSPF file.
+// The result has platform "file" and can be synced like any imported playlist.
+func UploadPlaylist(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing playlist file", "details": err.Error()})
+		return
+	}
+	if fileHeader.Size > services.MaxPlaylistFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Playlist file is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read playlist file", "details": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	playlist, tracks, err := services.ParsePlaylistFile(fileHeader.Filename, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse playlist file", "details": err.Error()})
+		return
+	}
+	if len(tracks) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Playlist file contains no tracks"})
+		return
+	}
+
+	importedTracksCount, err := saveUploadedPlaylist(c, userID, &playlist, tracks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create playlist", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Playlist uploaded successfully",
+		"playlist_id":     playlist.ID,
+		"title":           playlist.Title,
+		"tracks_imported": importedTracksCount,
+	})
+}
+
+// UploadPlaylistCSV creates a playlist from a CSV export (Exportify, TuneMyMusic, Soundiiz or
+// EchoBridge's own). Other layouts need a "mapping" form field: a JSON object naming the header
+// for title, artist, album, isrc, duration, spotify_uri and playlist.
+func UploadPlaylistCSV(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing CSV file", "details": err.Error()})
+		return
+	}
+	if fileHeader.Size > services.MaxPlaylistFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "CSV file is too large"})
+		return
+	}
+
+	var mapping *services.CSVColumnMapping
+	if raw := c.PostForm("mapping"); raw != "" {
+		mapping = &services.CSVColumnMapping{}
+		if err := json.Unmarshal([]byte(raw), mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid column mapping", "details": err.Error()})
+			return
+		}
+		if mapping.Title == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Column mapping must name the title column"})
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read CSV file", "details": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	result, err := services.ParsePlaylistCSV(fileHeader.Filename, file, mapping)
+	if err != nil {
+		var layoutErr *services.CSVLayoutError
+		if errors.As(err, &layoutErr) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "columns": layoutErr.Headers})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse CSV file", "details": err.Error()})
+		return
+	}
+	if len(result.Tracks) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file contains no importable tracks", "row_errors": result.RowErrors})
+		return
+	}
+
+	importedTracksCount, err := saveUploadedPlaylist(c, userID, &result.Playlist, result.Tracks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create playlist", "details": err.Error()})
+		return
+	}
+
+	rowErrors := result.RowErrors
+	if rowErrors == nil {
+		rowErrors = []services.CSVRowError{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Playlist imported successfully",
+		"playlist_id":     result.Playlist.ID,
+		"title":           result.Playlist.Title,
+		"format":          result.Format,
+		"tracks_imported": importedTracksCount,
+		"row_errors":      rowErrors,
+	})
+}
+
+// saveUploadedPlaylist applies the optional "title" and "is_public" form fields, stores the
+// playlist and its tracks, and queues categorization. It returns the number of tracks saved.
+func saveUploadedPlaylist(c *gin.Context, userID uuid.UUID, playlist *db.Playlist, tracks []db.Track) (int, error) {
+	if title := strings.TrimSpace(c.PostForm("title")); title != "" {
+		playlist.Title = title
+	}
+	playlist.OwnerID = userID
+	playlist.IsPublic, _ = strconv.ParseBool(c.PostForm("is_public"))
+
+	if err := db.DB.Create(playlist).Error; err != nil {
+		return 0, err
+	}
+
+	importedTracksCount := 0
+	for _, t := range tracks {
+		t.PlaylistID = playlist.ID
+		if err := db.DB.Create(&t).Error; err == nil {
+			importedTracksCount++
+		}
+	}
+	services.RecordImportVersion(playlist.ID, userID)
+
+	// Submit Categorization Job
+	if WorkerPool != nil {
+		WorkerPool.Submit(worker.Job{
+			Type:       "categorize",
+			JobID:      uuid.New(),
+			UserID:     userID,
+			PlaylistID: playlist.ID,
+		})
+	}
+	return importedTracksCount, nil
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+
+	"EchoBridge/db"
+	"EchoBridge/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func playlistVersionJSON(v db.PlaylistVersion) gin.H {
+	return gin.H{
+		"version":    v.Number,
+		"trigger":    v.Trigger,
+		"action":     v.Action,
+		"user_id":    v.UserID,
+		"tracks":     v.TrackCount,
+		"created_at": v.CreatedAt,
+	}
+}
+
+// versionParam parses a version number route param, writing the error response otherwise
+func versionParam(c *gin.Context, name string) (int, bool) {
+	number, err := strconv.Atoi(c.Param(name))
+	if err != nil || number <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version number"})
+		return 0, false
+	}
+	return number, true
+}
+
+// GetPlaylistVersions lists the versions of a playlist, newest first
+func GetPlaylistVersions(c *gin.Context) {
+	playlist, _, ok := playlistWithRole(c, services.PlaylistRoleViewer)
+	if !ok {
+		return
+	}
+
+	versions, err := services.PlaylistVersions(playlist.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch versions", "details": err.Error()})
+		return
+	}
+	response := []gin.H{}
+	for _, v := range versions {
+		response = append(response, playlistVersionJSON(v))
+	}
+	c.JSON(http.StatusOK, gin.H{"playlist_id": playlist.ID, "versions": response})
+}
+
+// GetPlaylistVersion returns a version of a playlist with its tracks
+func GetPlaylistVersion(c *gin.Context) {
+	playlist, _, ok := playlistWithRole(c, services.PlaylistRoleViewer)
+	if !ok {
+		return
+	}
+	number, ok := versionParam(c, "version")
+	if !ok {
+		return
+	}
+
+	version, tracks, err := services.LoadPlaylistVersion(playlist.ID, number)
+	if err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": "Failed to load version", "details": err.Error()})
+		return
+	}
+	trackList := []gin.H{}
+	for _, t := range tracks {
+		trackList = append(trackList, trackJSON(t))
+	}
+	response := playlistVersionJSON(version)
+	response["track_list"] = trackList
+	c.JSON(http.StatusOK, response)
+}
+
+// DiffPlaylistVersions compares two versions of a playlist. The second one may be "current"
+// to compare a version with the playlist as it is now.
+func DiffPlaylistVersions(c *gin.Context) {
+	playlist, _, ok := playlistWithRole(c, services.PlaylistRoleViewer)
+	if !ok {
+		return
+	}
+	number, ok := versionParam(c, "version")
+	if !ok {
+		return
+	}
+
+	versionA, tracksA, err := services.LoadPlaylistVersion(playlist.ID, number)
+	if err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": "Failed to load version", "details": err.Error()})
+		return
+	}
+	sideA := playlistVersionJSON(versionA)
+
+	var tracksB []db.Track
+	var sideB gin.H
+	if c.Param("other") == "current" {
+		if tracksB, err = services.PlaylistTracks(playlist.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tracks", "details": err.Error()})
+			return
+		}
+		sideB = gin.H{"version": "current", "tracks": len(tracksB)}
+	} else {
+		other, ok := versionParam(c, "other")
+		if !ok {
+			return
+		}
+		var versionB db.PlaylistVersion
+		if versionB, tracksB, err = services.LoadPlaylistVersion(playlist.ID, other); err != nil {
+			c.JSON(memberErrorStatus(err), gin.H{"error": "Failed to load version", "details": err.Error()})
+			return
+		}
+		sideB = playlistVersionJSON(versionB)
+	}
+
+	c.JSON(http.StatusOK, playlistDiffJSON(sideA, sideB, services.DiffTracks(tracksA, tracksB)))
+}
+
+// RestorePlaylistVersion rolls a playlist back to an earlier version. With push, the playlists
+// the user synced it to are emptied and synced again so they match the restored tracks.
+func RestorePlaylistVersion(c *gin.Context) {
+	playlist, userID, ok := playlistWithRole(c, services.PlaylistRoleEditor)
+	if !ok {
+		return
+	}
+	number, ok := versionParam(c, "version")
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Push      bool     `json:"push"`
+		Platforms []string `json:"platforms"` // limit the push to these platforms
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+			return
+		}
+	}
+	if input.Push && WorkerPool == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "No worker available"})
+		return
+	}
+
+	tracks, err := services.RestorePlaylistVersion(playlist.ID, userID, number)
+	if err != nil {
+		c.JSON(memberErrorStatus(err), gin.H{"error": "Failed to restore version", "details": err.Error()})
+		return
+	}
+	response := gin.H{
+		"message": "Version restored",
+		"version": number,
+		"tracks":  len(tracks),
+	}
+
+	if input.Push {
+		user, ok := currentUser(c)
+		if !ok {
+			return
+		}
+		response["push"] = pushRestoredPlaylist(user, playlist.ID, input.Platforms)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// pushRestoredPlaylist queues a worker pool sync that empties the user's sync destinations of
+// a playlist and refills them. It returns the outcome per platform and the sync job.
+func pushRestoredPlaylist(user db.User, playlistID uuid.UUID, only []string) gin.H {
+	destinations, err := services.SyncDestinations(user.ID, playlistID)
+	if err != nil {
+		return gin.H{"error": err.Error()}
+	}
+
+	var platforms []string
+	for platform := range destinations {
+		if len(only) == 0 || slices.Contains(only, platform) {
+			platforms = append(platforms, platform)
+		}
+	}
+	sort.Strings(platforms)
+
+	results := gin.H{}
+	var syncPlatforms []string
+	for _, platform := range platforms {
+		if !slices.Contains(services.ReplaceablePlatforms, platform) {
+			results[platform] = gin.H{
+				"playlist_id": destinations[platform],
+				"error":       services.ErrDestinationReplaceUnsupported.Error(),
+				"supported":   false,
+			}
+			continue
+		}
+		syncPlatforms = append(syncPlatforms, platform)
+		results[platform] = gin.H{"playlist_id": destinations[platform], "status": "pending"}
+	}
+	if len(syncPlatforms) == 0 {
+		return gin.H{"platforms": results}
+	}
+
+	jobID, err := queueSyncJob(user.ID, playlistID, syncPlatforms, destinations, true)
+	if err != nil {
+		return gin.H{"platforms": results, "error": err.Error()}
+	}
+	return gin.H{"platforms": results, "job_id": jobID}
+}
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"EchoBridge/db"
+	"EchoBridge/internal/auth"
+	"EchoBridge/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RegisterProfileRoutes sets up user profile, following and feed routes
+func RegisterProfileRoutes(r *gin.Engine) {
+	r.GET("/users/:id", GetUserProfile)
+	r.GET("/users/:id/playlists", GetUserPublicPlaylists)
+	protected := r.Group("/api").Use(auth.AuthMiddleware())
+	protected.GET("/me/profile", GetMyProfile)
+	protected.PATCH("/me/profile", UpdateMyProfile)
+	protected.GET("/me/following", GetFollowing)
+	protected.GET("/me/followers", GetFollowers)
+	protected.POST("/users/:id/follow", FollowUser)
+	protected.DELETE("/users/:id/follow", UnfollowUser)
+	protected.GET("/feed", GetFeed)
+}
+
+// profileJSON describes the public part of a user's profile
+func profileJSON(u db.User) gin.H {
+	return gin.H{
+		"id":           u.ID,
+		"name":         services.ProfileName(u),
+		"display_name": u.DisplayName,
+		"avatar_url":   u.AvatarURL,
+		"bio":          u.Bio,
+		"created_at":   u.CreatedAt,
+	}
+}
+
+// ownerJSON describes a playlist owner, or is nil when their profile is hidden
+func ownerJSON(profiles map[uuid.UUID]db.User, ownerID uuid.UUID) gin.H {
+	owner, ok := profiles[ownerID]
+	if !ok {
+		return nil
+	}
+	return gin.H{"id": owner.ID, "name": services.ProfileName(owner), "avatar_url": owner.AvatarURL}
+}
+
+func profileErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrProfileNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrCannotFollowSelf), errors.Is(err, services.ErrInvalidProfile):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// profileParam parses the user ID route param, writing the error response otherwise
+func profileParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// GetUserProfile returns a user's public profile with follow counts. Hidden profiles are not found,
+// except by their owner.
+func GetUserProfile(c *gin.Context) {
+	id, ok := profileParam(c)
+	if !ok {
+		return
+	}
+	viewerID, loggedIn := auth.OptionalUserID(c)
+	user, err := services.LoadProfile(id, viewerID)
+	if err != nil {
+		c.JSON(profileErrorStatus(err), gin.H{"error": "Profile not found"})
+		return
+	}
+
+	followers, following, err := services.FollowCounts(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch profile", "details": err.Error()})
+		return
+	}
+	var playlists int64
+	if err := db.DB.Model(&db.Playlist{}).Where("owner_id = ? AND is_public = ?", user.ID, true).Count(&playlists).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch profile", "details": err.Error()})
+		return
+	}
+
+	response := profileJSON(user)
+	response["followers"] = followers
+	response["following"] = following
+	response["public_playlists"] = playlists
+	if loggedIn && viewerID != user.ID {
+		response["is_following"] = services.IsFollowing(viewerID, user.ID)
+	}
+	if viewerID == user.ID {
+		response["hidden"] = user.ProfileHidden
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUserPublicPlaylists retrieves a page of a user's public playlists, with the same
+// parameters as the public playlist listing
+func GetUserPublicPlaylists(c *gin.Context) {
+	id, ok := profileParam(c)
+	if !ok {
+		return
+	}
+	viewerID, _ := auth.OptionalUserID(c)
+	user, err := services.LoadProfile(id, viewerID)
+	if err != nil {
+		c.JSON(profileErrorStatus(err), gin.H{"error": "Profile not found"})
+		return
+	}
+
+	page, err := services.BrowsePlaylists(db.DB.Where("playlists.is_public = ? AND playlists.owner_id = ?", true, user.ID), playlistQuery(c))
+	if err != nil {
+		browseError(c, err)
+		return
+	}
+
+	result := []gin.H{}
+	for _, p := range page.Playlists {
+		entry := publicPlaylistJSON(p)
+		entry["tracks"] = page.TrackCounts[p.ID]
+		entry["popularity"] = page.Popularity[p.ID]
+		result = append(result, entry)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Public playlists retrieved",
+		"user":        profileJSON(user),
+		"playlists":   result,
+		"next_cursor": page.NextCursor,
+		"has_more":    page.NextCursor != "",
+	})
+}
+
+// GetMyProfile returns the current user's profile, including whether it is hidden
+func GetMyProfile(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	response := profileJSON(user)
+	response["hidden"] = user.ProfileHidden
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateMyProfile changes the current user's display name, avatar, bio or visibility.
+// Fields left out of the body keep their value.
+func UpdateMyProfile(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input struct {
+		DisplayName *string `json:"display_name"`
+		AvatarURL   *string `json:"avatar_url"`
+		Bio         *string `json:"bio"`
+		Hidden      *bool   `json:"hidden"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input", "details": err.Error()})
+		return
+	}
+
+	user, err := services.UpdateProfile(userID, services.ProfileUpdate{
+		DisplayName: input.DisplayName,
+		AvatarURL:   input.AvatarURL,
+		Bio:         input.Bio,
+		Hidden:      input.Hidden,
+	})
+	if err != nil {
+		c.JSON(profileErrorStatus(err), gin.H{"error": "Failed to update profile", "details": err.Error()})
+		return
+	}
+	response := profileJSON(user)
+	response["hidden"] = user.ProfileHidden
+	c.JSON(http.StatusOK, gin.H{"message": "Profile updated", "profile": response})
+}
+
+// FollowUser follows a user with a visible profile
+func FollowUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	id, ok := profileParam(c)
+	if !ok {
+		return
+	}
+
+	if err := services.FollowUser(userID, id); err != nil {
+		c.JSON(profileErrorStatus(err), gin.H{"error": "Failed to follow user", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "User followed", "user_id": id})
+}
+
+// UnfollowUser stops following a user
+func UnfollowUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	id, ok := profileParam(c)
+	if !ok {
+		return
+	}
+
+	if err := services.UnfollowUser(userID, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unfollow user", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "User unfollowed", "user_id": id})
+}
+
+// GetFollowing lists the users the current user follows
+func GetFollowing(c *gin.Context) {
+	listFollows(c, services.FollowedUsers)
+}
+
+// GetFollowers lists the users following the current user
+func GetFollowers(c *gin.Context) {
+	listFollows(c, services.Followers)
+}
+
+func listFollows(c *gin.Context, list func(uuid.UUID) ([]db.User, error)) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	users, err := list(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users", "details": err.Error()})
+		return
+	}
+	result := []gin.H{}
+	for _, u := range users {
+		result = append(result, profileJSON(u))
+	}
+	c.JSON(http.StatusOK, gin.H{"users": result})
+}
+
+// GetFeed lists the public playlists that followed users recently published or updated,
+// newest first. Pages continue with the cursor parameter set to next_cursor.
+func GetFeed(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	page, err := services.FollowFeed(userID, c.Query("cursor"), limit)
+	if err != nil {
+		browseError(c, err)
+		return
+	}
+
+	items := []gin.H{}
+	for _, item := range page.Items {
+		playlist := publicPlaylistJSON(item.Playlist)
+		playlist["tracks"] = item.TrackCount
+		items = append(items, gin.H{
+			"kind":     item.Kind,
+			"at":       item.At,
+			"owner":    profileJSON(item.Owner),
+			"playlist": playlist,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Feed retrieved",
+		"items":       items,
+		"next_cursor": page.NextCursor,
+		"has_more":    page.NextCursor != "",
+	})
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// clientLimiterIdle is how long a client's limiter is kept after its last request
+const clientLimiterIdle = 10 * time.Minute
+
+// clientRateLimiter throttles requests per client address with a token bucket each
+type clientRateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu        sync.Mutex
+	clients   map[string]*clientLimiter
+	lastPrune time.Time
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newClientRateLimiter(limit rate.Limit, burst int) *clientRateLimiter {
+	return &clientRateLimiter{limit: limit, burst: burst, clients: make(map[string]*clientLimiter)}
+}
+
+// reserve takes a token for the client, returning how long to wait when none is left
+func (l *clientRateLimiter) reserve(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastPrune) > time.Minute {
+		for key, cl := range l.clients {
+			if now.Sub(cl.lastSeen) > clientLimiterIdle {
+				delete(l.clients, key)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	cl, ok := l.clients[client]
+	if !ok {
+		cl = &clientLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[client] = cl
+	}
+	cl.lastSeen = now
+	r := cl.limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// Middleware rejects requests over the limit with 429 and a Retry-After header
+func (l *clientRateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ok, wait := l.reserve(c.ClientIP()); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, try again later"})
+			return
+		}
+		c.Next()
+	}
+}
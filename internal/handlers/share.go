@@ -2,23 +2,49 @@ package handlers
 
 import (
 	"EchoBridge/internal/auth"
-	"fmt"
+	"errors"
+	"log"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"EchoBridge/db"
+	"EchoBridge/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"golang.org/x/time/rate"
 )
 
+// resolveLimiter throttles the public track resolver per client: every uncached link costs
+// searches on the server's Spotify, YouTube and Apple Music credentials
+var resolveLimiter = newClientRateLimiter(rate.Every(6*time.Second), 10)
+
 // RegisterShareRoutes sets up song sharing routes
 func RegisterShareRoutes(r *gin.Engine) {
+	r.GET("/resolve/track", resolveLimiter.Middleware(), ResolveTrack)
+	r.GET("/s/:code", ShareLanding)
+	r.GET("/s/:code/go/:platform", ShareRedirect)
 	protected := r.Group("/api").Use(auth.AuthMiddleware())
 	protected.POST("/share/track/:id", ShareTrack)
+	protected.GET("/shares", GetUserShares)
+	protected.GET("/shares/:code/stats", GetShareStats)
+}
+
+// shareCookie remembers the platform a visitor last picked on a share page
+const shareCookie = "eb_platform"
+
+// shareBaseURL is the public address share links point to. Links are stored, so the address
+// comes from PUBLIC_URL (or the frontend URL) rather than from request headers.
+func shareBaseURL() string {
+	if base := os.Getenv("PUBLIC_URL"); base != "" {
+		return strings.TrimSuffix(base, "/")
+	}
+	return strings.TrimSuffix(auth.FrontendURL, "/")
 }
 
-// ShareTrack generates platform-specific and custom links for a track
+// ShareTrack creates (or reuses) a short share link for a track and returns its platform links
 func ShareTrack(c *gin.Context) {
 	userID, err := uuid.Parse(c.GetString("userID"))
 	if err != nil {
@@ -44,36 +70,256 @@ func ShareTrack(c *gin.Context) {
 		return
 	}
 
-	links := make(map[string]string)
-	if track.SpotifyID != "" && dbUser.SpotifyToken != "" {
-		links["spotify"] = fmt.Sprintf("https://open.spotify.com/track/%s", track.SpotifyID)
-	}
-	if track.YouTubeID != "" && dbUser.YouTubeToken != "" {
-		links["youtube"] = fmt.Sprintf("https://music.youtube.com/watch?v=%s", track.YouTubeID)
+	// Check if user has access (Member or Public)
+	var playlist db.Playlist
+	if err := db.DB.Where("id = ?", track.PlaylistID).First(&playlist).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist not found"})
+		return
 	}
-	if track.AppleMusicID != "" && dbUser.AppleMusicToken != "" {
-		links["applemusic"] = fmt.Sprintf("https://music.apple.com/us/song/%s", track.AppleMusicID)
+	if !services.CanViewPlaylist(playlist, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this playlist"})
+		return
 	}
 
-	customLink := fmt.Sprintf("https://yourdomain.com/share/track/%s", track.ID)
-	links["custom"] = customLink
-
-	share := db.Share{
-		ID:        uuid.New(),
-		TrackID:   trackID,
-		UserID:    userID,
-		CustomURL: customLink,
-		CreatedAt: time.Now(),
-	}
-	if err := db.DB.Create(&share).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save share link", "details": err.Error()})
-		return
+	var share db.Share
+	err = db.DB.Where("track_id = ? AND user_id = ? AND code <> ''", trackID, userID).First(&share).Error
+	if err != nil {
+		code, err := services.NewShareCode()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share code", "details": err.Error()})
+			return
+		}
+		share = db.Share{
+			ID:        uuid.New(),
+			TrackID:   trackID,
+			UserID:    userID,
+			Code:      code,
+			CustomURL: shareBaseURL() + "/s/" + code,
+			CreatedAt: time.Now(),
+		}
+		if err := db.DB.Create(&share).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save share link", "details": err.Error()})
+			return
+		}
 	}
 
+	links := services.TrackLinks(track, dbUser.AppleMusicStorefront)
+	delete(links, "musicbrainz")
+	links["custom"] = share.CustomURL
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Track links generated",
 		"track":   track.Title,
 		"artist":  track.Artist,
+		"code":    share.Code,
 		"links":   links,
 	})
-}
\ No newline at end of file
+}
+
+// loadShare finds a share by code together with its track
+func loadShare(c *gin.Context) (db.Share, db.Track, bool) {
+	var share db.Share
+	if err := db.DB.Where("code = ?", c.Param("code")).First(&share).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share not found"})
+		return share, db.Track{}, false
+	}
+	var track db.Track
+	if err := db.DB.Where("id = ?", share.TrackID).First(&track).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Track no longer exists"})
+		return share, track, false
+	}
+	return share, track, true
+}
+
+// ShareLanding serves the public page of a share: HTML with OpenGraph tags, or JSON when
+// ?format=json or Accept: application/json. A visitor with a preferred platform (?to= or
+// the cookie set by ShareRedirect) is sent straight there unless ?redirect=0.
+func ShareLanding(c *gin.Context) {
+	share, track, ok := loadShare(c)
+	if !ok {
+		return
+	}
+	links := services.TrackLinks(track, "")
+	delete(links, "musicbrainz")
+
+	wantsJSON := c.Query("format") == "json" || strings.Contains(c.GetHeader("Accept"), "application/json")
+	if !wantsJSON && c.Query("redirect") != "0" {
+		preferred := c.Query("to")
+		if preferred == "" {
+			preferred, _ = c.Cookie(shareCookie)
+		}
+		if link, ok := links[preferred]; ok && services.IsPlatformURL(preferred, link) {
+			if err := services.RecordShareClick(share.ID, preferred); err != nil {
+				log.Printf("Failed to record share click: %v", err)
+			}
+			c.Redirect(http.StatusFound, link)
+			return
+		}
+	}
+
+	if err := services.RecordShareView(share.ID); err != nil {
+		log.Printf("Failed to record share view: %v", err)
+	}
+	artwork := services.TrackArtwork(track)
+	pageURL := shareBaseURL() + "/s/" + share.Code
+
+	if wantsJSON {
+		c.JSON(http.StatusOK, gin.H{
+			"code":        share.Code,
+			"url":         pageURL,
+			"title":       track.Title,
+			"artist":      track.Artist,
+			"album":       track.Album,
+			"duration_ms": track.DurationMs,
+			"artwork":     artwork,
+			"links":       links,
+		})
+		return
+	}
+
+	page := sharePage{
+		Title:   track.Title,
+		Artist:  track.Artist,
+		Album:   track.Album,
+		Artwork: artwork,
+		URL:     pageURL,
+	}
+	for _, platform := range services.StreamingPlatforms {
+		if _, ok := links[platform]; ok {
+			page.Links = append(page.Links, sharePageLink{
+				Name: services.PlatformNames[platform],
+				URL:  pageURL + "/go/" + platform,
+			})
+		}
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := sharePageTemplate.Execute(c.Writer, page); err != nil {
+		log.Printf("Failed to render share page: %v", err)
+	}
+}
+
+// ShareRedirect counts a click and sends the visitor to the track on the chosen platform.
+// The choice is remembered so later share pages can redirect automatically.
+func ShareRedirect(c *gin.Context) {
+	share, track, ok := loadShare(c)
+	if !ok {
+		return
+	}
+	platform := c.Param("platform")
+	link, ok := services.TrackLinks(track, "")[platform]
+	if !ok || !services.IsPlatformURL(platform, link) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Track is not available on this platform"})
+		return
+	}
+
+	if err := services.RecordShareClick(share.ID, platform); err != nil {
+		log.Printf("Failed to record share click: %v", err)
+	}
+	c.SetCookie(shareCookie, platform, 365*24*60*60, "/s", "", false, true)
+	c.Redirect(http.StatusFound, link)
+}
+
+// GetUserShares lists the user's share links with view and click totals
+func GetUserShares(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var shares []db.Share
+	if err := db.DB.Where("user_id = ? AND code <> ''", userID).Order("created_at DESC").Find(&shares).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch shares"})
+		return
+	}
+
+	result := []gin.H{}
+	for _, s := range shares {
+		var track db.Track
+		db.DB.Select("title", "artist").Where("id = ?", s.TrackID).First(&track)
+		var clicks int64
+		db.DB.Model(&db.ShareClick{}).Where("share_id = ?", s.ID).Count(&clicks)
+		result = append(result, gin.H{
+			"code":       s.Code,
+			"url":        s.CustomURL,
+			"track_id":   s.TrackID,
+			"title":      track.Title,
+			"artist":     track.Artist,
+			"views":      s.Views,
+			"clicks":     clicks,
+			"created_at": s.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"shares": result})
+}
+
+// GetShareStats returns views and per-platform clicks for one of the user's shares
+func GetShareStats(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var share db.Share
+	if err := db.DB.Where("code = ?", c.Param("code")).First(&share).Error; err != nil || share.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share not found"})
+		return
+	}
+
+	clicks, err := services.ShareClickCounts(share.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch share stats", "details": err.Error()})
+		return
+	}
+	var total int64
+	for _, n := range clicks {
+		total += n
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":         share.Code,
+		"url":          share.CustomURL,
+		"views":        share.Views,
+		"clicks":       clicks,
+		"total_clicks": total,
+	})
+}
+
+// ResolveTrack takes a Spotify, YouTube, Apple Music or Deezer track link (?url=) and returns
+// the song with links for every platform it was found on. No account is needed.
+func ResolveTrack(c *gin.Context) {
+	link := c.Query("url")
+	if link == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing url parameter", "code": services.PlaylistURLMissing})
+		return
+	}
+
+	result, err := services.ResolveTrackURL(c.Request.Context(), link)
+	if err != nil {
+		var urlErr *services.PlaylistURLError
+		if errors.As(err, &urlErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": urlErr.Message, "code": urlErr.Code})
+			return
+		}
+		if errors.Is(err, services.ErrResolvedTrackNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Track not found", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to resolve track", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"title":       result.Track.Title,
+		"artist":      result.Track.Artist,
+		"album":       result.Track.Album,
+		"isrc":        result.Track.ISRC,
+		"duration_ms": result.Track.DurationMs,
+		"artwork":     result.Track.ArtworkURL,
+		"links":       result.Links,
+		"cached":      result.Cached,
+	})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import "html/template"
+
+type sharePageLink struct {
+	Name string
+	URL  string
+}
+
+type sharePage struct {
+	Title   string
+	Artist  string
+	Album   string
+	Artwork string
+	URL     string
+	Links   []sharePageLink
+}
+
+// sharePageTemplate is the public landing page of a share link. The OpenGraph and Twitter
+// tags give link previews in chat apps and social networks.
+var sharePageTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}{{if .Artist}} by {{.Artist}}{{end}} · EchoBridge</title>
+<meta property="og:type" content="music.song">
+<meta property="og:site_name" content="EchoBridge">
+<meta property="og:title" content="{{.Title}}{{if .Artist}} – {{.Artist}}{{end}}">
+<meta property="og:description" content="Listen on {{range $i, $l := .Links}}{{if $i}}, {{end}}{{$l.Name}}{{end}}">
+<meta property="og:url" content="{{.URL}}">
+{{if .Artwork}}<meta property="og:image" content="{{.Artwork}}">{{end}}
+<meta name="twitter:card" content="{{if .Artwork}}summary_large_image{{else}}summary{{end}}">
+<style>
+body{font-family:system-ui,sans-serif;background:#111;color:#eee;display:flex;justify-content:center;padding:2rem}
+main{max-width:360px;width:100%;text-align:center}
+img{width:100%;border-radius:12px}
+h1{font-size:1.4rem;margin:1rem 0 .25rem}
+p{color:#aaa;margin:0 0 1.5rem}
+a{display:block;padding:.8rem;margin:.5rem 0;border-radius:8px;background:#222;color:#eee;text-decoration:none}
+a:hover{background:#333}
+</style>
+</head>
+<body>
+<main>
+{{if .Artwork}}<img src="{{.Artwork}}" alt="">{{end}}
+<h1>{{.Title}}</h1>
+<p>{{.Artist}}{{if .Album}} · {{.Album}}{{end}}</p>
+{{range .Links}}<a href="{{.URL}}">{{.Name}}</a>
+{{else}}<p>This track is not available on any streaming platform yet.</p>
+{{end}}
+</main>
+</body>
+</html>
+`))
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"EchoBridge/db"
+	"EchoBridge/internal/services"
 	"EchoBridge/internal/worker"
 
 	"github.com/google/uuid"
@@ -50,4 +51,24 @@ func enqueueUncategorized(pool *worker.WorkerPool) {
 			PlaylistID: p.ID,
 		})
 	}
-}
\ No newline at end of file
+}
+
+// StartPlaylistEventPruning deletes playlist view and import events once they are too old to
+// count in rankings, every hour
+func StartPlaylistEventPruning() {
+	prune := func() {
+		if n, err := services.PrunePlaylistEvents(); err != nil {
+			log.Printf("Scheduler: %v", err)
+		} else if n > 0 {
+			log.Printf("Scheduler: Pruned %d playlist events", n)
+		}
+	}
+	go prune()
+
+	ticker := time.NewTicker(1 * time.Hour)
+	go func() {
+		for range ticker.C {
+			prune()
+		}
+	}()
+}
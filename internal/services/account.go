@@ -0,0 +1,415 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// --- ACCOUNT BACKUP AND RESTORE ---
+
+// AccountArchiveVersion is bumped when the archive layout changes
+const AccountArchiveVersion = 1
+
+// MaxAccountArchiveSize bounds uploaded account archives
+const MaxAccountArchiveSize = 50 << 20
+
+// accountArchiveManifest is the name of the JSON document inside the zip.
+// Per-playlist JSPF files under playlists/ are for other tools; restore only reads the manifest.
+const accountArchiveManifest = "account.json"
+
+// AccountArchive is everything EchoBridge stores for a user, minus credentials.
+// Platform tokens are never exported; accounts are re-linked after a restore.
+type AccountArchive struct {
+	Version         int                    `json:"version"`
+	ExportedAt      time.Time              `json:"exported_at"`
+	User            AccountExport          `json:"user"`
+	Playlists       []PlaylistExport       `json:"playlists"`
+	Shares          []ShareExport          `json:"shares"`
+	SyncJobs        []SyncJobExport        `json:"sync_jobs"`
+	SyncCheckpoints []SyncCheckpointExport `json:"sync_checkpoints"`
+}
+
+type AccountExport struct {
+	ID                   string    `json:"id"`
+	Email                string    `json:"email"`
+	Username             string    `json:"username"`
+	DisplayName          string    `json:"display_name"`
+	AvatarURL            string    `json:"avatar_url"`
+	Bio                  string    `json:"bio"`
+	ProfileHidden        bool      `json:"profile_hidden"`
+	AuthType             string    `json:"auth_type"`
+	AppleMusicStorefront string    `json:"applemusic_storefront"`
+	SubsonicURL          string    `json:"subsonic_url"`
+	JellyfinURL          string    `json:"jellyfin_url"`
+	LastFMUsername       string    `json:"lastfm_username"`
+	ListenBrainzUsername string    `json:"listenbrainz_username"`
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+type ShareExport struct {
+	ID        string    `json:"id"`
+	TrackID   string    `json:"track_id"`
+	Code      string    `json:"code"`
+	CustomURL string    `json:"custom_url"`
+	Views     int       `json:"views"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SyncJobExport struct {
+	ID          string     `json:"id"`
+	PlaylistID  string     `json:"playlist_id"`
+	Platforms   string     `json:"platforms"`
+	Status      string     `json:"status"`
+	Runner      string     `json:"runner"`
+	Result      string     `json:"result"` // platform -> destination playlist ID
+	ErrorMsg    string     `json:"error_msg"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// SyncCheckpointExport maps one track to its ID on a destination platform
+type SyncCheckpointExport struct {
+	JobID      string `json:"job_id"`
+	Platform   string `json:"platform"`
+	TrackID    string `json:"track_id"`
+	Status     string `json:"status"`
+	ExternalID string `json:"external_id"`
+}
+
+// AccountRestoreSummary counts what a restore created
+type AccountRestoreSummary struct {
+	Playlists       int `json:"playlists"`
+	Tracks          int `json:"tracks"`
+	Shares          int `json:"shares"`
+	SyncJobs        int `json:"sync_jobs"`
+	SyncCheckpoints int `json:"sync_checkpoints"`
+}
+
+// BuildAccountArchive collects a user's data from the DB
+func BuildAccountArchive(user db.User) (AccountArchive, error) {
+	archive := AccountArchive{
+		Version:    AccountArchiveVersion,
+		ExportedAt: time.Now().UTC(),
+		User: AccountExport{
+			ID:                   user.ID.String(),
+			Email:                user.Email,
+			Username:             user.Username,
+			DisplayName:          user.DisplayName,
+			AvatarURL:            user.AvatarURL,
+			Bio:                  user.Bio,
+			ProfileHidden:        user.ProfileHidden,
+			AuthType:             user.AuthType,
+			AppleMusicStorefront: user.AppleMusicStorefront,
+			SubsonicURL:          user.SubsonicURL,
+			JellyfinURL:          user.JellyfinURL,
+			LastFMUsername:       user.LastFMUsername,
+			ListenBrainzUsername: user.ListenBrainzUsername,
+			CreatedAt:            user.CreatedAt,
+		},
+		Playlists:       []PlaylistExport{},
+		Shares:          []ShareExport{},
+		SyncJobs:        []SyncJobExport{},
+		SyncCheckpoints: []SyncCheckpointExport{},
+	}
+
+	var playlists []db.Playlist
+	if err := db.DB.Where("owner_id = ?", user.ID).Order("created_at").Find(&playlists).Error; err != nil {
+		return archive, fmt.Errorf("failed to fetch playlists: %w", err)
+	}
+	for _, p := range playlists {
+		var tracks []db.Track
+		if err := db.DB.Where("playlist_id = ?", p.ID).Order(TrackOrder).Find(&tracks).Error; err != nil {
+			return archive, fmt.Errorf("failed to fetch tracks: %w", err)
+		}
+		archive.Playlists = append(archive.Playlists, BuildPlaylistExport(p, tracks))
+	}
+
+	var shares []db.Share
+	if err := db.DB.Where("user_id = ?", user.ID).Find(&shares).Error; err != nil {
+		return archive, fmt.Errorf("failed to fetch shares: %w", err)
+	}
+	for _, s := range shares {
+		archive.Shares = append(archive.Shares, ShareExport{
+			ID:        s.ID.String(),
+			TrackID:   s.TrackID.String(),
+			Code:      s.Code,
+			CustomURL: s.CustomURL,
+			Views:     s.Views,
+			CreatedAt: s.CreatedAt,
+		})
+	}
+
+	var jobs []db.SyncJob
+	if err := db.DB.Where("user_id = ?", user.ID).Order("created_at").Find(&jobs).Error; err != nil {
+		return archive, fmt.Errorf("failed to fetch sync jobs: %w", err)
+	}
+	jobIDs := make([]uuid.UUID, 0, len(jobs))
+	for _, j := range jobs {
+		jobIDs = append(jobIDs, j.ID)
+		archive.SyncJobs = append(archive.SyncJobs, SyncJobExport{
+			ID:          j.ID.String(),
+			PlaylistID:  j.PlaylistID.String(),
+			Platforms:   j.Platforms,
+			Status:      j.Status,
+			Runner:      j.Runner,
+			Result:      j.Result,
+			ErrorMsg:    j.ErrorMsg,
+			CreatedAt:   j.CreatedAt,
+			CompletedAt: j.CompletedAt,
+		})
+	}
+
+	if len(jobIDs) > 0 {
+		var checkpoints []db.SyncCheckpoint
+		if err := db.DB.Where("job_id IN ?", jobIDs).Find(&checkpoints).Error; err != nil {
+			return archive, fmt.Errorf("failed to fetch sync checkpoints: %w", err)
+		}
+		for _, cp := range checkpoints {
+			archive.SyncCheckpoints = append(archive.SyncCheckpoints, SyncCheckpointExport{
+				JobID:      cp.JobID.String(),
+				Platform:   cp.Platform,
+				TrackID:    cp.TrackID.String(),
+				Status:     cp.Status,
+				ExternalID: cp.ExternalID,
+			})
+		}
+	}
+	return archive, nil
+}
+
+// WriteAccountArchive writes a zip with account.json and one JSPF file per playlist
+func WriteAccountArchive(w io.Writer, archive AccountArchive) error {
+	zw := zip.NewWriter(w)
+
+	manifest, err := zw.Create(accountArchiveManifest)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(manifest)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(archive); err != nil {
+		return fmt.Errorf("failed to encode account archive: %w", err)
+	}
+
+	for _, p := range archive.Playlists {
+		f, err := zw.Create("playlists/" + p.ID + jspfFileExtension)
+		if err != nil {
+			return err
+		}
+		playlist, tracks := p.toModels()
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(BuildJSPF(playlist, tracks)); err != nil {
+			return fmt.Errorf("failed to encode JSPF: %w", err)
+		}
+	}
+	return zw.Close()
+}
+
+// ReadAccountArchive reads the manifest from an archive written by WriteAccountArchive
+func ReadAccountArchive(r io.ReaderAt, size int64) (AccountArchive, error) {
+	var archive AccountArchive
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return archive, fmt.Errorf("invalid archive: %w", err)
+	}
+	f, err := zr.Open(accountArchiveManifest)
+	if err != nil {
+		return archive, fmt.Errorf("archive has no %s", accountArchiveManifest)
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(io.LimitReader(f, MaxAccountArchiveSize)).Decode(&archive); err != nil {
+		return archive, fmt.Errorf("invalid %s: %w", accountArchiveManifest, err)
+	}
+	if archive.Version < 1 || archive.Version > AccountArchiveVersion {
+		return archive, fmt.Errorf("unsupported archive version %d", archive.Version)
+	}
+	return archive, nil
+}
+
+// RestoreAccountArchive recreates an archive's data under userID. Every playlist, track, share
+// and sync job gets a new ID, and references between them are remapped. Jobs that were still
+// running at export time are restored as "interrupted" so they can be resumed. Shares keep
+// their short code where possible, with links rebuilt on shareBaseURL.
+func RestoreAccountArchive(userID uuid.UUID, archive AccountArchive, shareBaseURL string) (AccountRestoreSummary, error) {
+	var summary AccountRestoreSummary
+	playlistIDs := make(map[string]uuid.UUID)
+	trackIDs := make(map[string]uuid.UUID)
+	jobIDs := make(map[string]uuid.UUID)
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		for _, p := range archive.Playlists {
+			playlist, tracks := p.toModels()
+			playlist.ID = uuid.New()
+			playlist.OwnerID = userID
+			playlistIDs[p.ID] = playlist.ID
+			if err := tx.Create(&playlist).Error; err != nil {
+				return fmt.Errorf("failed to restore playlist %q: %w", p.Title, err)
+			}
+			summary.Playlists++
+
+			for i, t := range tracks {
+				t.ID = uuid.New()
+				t.PlaylistID = playlist.ID
+				trackIDs[p.Tracks[i].ID] = t.ID
+				if err := tx.Create(&t).Error; err != nil {
+					return fmt.Errorf("failed to restore track %q: %w", t.Title, err)
+				}
+				summary.Tracks++
+			}
+			if err := SnapshotPlaylist(tx, playlist.ID, userID, VersionTriggerImport, "account_restored"); err != nil {
+				return err
+			}
+		}
+
+		for _, s := range archive.Shares {
+			trackID, ok := trackIDs[s.TrackID]
+			if !ok {
+				continue
+			}
+			code, err := restoreShareCode(tx, s)
+			if err != nil {
+				return err
+			}
+			share := db.Share{
+				ID:        uuid.New(),
+				TrackID:   trackID,
+				UserID:    userID,
+				Code:      code,
+				CustomURL: shareBaseURL + "/s/" + code,
+				Views:     s.Views,
+				CreatedAt: s.CreatedAt,
+			}
+			if err := tx.Create(&share).Error; err != nil {
+				return fmt.Errorf("failed to restore share: %w", err)
+			}
+			summary.Shares++
+		}
+
+		for _, j := range archive.SyncJobs {
+			playlistID, ok := playlistIDs[j.PlaylistID]
+			if !ok {
+				continue
+			}
+			status := j.Status
+			if status == "pending" || status == "processing" {
+				status = "interrupted"
+			}
+			job := db.SyncJob{
+				ID:          uuid.New(),
+				UserID:      userID,
+				PlaylistID:  playlistID,
+				Platforms:   j.Platforms,
+				Status:      status,
+				Runner:      j.Runner,
+				Result:      j.Result,
+				ErrorMsg:    j.ErrorMsg,
+				CreatedAt:   j.CreatedAt,
+				CompletedAt: j.CompletedAt,
+			}
+			jobIDs[j.ID] = job.ID
+			if err := tx.Create(&job).Error; err != nil {
+				return fmt.Errorf("failed to restore sync job: %w", err)
+			}
+			summary.SyncJobs++
+		}
+
+		for _, cp := range archive.SyncCheckpoints {
+			jobID, okJob := jobIDs[cp.JobID]
+			trackID, okTrack := trackIDs[cp.TrackID]
+			if !okJob || !okTrack {
+				continue
+			}
+			checkpoint := db.SyncCheckpoint{
+				ID:         uuid.New(),
+				JobID:      jobID,
+				Platform:   cp.Platform,
+				TrackID:    trackID,
+				Status:     cp.Status,
+				ExternalID: cp.ExternalID,
+			}
+			if err := tx.Create(&checkpoint).Error; err != nil {
+				return fmt.Errorf("failed to restore sync checkpoint: %w", err)
+			}
+			summary.SyncCheckpoints++
+		}
+		return nil
+	})
+	if err != nil {
+		return AccountRestoreSummary{}, err
+	}
+	return summary, nil
+}
+
+// restoreShareCode returns the short code for a restored share. The exported code is kept so
+// old links keep working after moving an account, unless another share already uses it.
+// Archives from before codes were exported carry the code only at the end of the URL.
+func restoreShareCode(tx *gorm.DB, s ShareExport) (string, error) {
+	code := s.Code
+	if i := strings.LastIndex(s.CustomURL, "/s/"); code == "" && i >= 0 {
+		code = s.CustomURL[i+len("/s/"):]
+	}
+	if code != "" {
+		var count int64
+		if err := tx.Model(&db.Share{}).Where("code = ?", code).Count(&count).Error; err != nil {
+			return "", fmt.Errorf("failed to check share code: %w", err)
+		}
+		if count == 0 {
+			return code, nil
+		}
+	}
+	return NewShareCode()
+}
+
+// toModels converts an exported playlist back to DB models, keeping the exported IDs
+func (p PlaylistExport) toModels() (db.Playlist, []db.Track) {
+	id, _ := uuid.Parse(p.ID)
+	playlist := db.Playlist{
+		ID:          id,
+		Title:       p.Title,
+		Description: p.Description,
+		Platform:    p.Platform,
+		SourceID:    p.SourceID,
+		IsPublic:    p.IsPublic,
+		CoverImage:  p.CoverImage,
+		Category:    p.Category,
+		CreatedAt:   p.CreatedAt,
+	}
+	tracks := make([]db.Track, 0, len(p.Tracks))
+	for i, t := range p.Tracks {
+		trackID, _ := uuid.Parse(t.ID)
+		tracks = append(tracks, db.Track{
+			ID:            trackID,
+			PlaylistID:    id,
+			Title:         t.Title,
+			Artist:        t.Artist,
+			Album:         t.Album,
+			DurationMs:    t.DurationMs,
+			ISRC:          t.ISRC,
+			MusicBrainzID: t.MusicBrainzID,
+			SpotifyID:     t.SpotifyID,
+			YouTubeID:     t.YouTubeID,
+			AppleMusicID:  t.AppleMusicID,
+			DeezerID:      t.DeezerID,
+			TidalID:       t.TidalID,
+			SoundCloudID:  t.SoundCloudID,
+			SoundCloudURL: t.SoundCloudURL,
+			SubsonicID:    t.SubsonicID,
+			JellyfinID:    t.JellyfinID,
+			PreviewURL:    t.PreviewURL,
+			Position:      i + 1,
+			CreatedAt:     t.CreatedAt,
+		})
+	}
+	return playlist, tracks
+}
@@ -1,112 +1,535 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"EchoBridge/db"
+
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
-	"golang.org/x/oauth2"
 )
 
+// --- CONFIGURATION ---
+
+var appleMusicAPIURL = "https://api.music.apple.com"
+
+var (
+	appleMusicTokenMu     sync.Mutex
+	appleMusicDevToken    string
+	appleMusicDevTokenExp time.Time
+)
+
+// AppleMusicClient calls the Apple Music API with a developer token and a Music-User-Token
 type AppleMusicClient struct {
-	httpClient *http.Client
+	httpClient     *http.Client
+	developerToken string
+	userToken      string
+	Storefront     string
 }
 
-var appleMusicOAuthConfig = &oauth2.Config{
-	ClientID:     os.Getenv("APPLE_MUSIC_CLIENT_ID"),
-	ClientSecret: os.Getenv("APPLE_MUSIC_SECRET"),
-	RedirectURL:  "http://127.0.0.1:8000/callback/applemusic",
-	Scopes:       []string{"https://api.music.apple.com/v1/me/library"},
-	Endpoint: oauth2.Endpoint{
-		AuthURL:  "https://appleid.apple.com/auth/authorize",
-		TokenURL: "https://appleid.apple.com/auth/token",
-	},
+// GetAppleMusicDeveloperToken returns a cached ES256 developer token signed with the .p8 key
+func GetAppleMusicDeveloperToken() (string, error) {
+	appleMusicTokenMu.Lock()
+	defer appleMusicTokenMu.Unlock()
+
+	if appleMusicDevToken != "" && time.Now().Add(time.Hour).Before(appleMusicDevTokenExp) {
+		return appleMusicDevToken, nil
+	}
+
+	teamID := os.Getenv("APPLE_MUSIC_TEAM_ID")
+	keyID := os.Getenv("APPLE_MUSIC_KEY_ID")
+	if teamID == "" || keyID == "" {
+		return "", fmt.Errorf("APPLE_MUSIC_TEAM_ID or APPLE_MUSIC_KEY_ID not set")
+	}
+
+	keyPEM := []byte(os.Getenv("APPLE_MUSIC_PRIVATE_KEY"))
+	if len(keyPEM) == 0 {
+		keyPath := os.Getenv("APPLE_MUSIC_PRIVATE_KEY_PATH")
+		if keyPath == "" {
+			return "", fmt.Errorf("APPLE_MUSIC_PRIVATE_KEY or APPLE_MUSIC_PRIVATE_KEY_PATH not set")
+		}
+		var err error
+		keyPEM, err = os.ReadFile(keyPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read Apple Music private key: %w", err)
+		}
+	}
+
+	key, err := jwt.ParseECPrivateKeyFromPEM(keyPEM)
+	if err != nil {
+		return "", fmt.Errorf("invalid Apple Music private key: %w", err)
+	}
+
+	now := time.Now()
+	exp := now.Add(24 * time.Hour)
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": teamID,
+		"iat": now.Unix(),
+		"exp": exp.Unix(),
+	})
+	token.Header["kid"] = keyID
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign Apple Music developer token: %w", err)
+	}
+	appleMusicDevToken = signed
+	appleMusicDevTokenExp = exp
+	return signed, nil
 }
 
-// GetAppleMusicClient returns an Apple Music client
+// --- APPLE MUSIC FUNCTIONS ---
+
+// GetAppleMusicClient returns an Apple Music client for a user
 func GetAppleMusicClient(ctx context.Context, user db.User) (*AppleMusicClient, error) {
 	if user.AppleMusicToken == "" {
 		return nil, fmt.Errorf("no Apple Music token available")
 	}
-	var token oauth2.Token
-	if err := json.Unmarshal([]byte(user.AppleMusicToken), &token); err != nil {
-		return nil, fmt.Errorf("invalid Apple Music token: %w", err)
+	developerToken, err := GetAppleMusicDeveloperToken()
+	if err != nil {
+		return nil, err
+	}
+	storefront := user.AppleMusicStorefront
+	if storefront == "" {
+		storefront = defaultAppleMusicStorefront()
 	}
-	return &AppleMusicClient{httpClient: appleMusicOAuthConfig.Client(ctx, &token)}, nil
+	return NewAppleMusicClient(developerToken, user.AppleMusicToken, storefront), nil
 }
 
-// GetAppleMusicPlaylists retrieves user playlists
-func GetAppleMusicPlaylists(ctx context.Context, client *AppleMusicClient) ([]db.Playlist, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.music.apple.com/v1/me/library/playlists", nil)
+// NewAppleMusicClient builds a client from raw tokens (used while linking, before the user is saved)
+func NewAppleMusicClient(developerToken, userToken, storefront string) *AppleMusicClient {
+	return &AppleMusicClient{
+		httpClient:     &http.Client{Timeout: 30 * time.Second},
+		developerToken: developerToken,
+		userToken:      userToken,
+		Storefront:     storefront,
+	}
+}
+
+// GetAppleMusicCatalogClient returns a client with only the developer token, which can read
+// and search the catalog but not a user's library
+func GetAppleMusicCatalogClient() (*AppleMusicClient, error) {
+	developerToken, err := GetAppleMusicDeveloperToken()
+	if err != nil {
+		return nil, err
+	}
+	return NewAppleMusicClient(developerToken, "", defaultAppleMusicStorefront()), nil
+}
+
+func defaultAppleMusicStorefront() string {
+	if sf := os.Getenv("APPLE_MUSIC_STOREFRONT"); sf != "" {
+		return sf
+	}
+	return "us"
+}
+
+// do sends an authenticated request. Non-2xx responses are returned as errors that
+// include the status code so callers can detect 429s.
+func (c *AppleMusicClient) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode Apple Music request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, appleMusicAPIURL+path, reader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Apple Music request: %w", err)
+		return fmt.Errorf("failed to create Apple Music request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.developerToken)
+	if c.userToken != "" {
+		req.Header.Set("Music-User-Token", c.userToken)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
-	resp, err := client.httpClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch Apple Music playlists: %w", err)
+		return fmt.Errorf("Apple Music request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Apple Music API returned %d: %s", resp.StatusCode, string(respBody))
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode Apple Music response: %w", err)
+	}
+	return nil
+}
+
+// GetAppleMusicStorefront returns the storefront (country) of the user's account
+func GetAppleMusicStorefront(ctx context.Context, client *AppleMusicClient) (string, error) {
+	var result struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := client.do(ctx, "GET", "/v1/me/storefront", nil, &result); err != nil {
+		return "", fmt.Errorf("failed to get Apple Music storefront: %w", err)
+	}
+	if len(result.Data) == 0 {
+		return "", fmt.Errorf("no Apple Music storefront returned")
+	}
+	return result.Data[0].ID, nil
+}
+
+// GetAppleMusicPlaylists retrieves user library playlists
+func GetAppleMusicPlaylists(ctx context.Context, client *AppleMusicClient) ([]db.Playlist, error) {
+	var playlists []db.Playlist
+	next := "/v1/me/library/playlists?limit=100"
+
+	for next != "" {
+		var result struct {
+			Next string `json:"next"`
+			Data []struct {
+				ID         string `json:"id"`
+				Attributes struct {
+					Name        string `json:"name"`
+					Description struct {
+						Standard string `json:"standard"`
+					} `json:"description"`
+					Artwork *struct {
+						URL string `json:"url"`
+					} `json:"artwork"`
+				} `json:"attributes"`
+			} `json:"data"`
+		}
+		if err := client.do(ctx, "GET", next, nil, &result); err != nil {
+			return nil, fmt.Errorf("failed to fetch Apple Music playlists: %w", err)
+		}
+
+		for _, p := range result.Data {
+			var coverImage string
+			if p.Attributes.Artwork != nil {
+				coverImage = appleMusicArtworkURL(p.Attributes.Artwork.URL)
+			}
+			playlists = append(playlists, db.Playlist{
+				ID:          uuid.New(),
+				Title:       p.Attributes.Name,
+				Description: p.Attributes.Description.Standard,
+				Platform:    "applemusic",
+				SourceID:    p.ID,
+				IsPublic:    false,
+				CoverImage:  coverImage,
+				CreatedAt:   time.Now(),
+			})
+		}
+		next = result.Next
+	}
+	return playlists, nil
+}
+
+// GetAppleMusicPlaylist retrieves a single library playlist's metadata
+func GetAppleMusicPlaylist(ctx context.Context, client *AppleMusicClient, playlistID string) (db.Playlist, error) {
 	var result struct {
 		Data []struct {
 			ID         string `json:"id"`
 			Attributes struct {
 				Name        string `json:"name"`
-				Description string `json:"description"`
+				Description struct {
+					Standard string `json:"standard"`
+				} `json:"description"`
+				Artwork *struct {
+					URL string `json:"url"`
+				} `json:"artwork"`
 			} `json:"attributes"`
 		} `json:"data"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode Apple Music response: %w", err)
+	if err := client.do(ctx, "GET", appleMusicPlaylistPath(client, playlistID), nil, &result); err != nil {
+		return db.Playlist{}, fmt.Errorf("failed to fetch Apple Music playlist: %w", err)
+	}
+	if len(result.Data) == 0 {
+		return db.Playlist{}, fmt.Errorf("Apple Music playlist %s not found", playlistID)
 	}
 
-	var playlists []db.Playlist
-	for _, p := range result.Data {
-		playlists = append(playlists, db.Playlist{
-			ID:          uuid.New(),
-			Title:       p.Attributes.Name,
-			Description: p.Attributes.Description,
-			Platform:    "applemusic",
-			SourceID:    p.ID,
-			IsPublic:    false,
-			CreatedAt:   time.Now(),
-		})
+	p := result.Data[0]
+	var coverImage string
+	if p.Attributes.Artwork != nil {
+		coverImage = appleMusicArtworkURL(p.Attributes.Artwork.URL)
 	}
-	return playlists, nil
+	return db.Playlist{
+		ID:          uuid.New(),
+		Title:       p.Attributes.Name,
+		Description: p.Attributes.Description.Standard,
+		Platform:    "applemusic",
+		SourceID:    p.ID,
+		IsPublic:    false,
+		CoverImage:  coverImage,
+		CreatedAt:   time.Now(),
+	}, nil
 }
 
-// SearchAppleMusicTrack searches for a track
-func SearchAppleMusicTrack(ctx context.Context, client *AppleMusicClient, title, artist string) (string, error) {
-	url := fmt.Sprintf("https://api.music.apple.com/v1/catalog/us/songs?filter[term]=%s+%s", url.QueryEscape(title), url.QueryEscape(artist))
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create Apple Music search request: %w", err)
+// appleMusicPlaylistPath returns the API path of a playlist. Catalog playlists, as found in
+// public music.apple.com links, have "pl." IDs; library playlists have "p." IDs.
+func appleMusicPlaylistPath(client *AppleMusicClient, playlistID string) string {
+	if strings.HasPrefix(playlistID, "pl.") {
+		return fmt.Sprintf("/v1/catalog/%s/playlists/%s", url.PathEscape(client.Storefront), url.PathEscape(playlistID))
 	}
+	return "/v1/me/library/playlists/" + url.PathEscape(playlistID)
+}
 
-	resp, err := client.httpClient.Do(req)
-	if err != nil {
+// GetAppleMusicPlaylistTracks retrieves tracks from a library or catalog playlist.
+// AppleMusicID is always a catalog song ID, so it can be reused across storefronts and in links.
+func GetAppleMusicPlaylistTracks(ctx context.Context, client *AppleMusicClient, playlistID string) ([]db.Track, error) {
+	var tracks []db.Track
+	next := appleMusicPlaylistPath(client, playlistID) + "/tracks?limit=100"
+
+	for next != "" {
+		var result struct {
+			Next string `json:"next"`
+			Data []struct {
+				ID         string `json:"id"`
+				Attributes struct {
+					Name       string `json:"name"`
+					ArtistName string `json:"artistName"`
+					AlbumName  string `json:"albumName"`
+					DurationMs int    `json:"durationInMillis"`
+					PlayParams struct {
+						CatalogID string `json:"catalogId"`
+					} `json:"playParams"`
+					Previews []struct {
+						URL string `json:"url"`
+					} `json:"previews"`
+				} `json:"attributes"`
+			} `json:"data"`
+		}
+		if err := client.do(ctx, "GET", next, nil, &result); err != nil {
+			return nil, fmt.Errorf("failed to retrieve Apple Music playlist tracks: %w", err)
+		}
+
+		for _, item := range result.Data {
+			// Catalog playlists list catalog songs. Library items ("i." IDs) without a catalog
+			// match, e.g. uploads, have no catalog ID and are left for sync to find by search.
+			appleMusicID := item.Attributes.PlayParams.CatalogID
+			if appleMusicID == "" && !strings.HasPrefix(item.ID, "i.") {
+				appleMusicID = item.ID
+			}
+			var previewURL string
+			if len(item.Attributes.Previews) > 0 {
+				previewURL = item.Attributes.Previews[0].URL
+			}
+			tracks = append(tracks, db.Track{
+				ID:           uuid.New(),
+				Title:        item.Attributes.Name,
+				Artist:       item.Attributes.ArtistName,
+				Album:        item.Attributes.AlbumName,
+				AppleMusicID: appleMusicID,
+				DurationMs:   item.Attributes.DurationMs,
+				PreviewURL:   previewURL,
+				CreatedAt:    time.Now(),
+			})
+		}
+		next = result.Next
+	}
+	return tracks, nil
+}
+
+// SearchAppleMusicTrack searches the catalog in the client's storefront and returns a song ID
+func SearchAppleMusicTrack(ctx context.Context, client *AppleMusicClient, title, artist string) (string, error) {
+	query := url.Values{}
+	query.Set("term", fmt.Sprintf("%s %s", title, artist))
+	query.Set("types", "songs")
+	query.Set("limit", "1")
+	path := fmt.Sprintf("/v1/catalog/%s/search?%s", url.PathEscape(client.Storefront), query.Encode())
+
+	var result struct {
+		Results struct {
+			Songs struct {
+				Data []struct {
+					ID string `json:"id"`
+				} `json:"data"`
+			} `json:"songs"`
+		} `json:"results"`
+	}
+	if err := client.do(ctx, "GET", path, nil, &result); err != nil {
 		return "", fmt.Errorf("failed to search Apple Music track: %w", err)
 	}
-	defer resp.Body.Close()
 
+	if len(result.Results.Songs.Data) > 0 {
+		return result.Results.Songs.Data[0].ID, nil
+	}
+	return "", nil
+}
+
+// CreateAppleMusicPlaylist creates a new library playlist
+func CreateAppleMusicPlaylist(ctx context.Context, client *AppleMusicClient, title, description string) (string, error) {
+	body := map[string]interface{}{
+		"attributes": map[string]string{
+			"name":        title,
+			"description": description,
+		},
+	}
 	var result struct {
 		Data []struct {
 			ID string `json:"id"`
 		} `json:"data"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to decode Apple Music search response: %w", err)
+	if err := client.do(ctx, "POST", "/v1/me/library/playlists", body, &result); err != nil {
+		return "", fmt.Errorf("failed to create Apple Music playlist: %w", err)
+	}
+	if len(result.Data) == 0 {
+		return "", fmt.Errorf("Apple Music did not return the new playlist")
 	}
+	return result.Data[0].ID, nil
+}
 
-	if len(result.Data) > 0 {
-		return result.Data[0].ID, nil
+// AddAppleMusicPlaylistTracks adds catalog songs to a library playlist
+func AddAppleMusicPlaylistTracks(ctx context.Context, client *AppleMusicClient, playlistID string, songIDs ...string) error {
+	var data []map[string]string
+	for _, id := range songIDs {
+		data = append(data, map[string]string{"id": id, "type": "songs"})
 	}
-	return "", nil
+	path := fmt.Sprintf("/v1/me/library/playlists/%s/tracks", url.PathEscape(playlistID))
+	if err := client.do(ctx, "POST", path, map[string]interface{}{"data": data}, nil); err != nil {
+		return fmt.Errorf("failed to add tracks to Apple Music playlist: %w", err)
+	}
+	return nil
+}
+
+// ImportToAppleMusic creates an Apple Music playlist and adds tracks
+func ImportToAppleMusic(ctx context.Context, client *AppleMusicClient, playlist db.Playlist, tracks []db.Track) (string, error) {
+	return syncToAppleMusic(ctx, client, playlist, tracks, uuid.Nil)
+}
+
+// syncToAppleMusic adds tracks to the job's Apple Music playlist, creating it on the first run.
+// Tracks without an AppleMusicID are matched through catalog search.
+func syncToAppleMusic(ctx context.Context, client *AppleMusicClient, playlist db.Playlist, tracks []db.Track, jobID uuid.UUID) (string, error) {
+	amPlaylistID := loadSyncDestination(jobID, "applemusic")
+	if amPlaylistID == "" {
+		var err error
+		amPlaylistID, err = CreateAppleMusicPlaylist(ctx, client, playlist.Title, playlist.Description)
+		if err != nil {
+			return "", err
+		}
+		SaveSyncDestination(jobID, "applemusic", amPlaylistID)
+	}
+
+	checkpoints, err := loadSyncCheckpoints(jobID, "applemusic")
+	if err != nil {
+		return amPlaylistID, err
+	}
+
+	successCount := 0
+	for _, track := range tracks {
+		if checkpoints.isDone(track.ID) {
+			continue
+		}
+		songID := track.AppleMusicID
+		if songID == "" {
+			songID, err = SearchAppleMusicTrack(ctx, client, track.Title, track.Artist)
+			if err != nil {
+				log.Printf("   ⚠️ Apple Music search failed for %s - %s: %v", track.Title, track.Artist, err)
+				checkpoints.record(track.ID, "failed", "")
+				continue
+			}
+			if songID == "" {
+				log.Printf("   ⚠️ Not found on Apple Music: %s - %s", track.Title, track.Artist)
+				checkpoints.record(track.ID, "not_found", "")
+				continue
+			}
+		}
+		if err := AddAppleMusicPlaylistTracks(ctx, client, amPlaylistID, songID); err != nil {
+			log.Printf("   ❌ Failed to add track to Apple Music: %s: %v", track.Title, err)
+			checkpoints.record(track.ID, "failed", songID)
+			continue
+		}
+		checkpoints.record(track.ID, "added", songID)
+		successCount++
+	}
+	log.Printf("🏁 Apple Music Sync Finished. Added %d/%d songs.", successCount, len(tracks))
+	return amPlaylistID, nil
+}
+
+// ImportAppleMusicPlaylist imports a single Apple Music library playlist to DB
+func ImportAppleMusicPlaylist(ctx context.Context, client *AppleMusicClient, user db.User, sourceID string) error {
+	playlist, err := GetAppleMusicPlaylist(ctx, client, sourceID)
+	if err != nil {
+		return err
+	}
+	playlist.OwnerID = user.ID
+
+	var existing db.Playlist
+	if err := db.DB.Where("owner_id = ? AND source_id = ? AND platform = 'applemusic'", user.ID, sourceID).First(&existing).Error; err == nil {
+		playlist = existing
+	} else {
+		if err := db.DB.Create(&playlist).Error; err != nil {
+			return fmt.Errorf("failed to create playlist in DB: %w", err)
+		}
+	}
+
+	tracks, err := GetAppleMusicPlaylistTracks(ctx, client, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch tracks: %w", err)
+	}
+
+	for _, t := range tracks {
+		t.PlaylistID = playlist.ID
+		db.DB.Create(&t)
+	}
+	RecordImportVersion(playlist.ID, user.ID)
+	return nil
+}
+
+// ImportAllAppleMusicPlaylists imports all library playlists and tracks for a user
+func ImportAllAppleMusicPlaylists(ctx context.Context, user db.User) error {
+	client, err := GetAppleMusicClient(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	playlists, err := GetAppleMusicPlaylists(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range playlists {
+		p.OwnerID = user.ID
+		var existing db.Playlist
+		if err := db.DB.Where("owner_id = ? AND source_id = ? AND platform = 'applemusic'", user.ID, p.SourceID).First(&existing).Error; err == nil {
+			continue
+		}
+
+		if err := db.DB.Create(&p).Error; err != nil {
+			log.Printf("Failed to create playlist %s: %v", p.Title, err)
+			continue
+		}
+
+		tracks, err := GetAppleMusicPlaylistTracks(ctx, client, p.SourceID)
+		if err != nil {
+			log.Printf("Failed to get tracks for %s: %v", p.Title, err)
+			continue
+		}
+
+		for _, t := range tracks {
+			t.PlaylistID = p.ID
+			db.DB.Create(&t)
+		}
+		RecordImportVersion(p.ID, user.ID)
+	}
+	return nil
+}
+
+// appleMusicArtworkURL fills in the {w}x{h} template Apple returns for artwork
+func appleMusicArtworkURL(template string) string {
+	return strings.NewReplacer("{w}", "300", "{h}", "300").Replace(template)
 }
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+	"github.com/zmb3/spotify/v2"
+	"gorm.io/gorm"
+)
+
+// --- DUPLICATE DETECTION ---
+
+// Ways a track can duplicate an earlier one, strongest first
+const (
+	DuplicateExact     = "exact"     // shared platform ID or ISRC
+	DuplicateCanonical = "canonical" // same primary artist and canonical title
+	DuplicateFuzzy     = "fuzzy"     // similar artist and title, and a similar duration
+)
+
+// DuplicateLevels lists the match levels, strongest first
+var DuplicateLevels = []string{DuplicateExact, DuplicateCanonical, DuplicateFuzzy}
+
+const (
+	fuzzyTitleThreshold  = 0.85
+	fuzzyArtistThreshold = 0.8
+	fuzzyDurationSlackMs = 5000
+)
+
+var ErrSourceCleanupUnsupported = errors.New("removing tracks on the source platform is not supported for this playlist")
+
+// DuplicateTrack is a later occurrence of a song and how it matched
+type DuplicateTrack struct {
+	Track      db.Track
+	Match      string
+	Similarity float64 // title similarity for fuzzy matches, 1 otherwise
+}
+
+// DuplicateGroup is a song with the track that is kept and its duplicates
+type DuplicateGroup struct {
+	Keep       db.Track
+	Duplicates []DuplicateTrack
+}
+
+type fuzzyCandidate struct {
+	group  int
+	artist string
+	title  string
+	track  db.Track
+}
+
+// FindDuplicates groups repeated songs in playlist order. The first occurrence is kept and
+// every later one is reported with the strongest level it matched at.
+func FindDuplicates(tracks []db.Track) []DuplicateGroup {
+	var groups []DuplicateGroup
+	exact := make(map[string]int)
+	canonical := make(map[string]int)
+	// Fuzzy candidates are bucketed by the first letter of the title to avoid comparing every pair
+	buckets := make(map[rune][]fuzzyCandidate)
+
+	for _, t := range tracks {
+		group, match, score := -1, "", 1.0
+		for _, key := range exactTrackKeys(t) {
+			if g, ok := exact[key]; ok {
+				group, match = g, DuplicateExact
+				break
+			}
+		}
+		song := CanonicalSongKey(t)
+		if group < 0 && song != "" {
+			if g, ok := canonical[song]; ok {
+				group, match = g, DuplicateCanonical
+			}
+		}
+
+		artist, title := primaryArtist(t.Artist), canonicalTitle(t.Title)
+		var bucket rune
+		if title != "" {
+			bucket = []rune(title)[0]
+		}
+		if group < 0 && title != "" {
+			for _, c := range buckets[bucket] {
+				if s, ok := fuzzyMatch(c, artist, title, t.DurationMs); ok && (match == "" || s > score) {
+					group, match, score = c.group, DuplicateFuzzy, s
+				}
+			}
+		}
+
+		if group < 0 {
+			group = len(groups)
+			groups = append(groups, DuplicateGroup{Keep: t})
+			if title != "" {
+				buckets[bucket] = append(buckets[bucket], fuzzyCandidate{group: group, artist: artist, title: title, track: t})
+			}
+		} else {
+			groups[group].Duplicates = append(groups[group].Duplicates, DuplicateTrack{Track: t, Match: match, Similarity: score})
+		}
+
+		// Later variants can match through any member of the group
+		for _, key := range exactTrackKeys(t) {
+			if _, ok := exact[key]; !ok {
+				exact[key] = group
+			}
+		}
+		if _, ok := canonical[song]; song != "" && !ok {
+			canonical[song] = group
+		}
+	}
+
+	var result []DuplicateGroup
+	for _, g := range groups {
+		if len(g.Duplicates) > 0 {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+// fuzzyMatch compares a track with a kept one; durations only count when both are known
+func fuzzyMatch(c fuzzyCandidate, artist, title string, durationMs int) (float64, bool) {
+	if durationMs > 0 && c.track.DurationMs > 0 {
+		diff := durationMs - c.track.DurationMs
+		if diff < -fuzzyDurationSlackMs || diff > fuzzyDurationSlackMs {
+			return 0, false
+		}
+	}
+	if artist != "" && c.artist != "" && similarity(artist, c.artist) < fuzzyArtistThreshold {
+		return 0, false
+	}
+	s := similarity(title, c.title)
+	return s, s >= fuzzyTitleThreshold
+}
+
+// RemoveDuplicates deletes the detected duplicates matched at one of levels. When trackIDs is
+// not empty, only those duplicates are removed. It returns the removed and remaining tracks.
+func RemoveDuplicates(playlistID, userID uuid.UUID, levels []string, trackIDs []uuid.UUID) ([]db.Track, []db.Track, error) {
+	var removed, remaining []db.Track
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		tracks, err := lockPlaylistTracks(tx, playlistID)
+		if err != nil {
+			return err
+		}
+
+		remove := make(map[uuid.UUID]bool)
+		for _, g := range FindDuplicates(tracks) {
+			for _, d := range g.Duplicates {
+				if slices.Contains(levels, d.Match) && (len(trackIDs) == 0 || slices.Contains(trackIDs, d.Track.ID)) {
+					remove[d.Track.ID] = true
+				}
+			}
+		}
+		if len(remove) == 0 {
+			remaining = tracks
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(remove))
+		for _, t := range tracks {
+			if remove[t.ID] {
+				removed = append(removed, t)
+				ids = append(ids, t.ID)
+			} else {
+				remaining = append(remaining, t)
+			}
+		}
+		if err := tx.Where("id IN ?", ids).Delete(&db.Track{}).Error; err != nil {
+			return fmt.Errorf("failed to remove duplicates: %w", err)
+		}
+		return logTrackChange(tx, playlistID, userID, "duplicates_removed", map[string]interface{}{
+			"removed": len(removed),
+			"levels":  levels,
+		})
+	})
+	return removed, remaining, err
+}
+
+// keepCounts returns, for each platform ID of the removed tracks, how many copies should stay
+// on the source playlist: the number of remaining tracks with that ID. IDs of tracks that were
+// not removed are left out, so songs added on the platform after the import are never touched.
+func keepCounts(removed, remaining []db.Track, id func(db.Track) string) map[string]int {
+	keep := make(map[string]int)
+	for _, t := range removed {
+		if v := id(t); v != "" {
+			keep[v] = 0
+		}
+	}
+	for _, t := range remaining {
+		if _, ok := keep[id(t)]; ok {
+			keep[id(t)]++
+		}
+	}
+	return keep
+}
+
+// RemoveDuplicatesOnSource removes the same duplicates from the platform playlist this playlist
+// was imported from, using the owner's account. Supported for Spotify, YouTube and Deezer.
+// It returns the number of items removed on the platform.
+func RemoveDuplicatesOnSource(ctx context.Context, user db.User, playlist db.Playlist, removed, remaining []db.Track) (int, error) {
+	switch playlist.Platform {
+	case "spotify":
+		client, err := GetSpotifyClient(ctx, user)
+		if err != nil {
+			return 0, err
+		}
+		keep := keepCounts(removed, remaining, func(t db.Track) string { return t.SpotifyID })
+
+		page, err := client.GetPlaylistTracks(ctx, spotify.ID(playlist.SourceID))
+		if err != nil {
+			return 0, fmt.Errorf("failed to read Spotify playlist: %w", err)
+		}
+		var positions []spotify.TrackToRemove
+		seen := make(map[string]int)
+		for position := 0; ; {
+			for _, item := range page.Tracks {
+				id := item.Track.ID.String()
+				if k, ok := keep[id]; ok {
+					seen[id]++
+					if seen[id] > k {
+						positions = append(positions, spotify.NewTrackToRemove(id, []int{position}))
+					}
+				}
+				position++
+			}
+			if err := client.NextPage(ctx, page); err != nil {
+				if errors.Is(err, spotify.ErrNoMorePages) {
+					break
+				}
+				return 0, fmt.Errorf("failed to read Spotify playlist: %w", err)
+			}
+		}
+
+		// Remove from the end so earlier positions stay valid between requests
+		sort.Slice(positions, func(i, j int) bool { return positions[i].Positions[0] > positions[j].Positions[0] })
+		for start := 0; start < len(positions); start += 100 {
+			end := min(start+100, len(positions))
+			if _, err := client.RemoveTracksFromPlaylistOpt(ctx, spotify.ID(playlist.SourceID), positions[start:end], ""); err != nil {
+				return start, fmt.Errorf("failed to remove Spotify tracks: %w", err)
+			}
+		}
+		return len(positions), nil
+
+	case "youtube":
+		client, err := GetYouTubeClient(ctx, user)
+		if err != nil {
+			return 0, err
+		}
+		keep := keepCounts(removed, remaining, func(t db.Track) string { return t.YouTubeID })
+
+		var itemIDs []string
+		seen := make(map[string]int)
+		pageToken := ""
+		for {
+			response, err := client.PlaylistItems.List([]string{"contentDetails"}).PlaylistId(playlist.SourceID).MaxResults(50).PageToken(pageToken).Context(ctx).Do()
+			if err != nil {
+				return 0, fmt.Errorf("failed to read YouTube playlist: %w", err)
+			}
+			for _, item := range response.Items {
+				if item.ContentDetails == nil {
+					continue
+				}
+				id := item.ContentDetails.VideoId
+				if k, ok := keep[id]; ok {
+					seen[id]++
+					if seen[id] > k {
+						itemIDs = append(itemIDs, item.Id)
+					}
+				}
+			}
+			if pageToken = response.NextPageToken; pageToken == "" {
+				break
+			}
+		}
+
+		for i, itemID := range itemIDs {
+			if err := client.PlaylistItems.Delete(itemID).Context(ctx).Do(); err != nil {
+				return i, fmt.Errorf("failed to remove YouTube playlist item: %w", err)
+			}
+		}
+		return len(itemIDs), nil
+
+	case "deezer":
+		client, err := GetDeezerClient(ctx, user)
+		if err != nil {
+			return 0, err
+		}
+		// Deezer playlists hold each track once, so only IDs with nothing left to keep are removed
+		var ids []string
+		for id, k := range keepCounts(removed, remaining, func(t db.Track) string { return t.DeezerID }) {
+			if k == 0 {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			return 0, nil
+		}
+		params := url.Values{}
+		params.Set("songs", strings.Join(ids, ","))
+		if err := client.do(ctx, "DELETE", "/playlist/"+url.PathEscape(playlist.SourceID)+"/tracks", params, nil); err != nil {
+			return 0, fmt.Errorf("failed to remove Deezer tracks: %w", err)
+		}
+		return len(ids), nil
+	}
+	return 0, ErrSourceCleanupUnsupported
+}
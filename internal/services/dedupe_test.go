@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"EchoBridge/db"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	tests := []struct {
+		name   string
+		tracks []db.Track
+		want   []string // per group: "keep<dup:match,...", by track index
+	}{
+		{
+			name: "shared platform ID or ISRC",
+			tracks: []db.Track{
+				{Title: "Song", Artist: "Artist", SpotifyID: "a"},
+				{Title: "Other", Artist: "Band"},
+				{Title: "Song (Live)", Artist: "Someone", SpotifyID: "a"},
+				{Title: "Renamed", Artist: "Artist", ISRC: "usrc1", SpotifyID: "b"},
+				{Title: "Renamed again", Artist: "Artist", ISRC: "USRC1"},
+			},
+			want: []string{"0<2:exact", "3<4:exact"},
+		},
+		{
+			name: "canonical title and primary artist",
+			tracks: []db.Track{
+				{Title: "Song", Artist: "Artist", SpotifyID: "a"},
+				{Title: "Song - 2011 Remaster", Artist: "Artist", SpotifyID: "b"},
+				{Title: "Song (feat. Guest)", Artist: "Artist, Guest", DeezerID: "1"},
+				{Title: "Song", Artist: "Different Artist"},
+			},
+			want: []string{"0<1:canonical,2:canonical"},
+		},
+		{
+			name: "fuzzy title needs a similar duration",
+			tracks: []db.Track{
+				{Title: "Colour of the Night", Artist: "Artist", DurationMs: 200000},
+				{Title: "Color of the Night", Artist: "Artist", DurationMs: 202000},
+				{Title: "Colour of the Nite", Artist: "Artist", DurationMs: 260000},
+				{Title: "Colour of the Knight", Artist: "Other Band", DurationMs: 200000},
+			},
+			want: []string{"0<1:fuzzy"},
+		},
+		{
+			name: "exact wins over canonical",
+			tracks: []db.Track{
+				{Title: "Song", Artist: "Artist", YouTubeID: "v"},
+				{Title: "Song", Artist: "Artist", YouTubeID: "v"},
+			},
+			want: []string{"0<1:exact"},
+		},
+		{
+			name: "variants match through any group member",
+			tracks: []db.Track{
+				{Title: "Song", Artist: "Artist"},
+				{Title: "Song (Radio Edit)", Artist: "Artist", SpotifyID: "a"},
+				{Title: "Completely different", Artist: "Someone", SpotifyID: "a"},
+			},
+			want: []string{"0<1:canonical,2:exact"},
+		},
+		{
+			name: "untitled tracks are never grouped",
+			tracks: []db.Track{
+				{Artist: "Artist"},
+				{Artist: "Artist"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Tracks are told apart by their index, stored in Album
+			for i := range tt.tracks {
+				tt.tracks[i].Album = fmt.Sprint(i)
+			}
+			var got []string
+			for _, g := range FindDuplicates(tt.tracks) {
+				dups := make([]string, len(g.Duplicates))
+				for i, d := range g.Duplicates {
+					dups[i] = d.Track.Album + ":" + d.Match
+				}
+				got = append(got, g.Keep.Album+"<"+strings.Join(dups, ","))
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,496 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+)
+
+// --- CONFIGURATION ---
+
+// Overridable so tests can point the client at a local fake server
+var (
+	deezerAPIURL     = "https://api.deezer.com"
+	deezerConnectURL = "https://connect.deezer.com"
+)
+
+const deezerPerms = "basic_access,email,offline_access,manage_library"
+
+// DeezerClient calls the Deezer API with a user access token
+type DeezerClient struct {
+	httpClient  *http.Client
+	accessToken string
+}
+
+// deezerError is the error envelope Deezer returns with HTTP 200
+type deezerError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+func getDeezerRedirectURL() string {
+	if redirectURL := os.Getenv("DEEZER_REDIRECT_URL"); redirectURL != "" {
+		return redirectURL
+	}
+	return "http://127.0.0.1:8000/callback/deezer"
+}
+
+// DeezerAuthURL returns the Deezer consent page URL. Deezer's OAuth endpoints take
+// app_id/secret instead of client_id/client_secret, so golang.org/x/oauth2 can't be used.
+func DeezerAuthURL(state string) string {
+	params := url.Values{}
+	params.Set("app_id", os.Getenv("DEEZER_APP_ID"))
+	params.Set("redirect_uri", getDeezerRedirectURL())
+	params.Set("perms", deezerPerms)
+	params.Set("state", state)
+	return deezerConnectURL + "/oauth/auth.php?" + params.Encode()
+}
+
+// ExchangeDeezerCode trades an authorization code for an access token.
+// With offline_access the token does not expire.
+func ExchangeDeezerCode(ctx context.Context, code string) (string, error) {
+	params := url.Values{}
+	params.Set("app_id", os.Getenv("DEEZER_APP_ID"))
+	params.Set("secret", os.Getenv("DEEZER_SECRET"))
+	params.Set("code", code)
+	params.Set("output", "json")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", deezerConnectURL+"/oauth/access_token.php?"+params.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Deezer token request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to exchange Deezer code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	var result struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil || result.AccessToken == "" {
+		return "", fmt.Errorf("Deezer token exchange failed: %s", string(body))
+	}
+	return result.AccessToken, nil
+}
+
+// --- DEEZER FUNCTIONS ---
+
+// GetDeezerClient returns a Deezer client for a user
+func GetDeezerClient(ctx context.Context, user db.User) (*DeezerClient, error) {
+	if user.DeezerToken == "" {
+		return nil, fmt.Errorf("no Deezer token available")
+	}
+	return NewDeezerClient(user.DeezerToken), nil
+}
+
+// NewDeezerClient builds a client from a raw access token
+func NewDeezerClient(accessToken string) *DeezerClient {
+	return &DeezerClient{
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		accessToken: accessToken,
+	}
+}
+
+// do sends a request with the access token. Deezer reports most errors in the body with
+// a 200 status; quota errors (code 4) are surfaced as "429" so callers can back off.
+func (c *DeezerClient) do(ctx context.Context, method, path string, params url.Values, out interface{}) error {
+	if params == nil {
+		params = url.Values{}
+	}
+	// Catalog reads work without a token (see NewDeezerClient(""))
+	if c.accessToken != "" {
+		params.Set("access_token", c.accessToken)
+	}
+
+	endpoint := path
+	if !strings.HasPrefix(endpoint, "http") {
+		endpoint = deezerAPIURL + path
+	}
+	if strings.Contains(endpoint, "?") {
+		endpoint += "&" + params.Encode()
+	} else {
+		endpoint += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create Deezer request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Deezer request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read Deezer response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Deezer API returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var envelope struct {
+		Error *deezerError `json:"error"`
+	}
+	if json.Unmarshal(body, &envelope) == nil && envelope.Error != nil {
+		if envelope.Error.Code == 4 {
+			return fmt.Errorf("Deezer API returned 429: %s", envelope.Error.Message)
+		}
+		return fmt.Errorf("Deezer API error %d (%s): %s", envelope.Error.Code, envelope.Error.Type, envelope.Error.Message)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode Deezer response: %w", err)
+	}
+	return nil
+}
+
+// GetDeezerUser returns the ID and display name of the token's owner
+func GetDeezerUser(ctx context.Context, client *DeezerClient) (string, string, error) {
+	var result struct {
+		ID    int64  `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	if err := client.do(ctx, "GET", "/user/me", nil, &result); err != nil {
+		return "", "", fmt.Errorf("failed to get Deezer user: %w", err)
+	}
+	return strconv.FormatInt(result.ID, 10), result.Name, nil
+}
+
+type deezerPlaylist struct {
+	ID            int64  `json:"id"`
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	PictureMedium string `json:"picture_medium"`
+}
+
+func (p deezerPlaylist) toPlaylist() db.Playlist {
+	return db.Playlist{
+		ID:          uuid.New(),
+		Title:       p.Title,
+		Description: p.Description,
+		Platform:    "deezer",
+		SourceID:    strconv.FormatInt(p.ID, 10),
+		IsPublic:    false,
+		CoverImage:  p.PictureMedium,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// GetDeezerPlaylists retrieves user playlists
+func GetDeezerPlaylists(ctx context.Context, client *DeezerClient) ([]db.Playlist, error) {
+	var playlists []db.Playlist
+	next := "/user/me/playlists?limit=100"
+
+	for next != "" {
+		var result struct {
+			Data []deezerPlaylist `json:"data"`
+			Next string           `json:"next"`
+		}
+		if err := client.do(ctx, "GET", next, nil, &result); err != nil {
+			return nil, fmt.Errorf("failed to get Deezer playlists: %w", err)
+		}
+		for _, p := range result.Data {
+			playlists = append(playlists, p.toPlaylist())
+		}
+		next = result.Next
+	}
+	return playlists, nil
+}
+
+// GetDeezerPlaylist retrieves a single playlist's metadata
+func GetDeezerPlaylist(ctx context.Context, client *DeezerClient, playlistID string) (db.Playlist, error) {
+	var result deezerPlaylist
+	if err := client.do(ctx, "GET", "/playlist/"+url.PathEscape(playlistID), nil, &result); err != nil {
+		return db.Playlist{}, fmt.Errorf("failed to fetch Deezer playlist: %w", err)
+	}
+	return result.toPlaylist(), nil
+}
+
+// GetDeezerPlaylistTracks retrieves tracks from a Deezer playlist. The listing omits ISRCs;
+// they are looked up per track only when a sync needs them, see fillDeezerISRCs.
+func GetDeezerPlaylistTracks(ctx context.Context, client *DeezerClient, playlistID string) ([]db.Track, error) {
+	var tracks []db.Track
+	next := fmt.Sprintf("/playlist/%s/tracks?limit=100", url.PathEscape(playlistID))
+
+	for next != "" {
+		var result struct {
+			Data []struct {
+				ID       int64  `json:"id"`
+				Title    string `json:"title"`
+				Duration int    `json:"duration"` // seconds
+				Preview  string `json:"preview"`
+				Readable bool   `json:"readable"`
+				Artist   struct {
+					Name string `json:"name"`
+				} `json:"artist"`
+				Album struct {
+					Title string `json:"title"`
+				} `json:"album"`
+			} `json:"data"`
+			Next string `json:"next"`
+		}
+		if err := client.do(ctx, "GET", next, nil, &result); err != nil {
+			return nil, fmt.Errorf("failed to retrieve Deezer playlist tracks: %w", err)
+		}
+
+		for _, item := range result.Data {
+			tracks = append(tracks, db.Track{
+				ID:         uuid.New(),
+				Title:      item.Title,
+				Artist:     item.Artist.Name,
+				Album:      item.Album.Title,
+				DeezerID:   strconv.FormatInt(item.ID, 10),
+				DurationMs: item.Duration * 1000,
+				PreviewURL: item.Preview,
+				CreatedAt:  time.Now(),
+			})
+		}
+		next = result.Next
+	}
+	return tracks, nil
+}
+
+func getDeezerTrackISRC(ctx context.Context, client *DeezerClient, trackID string) (string, error) {
+	var result struct {
+		ISRC string `json:"isrc"`
+	}
+	if err := client.do(ctx, "GET", "/track/"+url.PathEscape(trackID), nil, &result); err != nil {
+		return "", err
+	}
+	return result.ISRC, nil
+}
+
+// lookupDeezerISRCs fills in the ISRC of Deezer tracks that have none and returns the indexes
+// of the tracks it changed. It stops at the first quota error; the rest are tried next time.
+func lookupDeezerISRCs(ctx context.Context, client *DeezerClient, tracks []db.Track) []int {
+	var filled []int
+	for i, t := range tracks {
+		if t.DeezerID == "" || t.ISRC != "" {
+			continue
+		}
+		isrc, err := getDeezerTrackISRC(ctx, client, t.DeezerID)
+		if err != nil {
+			log.Printf("Failed to get ISRC for Deezer track %s: %v", t.DeezerID, err)
+			if strings.Contains(err.Error(), "returned 429") {
+				break
+			}
+			continue
+		}
+		if isrc != "" {
+			tracks[i].ISRC = isrc
+			filled = append(filled, i)
+		}
+	}
+	return filled
+}
+
+// fillDeezerISRCs looks up missing ISRCs of tracks imported from Deezer, so other platforms
+// can match them exactly, and saves them so each track is looked up once
+func fillDeezerISRCs(ctx context.Context, tracks []db.Track) {
+	for _, i := range lookupDeezerISRCs(ctx, NewDeezerClient(""), tracks) {
+		if err := db.DB.Model(&db.Track{}).Where("id = ?", tracks[i].ID).Update("isrc", tracks[i].ISRC).Error; err != nil {
+			log.Printf("Failed to save ISRC of track %s: %v", tracks[i].ID, err)
+		}
+	}
+}
+
+// SearchDeezerTrack finds a Deezer track ID, preferring an exact ISRC lookup
+func SearchDeezerTrack(ctx context.Context, client *DeezerClient, title, artist, isrc string) (string, error) {
+	if isrc != "" {
+		var result struct {
+			ID int64 `json:"id"`
+		}
+		if err := client.do(ctx, "GET", "/track/isrc:"+url.PathEscape(isrc), nil, &result); err == nil && result.ID != 0 {
+			return strconv.FormatInt(result.ID, 10), nil
+		}
+	}
+
+	params := url.Values{}
+	params.Set("q", fmt.Sprintf(`artist:"%s" track:"%s"`, artist, title))
+	params.Set("limit", "1")
+	var result struct {
+		Data []struct {
+			ID int64 `json:"id"`
+		} `json:"data"`
+	}
+	if err := client.do(ctx, "GET", "/search/track", params, &result); err != nil {
+		return "", fmt.Errorf("failed to search Deezer track: %w", err)
+	}
+	if len(result.Data) > 0 {
+		return strconv.FormatInt(result.Data[0].ID, 10), nil
+	}
+	return "", nil
+}
+
+// CreateDeezerPlaylist creates a new playlist for the user
+func CreateDeezerPlaylist(ctx context.Context, client *DeezerClient, title string) (string, error) {
+	params := url.Values{}
+	params.Set("title", title)
+	var result struct {
+		ID int64 `json:"id"`
+	}
+	if err := client.do(ctx, "POST", "/user/me/playlists", params, &result); err != nil {
+		return "", fmt.Errorf("failed to create Deezer playlist: %w", err)
+	}
+	return strconv.FormatInt(result.ID, 10), nil
+}
+
+// AddDeezerPlaylistTracks appends tracks to a playlist
+func AddDeezerPlaylistTracks(ctx context.Context, client *DeezerClient, playlistID string, trackIDs ...string) error {
+	params := url.Values{}
+	params.Set("songs", strings.Join(trackIDs, ","))
+	if err := client.do(ctx, "POST", fmt.Sprintf("/playlist/%s/tracks", url.PathEscape(playlistID)), params, nil); err != nil {
+		return fmt.Errorf("failed to add tracks to Deezer playlist: %w", err)
+	}
+	return nil
+}
+
+// ImportToDeezer creates a Deezer playlist and adds tracks
+func ImportToDeezer(ctx context.Context, client *DeezerClient, playlist db.Playlist, tracks []db.Track) (string, error) {
+	return syncToDeezer(ctx, client, playlist, tracks, uuid.Nil)
+}
+
+// syncToDeezer adds tracks to the job's Deezer playlist, creating it on the first run.
+// Tracks without a DeezerID are matched by ISRC, then by title and artist.
+func syncToDeezer(ctx context.Context, client *DeezerClient, playlist db.Playlist, tracks []db.Track, jobID uuid.UUID) (string, error) {
+	dzPlaylistID := loadSyncDestination(jobID, "deezer")
+	if dzPlaylistID == "" {
+		var err error
+		dzPlaylistID, err = CreateDeezerPlaylist(ctx, client, playlist.Title)
+		if err != nil {
+			return "", err
+		}
+		SaveSyncDestination(jobID, "deezer", dzPlaylistID)
+	}
+
+	checkpoints, err := loadSyncCheckpoints(jobID, "deezer")
+	if err != nil {
+		return dzPlaylistID, err
+	}
+
+	successCount := 0
+	for _, track := range tracks {
+		if checkpoints.isDone(track.ID) {
+			continue
+		}
+		trackID := track.DeezerID
+		if trackID == "" {
+			trackID, err = SearchDeezerTrack(ctx, client, track.Title, track.Artist, track.ISRC)
+			if err != nil {
+				if strings.Contains(err.Error(), "429") {
+					return dzPlaylistID, fmt.Errorf("quota exceeded during search: %w", err)
+				}
+				log.Printf("   ⚠️ Deezer search failed for %s - %s: %v", track.Title, track.Artist, err)
+				checkpoints.record(track.ID, "failed", "")
+				continue
+			}
+			if trackID == "" {
+				log.Printf("   ⚠️ Not found on Deezer: %s - %s", track.Title, track.Artist)
+				checkpoints.record(track.ID, "not_found", "")
+				continue
+			}
+		}
+		if err := AddDeezerPlaylistTracks(ctx, client, dzPlaylistID, trackID); err != nil {
+			if strings.Contains(err.Error(), "429") {
+				return dzPlaylistID, fmt.Errorf("quota exceeded during add: %w", err)
+			}
+			log.Printf("   ❌ Failed to add track to Deezer: %s: %v", track.Title, err)
+			checkpoints.record(track.ID, "failed", trackID)
+			continue
+		}
+		checkpoints.record(track.ID, "added", trackID)
+		successCount++
+	}
+	log.Printf("🏁 Deezer Sync Finished. Added %d/%d songs.", successCount, len(tracks))
+	return dzPlaylistID, nil
+}
+
+// ImportDeezerPlaylist imports a single Deezer playlist to DB
+func ImportDeezerPlaylist(ctx context.Context, client *DeezerClient, user db.User, sourceID string) error {
+	playlist, err := GetDeezerPlaylist(ctx, client, sourceID)
+	if err != nil {
+		return err
+	}
+	playlist.OwnerID = user.ID
+
+	var existing db.Playlist
+	if err := db.DB.Where("owner_id = ? AND source_id = ? AND platform = 'deezer'", user.ID, sourceID).First(&existing).Error; err == nil {
+		playlist = existing
+	} else {
+		if err := db.DB.Create(&playlist).Error; err != nil {
+			return fmt.Errorf("failed to create playlist in DB: %w", err)
+		}
+	}
+
+	tracks, err := GetDeezerPlaylistTracks(ctx, client, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch tracks: %w", err)
+	}
+
+	for _, t := range tracks {
+		t.PlaylistID = playlist.ID
+		db.DB.Create(&t)
+	}
+	RecordImportVersion(playlist.ID, user.ID)
+	return nil
+}
+
+// ImportAllDeezerPlaylists imports all playlists and tracks for a user
+func ImportAllDeezerPlaylists(ctx context.Context, user db.User) error {
+	client, err := GetDeezerClient(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	playlists, err := GetDeezerPlaylists(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range playlists {
+		p.OwnerID = user.ID
+		var existing db.Playlist
+		if err := db.DB.Where("owner_id = ? AND source_id = ? AND platform = 'deezer'", user.ID, p.SourceID).First(&existing).Error; err == nil {
+			continue
+		}
+
+		if err := db.DB.Create(&p).Error; err != nil {
+			log.Printf("Failed to create playlist %s: %v", p.Title, err)
+			continue
+		}
+
+		tracks, err := GetDeezerPlaylistTracks(ctx, client, p.SourceID)
+		if err != nil {
+			log.Printf("Failed to get tracks for %s: %v", p.Title, err)
+			continue
+		}
+
+		for _, t := range tracks {
+			t.PlaylistID = p.ID
+			db.DB.Create(&t)
+		}
+		RecordImportVersion(p.ID, user.ID)
+	}
+	return nil
+}
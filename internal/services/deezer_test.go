@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"EchoBridge/db"
+)
+
+// fakeDeezer serves a minimal Deezer API and Connect server. Requests without the
+// test access token are rejected with Deezer's OAuth error envelope.
+func fakeDeezer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	write := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+	authed := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("access_token") != "token" {
+				write(w, map[string]interface{}{"error": map[string]interface{}{
+					"type": "OAuthException", "message": "Invalid OAuth access token.", "code": 300,
+				}})
+				return
+			}
+			h(w, r)
+		}
+	}
+
+	mux.HandleFunc("GET /user/me/playlists", authed(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("index") == "" {
+			write(w, map[string]interface{}{
+				"data": []map[string]interface{}{{"id": 11, "title": "First", "picture_medium": "https://cdn/1.jpg"}},
+				"next": srv.URL + "/user/me/playlists?limit=100&index=1",
+			})
+			return
+		}
+		write(w, map[string]interface{}{
+			"data": []map[string]interface{}{{"id": 12, "title": "Second", "description": "more"}},
+		})
+	}))
+	mux.HandleFunc("GET /playlist/11/tracks", authed(func(w http.ResponseWriter, r *http.Request) {
+		write(w, map[string]interface{}{"data": []map[string]interface{}{
+			{"id": 101, "title": "Song A", "duration": 180, "preview": "https://cdn/a.mp3",
+				"artist": map[string]string{"name": "Artist A"}, "album": map[string]string{"title": "Album A"}},
+			{"id": 102, "title": "Song B", "duration": 200,
+				"artist": map[string]string{"name": "Artist B"}, "album": map[string]string{"title": "Album B"}},
+		}})
+	}))
+	mux.HandleFunc("GET /track/{id}", authed(func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("id") {
+		case "101":
+			write(w, map[string]interface{}{"id": 101, "isrc": "USAAA0000001"})
+		case "102":
+			write(w, map[string]interface{}{"id": 102, "isrc": "USAAA0000002"})
+		case "isrc:USAAA0000009":
+			write(w, map[string]interface{}{"id": 109})
+		default:
+			write(w, map[string]interface{}{"error": map[string]interface{}{
+				"type": "DataException", "message": "no data", "code": 800,
+			}})
+		}
+	}))
+	mux.HandleFunc("GET /search/track", authed(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") == `artist:"Artist C" track:"Song C"` {
+			write(w, map[string]interface{}{"data": []map[string]interface{}{{"id": 103}}})
+			return
+		}
+		if r.URL.Query().Get("q") == `artist:"Busy" track:"Busy"` {
+			write(w, map[string]interface{}{"error": map[string]interface{}{
+				"type": "Exception", "message": "Quota limit exceeded", "code": 4,
+			}})
+			return
+		}
+		write(w, map[string]interface{}{"data": []interface{}{}})
+	}))
+	mux.HandleFunc("POST /user/me/playlists", authed(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("title") != "New list" {
+			t.Errorf("create playlist title = %q", r.URL.Query().Get("title"))
+		}
+		write(w, map[string]interface{}{"id": 500})
+	}))
+	mux.HandleFunc("POST /playlist/500/tracks", authed(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("songs"); got != "101,102" {
+			t.Errorf("songs = %q, want 101,102", got)
+		}
+		write(w, true)
+	}))
+	mux.HandleFunc("GET /oauth/access_token.php", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("code") != "good-code" || q.Get("app_id") != "app" || q.Get("secret") != "secret" || q.Get("output") != "json" {
+			w.Write([]byte("wrong code"))
+			return
+		}
+		write(w, map[string]interface{}{"access_token": "token", "expires": 0})
+	})
+
+	apiURL, connectURL := deezerAPIURL, deezerConnectURL
+	deezerAPIURL, deezerConnectURL = srv.URL, srv.URL
+	t.Cleanup(func() { deezerAPIURL, deezerConnectURL = apiURL, connectURL })
+	return srv
+}
+
+func TestGetDeezerPlaylists(t *testing.T) {
+	fakeDeezer(t)
+	playlists, err := GetDeezerPlaylists(context.Background(), NewDeezerClient("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(playlists) != 2 {
+		t.Fatalf("got %d playlists, want 2 across both pages", len(playlists))
+	}
+	if p := playlists[0]; p.SourceID != "11" || p.Title != "First" || p.Platform != "deezer" || p.CoverImage != "https://cdn/1.jpg" || p.IsPublic {
+		t.Errorf("unexpected first playlist: %+v", p)
+	}
+	if p := playlists[1]; p.SourceID != "12" || p.Description != "more" {
+		t.Errorf("unexpected second playlist: %+v", p)
+	}
+}
+
+func TestGetDeezerPlaylistsInvalidToken(t *testing.T) {
+	fakeDeezer(t)
+	if _, err := GetDeezerPlaylists(context.Background(), NewDeezerClient("expired")); err == nil {
+		t.Fatal("expected the OAuth error envelope to be returned as an error")
+	}
+}
+
+func TestGetDeezerPlaylistTracks(t *testing.T) {
+	fakeDeezer(t)
+	tracks, err := GetDeezerPlaylistTracks(context.Background(), NewDeezerClient("token"), "11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 2 {
+		t.Fatalf("got %d tracks, want 2", len(tracks))
+	}
+	a := tracks[0]
+	if a.DeezerID != "101" || a.Title != "Song A" || a.Artist != "Artist A" || a.Album != "Album A" {
+		t.Errorf("unexpected track: %+v", a)
+	}
+	if a.DurationMs != 180000 || a.PreviewURL != "https://cdn/a.mp3" {
+		t.Errorf("duration/preview = %d/%q", a.DurationMs, a.PreviewURL)
+	}
+	if a.ISRC != "" || tracks[1].ISRC != "" {
+		t.Errorf("ISRCs = %q, %q; want them left for the sync to look up", a.ISRC, tracks[1].ISRC)
+	}
+}
+
+func TestLookupDeezerISRCs(t *testing.T) {
+	fakeDeezer(t)
+	tracks := []db.Track{
+		{DeezerID: "101"},
+		{DeezerID: "102", ISRC: "KEPT00000002"},
+		{SpotifyID: "x"},
+		{DeezerID: "999"},
+	}
+	filled := lookupDeezerISRCs(context.Background(), NewDeezerClient("token"), tracks)
+	if !slices.Equal(filled, []int{0}) {
+		t.Errorf("filled = %v, want [0]", filled)
+	}
+	if tracks[0].ISRC != "USAAA0000001" || tracks[1].ISRC != "KEPT00000002" || tracks[3].ISRC != "" {
+		t.Errorf("ISRCs = %q, %q, %q", tracks[0].ISRC, tracks[1].ISRC, tracks[3].ISRC)
+	}
+}
+
+func TestSearchDeezerTrack(t *testing.T) {
+	fakeDeezer(t)
+	client := NewDeezerClient("token")
+	ctx := context.Background()
+
+	tests := []struct {
+		name                string
+		title, artist, isrc string
+		want                string
+	}{
+		{"isrc match", "Ignored", "Ignored", "USAAA0000009", "109"},
+		{"unknown isrc falls back to search", "Song C", "Artist C", "ZZ0000000000", "103"},
+		{"title and artist", "Song C", "Artist C", "", "103"},
+		{"not found", "Nothing", "Nobody", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SearchDeezerTrack(ctx, client, tt.title, tt.artist, tt.isrc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchDeezerTrackQuota(t *testing.T) {
+	fakeDeezer(t)
+	_, err := SearchDeezerTrack(context.Background(), NewDeezerClient("token"), "Busy", "Busy", "")
+	if err == nil || !strings.Contains(err.Error(), "429") {
+		t.Fatalf("quota errors must surface as 429 so syncs back off, got %v", err)
+	}
+}
+
+func TestCreateDeezerPlaylistAndAddTracks(t *testing.T) {
+	fakeDeezer(t)
+	client := NewDeezerClient("token")
+	ctx := context.Background()
+
+	id, err := CreateDeezerPlaylist(ctx, client, "New list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "500" {
+		t.Fatalf("playlist ID = %q, want 500", id)
+	}
+	if err := AddDeezerPlaylistTracks(ctx, client, id, "101", "102"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExchangeDeezerCode(t *testing.T) {
+	fakeDeezer(t)
+	t.Setenv("DEEZER_APP_ID", "app")
+	t.Setenv("DEEZER_SECRET", "secret")
+
+	token, err := ExchangeDeezerCode(context.Background(), "good-code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "token" {
+		t.Errorf("token = %q", token)
+	}
+	if _, err := ExchangeDeezerCode(context.Background(), "bad-code"); err == nil {
+		t.Error("expected an error for a rejected code")
+	}
+}
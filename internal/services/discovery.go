@@ -0,0 +1,320 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// --- DISCOVERY ---
+
+// Kinds of PlaylistEvent
+const (
+	PlaylistEventView   = "view"
+	PlaylistEventImport = "import"
+)
+
+const (
+	// An import counts as much as this many views in rankings
+	importWeight = 5
+	// Trending scores halve every trendingHalfLife; events older than trendingWindow are ignored
+	trendingHalfLife = 48 * time.Hour
+	trendingWindow   = 14 * 24 * time.Hour
+	// Repeated views by the same viewer within viewWindow count once
+	viewWindow = 6 * time.Hour
+	// PopularImportWindow is the period "most imported" rankings cover
+	PopularImportWindow = 7 * 24 * time.Hour
+
+	// Related playlists are picked from at most this many candidates of each kind
+	relatedCandidates = 200
+)
+
+// RankedPlaylist is a public playlist with its ranking score
+type RankedPlaylist struct {
+	Playlist db.Playlist
+	Score    float64
+}
+
+// RelatedPlaylist is a public playlist similar to another one
+type RelatedPlaylist struct {
+	Playlist     db.Playlist
+	SharedTracks int  // songs both playlists contain
+	SameCategory bool // both playlists have the same category
+	Score        float64
+}
+
+// recordPlaylistEvent increments a playlist counter and stores the event behind it. With a
+// viewer, nothing is recorded when the same viewer caused the same kind of event within viewWindow.
+func recordPlaylistEvent(playlistID uuid.UUID, kind, counter, viewer string) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if viewer != "" {
+			// Serialize events of the playlist so concurrent requests see each other
+			var playlist db.Playlist
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", playlistID).First(&playlist).Error; err != nil {
+				return fmt.Errorf("failed to load playlist: %w", err)
+			}
+			var recent int64
+			err := tx.Model(&db.PlaylistEvent{}).
+				Where("playlist_id = ? AND kind = ? AND viewer = ? AND created_at > ?", playlistID, kind, viewer, time.Now().Add(-viewWindow)).
+				Count(&recent).Error
+			if err != nil {
+				return fmt.Errorf("failed to check recent %s: %w", kind, err)
+			}
+			if recent > 0 {
+				return nil
+			}
+		}
+		if err := tx.Model(&db.Playlist{}).Where("id = ?", playlistID).UpdateColumn(counter, gorm.Expr(counter+" + 1")).Error; err != nil {
+			return fmt.Errorf("failed to count %s: %w", kind, err)
+		}
+		return tx.Create(&db.PlaylistEvent{
+			ID:         uuid.New(),
+			PlaylistID: playlistID,
+			Kind:       kind,
+			Viewer:     viewer,
+			CreatedAt:  time.Now(),
+		}).Error
+	})
+}
+
+// viewerKey identifies a viewer without storing who they are: the user ID when logged in,
+// otherwise the client address and user agent
+func viewerKey(viewerID uuid.UUID, clientIP, userAgent string) string {
+	key := "client:" + clientIP + "|" + userAgent
+	if viewerID != uuid.Nil {
+		key = "user:" + viewerID.String()
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// RecordPlaylistView counts a view of a playlist, once per viewer within viewWindow. viewerID is
+// uuid.Nil for anonymous visitors, who are told apart by client address and user agent; owners
+// looking at their own playlist are not counted.
+func RecordPlaylistView(playlist db.Playlist, viewerID uuid.UUID, clientIP, userAgent string) error {
+	if viewerID == playlist.OwnerID {
+		return nil
+	}
+	return recordPlaylistEvent(playlist.ID, PlaylistEventView, "view_count", viewerKey(viewerID, clientIP, userAgent))
+}
+
+// PrunePlaylistEvents deletes events older than any ranking looks at
+func PrunePlaylistEvents() (int64, error) {
+	result := db.DB.Where("created_at < ?", time.Now().Add(-trendingWindow)).Delete(&db.PlaylistEvent{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune playlist events: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// RecordPlaylistImport counts an import of a playlist to a platform by another user
+func RecordPlaylistImport(playlist db.Playlist, userID uuid.UUID) error {
+	if userID == playlist.OwnerID {
+		return nil
+	}
+	return recordPlaylistEvent(playlist.ID, PlaylistEventImport, "import_count", "")
+}
+
+// rankPlaylists scores public playlists by their events since a time, highest first
+func rankPlaylists(score string, since time.Time, kinds []string, limit int, args ...interface{}) ([]RankedPlaylist, error) {
+	var rows []struct {
+		PlaylistID uuid.UUID
+		Score      float64
+	}
+	err := db.DB.Model(&db.PlaylistEvent{}).
+		Select("playlist_events.playlist_id, "+score+" AS score", args...).
+		Joins("JOIN playlists ON playlists.id = playlist_events.playlist_id AND playlists.is_public = ?", true).
+		Where("playlist_events.kind IN ? AND playlist_events.created_at > ?", kinds, since).
+		Group("playlist_events.playlist_id").
+		Order("score DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to rank playlists: %w", err)
+	}
+	if len(rows) == 0 {
+		return []RankedPlaylist{}, nil
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, r := range rows {
+		ids[i] = r.PlaylistID
+	}
+	var playlists []db.Playlist
+	if err := db.DB.Where("id IN ?", ids).Find(&playlists).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch playlists: %w", err)
+	}
+	byID := make(map[uuid.UUID]db.Playlist, len(playlists))
+	for _, p := range playlists {
+		byID[p.ID] = p
+	}
+
+	ranked := make([]RankedPlaylist, 0, len(rows))
+	for _, r := range rows {
+		if p, ok := byID[r.PlaylistID]; ok {
+			ranked = append(ranked, RankedPlaylist{Playlist: p, Score: r.Score})
+		}
+	}
+	return ranked, nil
+}
+
+// TrendingPlaylists ranks public playlists by recent views and imports. Each event's weight
+// halves every trendingHalfLife, so a burst of activity today outranks a larger one last week.
+func TrendingPlaylists(limit int) ([]RankedPlaylist, error) {
+	now := time.Now()
+	score := "SUM(CASE WHEN playlist_events.kind = 'import' THEN ? ELSE 1 END" +
+		" * POWER(0.5, EXTRACT(EPOCH FROM (?::timestamptz - playlist_events.created_at)) / ?))"
+	return rankPlaylists(score, now.Add(-trendingWindow), []string{PlaylistEventView, PlaylistEventImport}, limit,
+		importWeight, now, trendingHalfLife.Seconds())
+}
+
+// MostImportedPlaylists ranks public playlists by imports during the last PopularImportWindow
+func MostImportedPlaylists(limit int) ([]RankedPlaylist, error) {
+	return rankPlaylists("COUNT(*)", time.Now().Add(-PopularImportWindow), []string{PlaylistEventImport}, limit)
+}
+
+// RelatedPlaylists finds public playlists similar to a playlist. Candidates share a track by
+// ISRC or platform ID, or have the same category; they are scored by the songs both contain,
+// matched by canonical identity, relative to the sizes of both playlists.
+func RelatedPlaylists(playlist db.Playlist, limit int) ([]RelatedPlaylist, error) {
+	tracks, err := PlaylistTracks(playlist.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Distinct songs of the playlist
+	var songs []db.Track
+	seen := newTrackSet(nil)
+	for _, t := range tracks {
+		if !seen.contains(t) {
+			seen.add(t)
+			songs = append(songs, t)
+		}
+	}
+
+	candidates := make(map[uuid.UUID]bool)
+	if len(songs) > 0 {
+		var conditions []string
+		var args []interface{}
+		for _, column := range []struct {
+			name  string
+			value func(db.Track) string
+		}{
+			{"isrc", func(t db.Track) string { return t.ISRC }},
+			{"spotify_id", func(t db.Track) string { return t.SpotifyID }},
+			{"you_tube_id", func(t db.Track) string { return t.YouTubeID }},
+			{"apple_music_id", func(t db.Track) string { return t.AppleMusicID }},
+			{"deezer_id", func(t db.Track) string { return t.DeezerID }},
+			{"tidal_id", func(t db.Track) string { return t.TidalID }},
+		} {
+			var values []string
+			for _, t := range songs {
+				if v := column.value(t); v != "" {
+					values = append(values, v)
+				}
+			}
+			if len(values) > 0 {
+				conditions = append(conditions, "tracks."+column.name+" IN ?")
+				args = append(args, values)
+			}
+		}
+		if len(conditions) > 0 {
+			var shared []uuid.UUID
+			err := db.DB.Model(&db.Track{}).
+				Select("tracks.playlist_id").
+				Joins("JOIN playlists ON playlists.id = tracks.playlist_id AND playlists.is_public = ?", true).
+				Where("tracks.playlist_id <> ?", playlist.ID).
+				Where(strings.Join(conditions, " OR "), args...).
+				Group("tracks.playlist_id").
+				Order("COUNT(*) DESC").
+				Limit(relatedCandidates).
+				Pluck("tracks.playlist_id", &shared).Error
+			if err != nil {
+				return nil, fmt.Errorf("failed to find related playlists: %w", err)
+			}
+			for _, id := range shared {
+				candidates[id] = true
+			}
+		}
+	}
+	if playlist.Category != "" {
+		var sameCategory []uuid.UUID
+		err := db.DB.Model(&db.Playlist{}).
+			Where("is_public = ? AND id <> ? AND LOWER(category) = LOWER(?)", true, playlist.ID, playlist.Category).
+			Order("import_count DESC, view_count DESC").
+			Limit(relatedCandidates).
+			Pluck("id", &sameCategory).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to find related playlists: %w", err)
+		}
+		for _, id := range sameCategory {
+			candidates[id] = true
+		}
+	}
+	if len(candidates) == 0 {
+		return []RelatedPlaylist{}, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(candidates))
+	for id := range candidates {
+		ids = append(ids, id)
+	}
+	var playlists []db.Playlist
+	if err := db.DB.Where("id IN ?", ids).Find(&playlists).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch playlists: %w", err)
+	}
+	var candidateTracks []db.Track
+	if err := db.DB.Where("playlist_id IN ?", ids).Find(&candidateTracks).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch tracks: %w", err)
+	}
+	tracksByPlaylist := make(map[uuid.UUID][]db.Track)
+	for _, t := range candidateTracks {
+		tracksByPlaylist[t.PlaylistID] = append(tracksByPlaylist[t.PlaylistID], t)
+	}
+
+	related := make([]RelatedPlaylist, 0, len(playlists))
+	for _, p := range playlists {
+		other := tracksByPlaylist[p.ID]
+		set := newTrackSet(other)
+		shared := 0
+		for _, t := range songs {
+			if set.contains(t) {
+				shared++
+			}
+		}
+		sameCategory := playlist.Category != "" && strings.EqualFold(p.Category, playlist.Category)
+
+		// Cosine-style overlap, so large playlists do not win on size alone
+		score := 0.0
+		if shared > 0 {
+			score = float64(shared) / math.Sqrt(float64(len(songs))*float64(len(other)))
+		}
+		if sameCategory {
+			score += 0.1
+		}
+		if score > 0 {
+			related = append(related, RelatedPlaylist{Playlist: p, SharedTracks: shared, SameCategory: sameCategory, Score: score})
+		}
+	}
+
+	sort.Slice(related, func(i, j int) bool {
+		if related[i].Score != related[j].Score {
+			return related[i].Score > related[j].Score
+		}
+		return related[i].Playlist.ImportCount > related[j].Playlist.ImportCount
+	})
+	if len(related) > limit {
+		related = related[:limit]
+	}
+	return related, nil
+}
@@ -0,0 +1,453 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+)
+
+// --- CONFIGURATION ---
+
+// jellyfinAuthHeader identifies EchoBridge to the server. Emby and Jellyfin both accept
+// the X-Emby-Authorization header.
+const jellyfinAuthHeader = `MediaBrowser Client="EchoBridge", Device="EchoBridge", DeviceId="echobridge-server", Version="1.0.0"`
+
+// JellyfinClient talks to a Jellyfin or Emby server. token is either an API key or the
+// access token returned by AuthenticateByName; both are sent as X-Emby-Token.
+type JellyfinClient struct {
+	httpClient *http.Client
+	serverURL  string
+	userID     string
+	token      string
+}
+
+type jellyfinItem struct {
+	ID           string            `json:"Id"`
+	Name         string            `json:"Name"`
+	Overview     string            `json:"Overview"`
+	Album        string            `json:"Album"`
+	AlbumArtist  string            `json:"AlbumArtist"`
+	Artists      []string          `json:"Artists"`
+	RunTimeTicks int64             `json:"RunTimeTicks"` // 100ns units
+	MediaType    string            `json:"MediaType"`
+	ProviderIds  map[string]string `json:"ProviderIds"`
+}
+
+type jellyfinItemsResponse struct {
+	Items            []jellyfinItem `json:"Items"`
+	TotalRecordCount int            `json:"TotalRecordCount"`
+}
+
+// NormalizeJellyfinURL validates a server URL and trims trailing slashes
+func NormalizeJellyfinURL(serverURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(serverURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid server URL: %s", serverURL)
+	}
+	u.RawQuery = ""
+	return strings.TrimRight(u.String(), "/"), nil
+}
+
+// --- JELLYFIN FUNCTIONS ---
+
+// GetJellyfinClient returns a Jellyfin client for a user
+func GetJellyfinClient(ctx context.Context, user db.User) (*JellyfinClient, error) {
+	if user.JellyfinURL == "" || user.JellyfinToken == "" {
+		return nil, fmt.Errorf("no Jellyfin server linked")
+	}
+	return NewJellyfinClient(user.JellyfinURL, user.JellyfinUserID, user.JellyfinToken), nil
+}
+
+// NewJellyfinClient builds a client from stored credentials
+func NewJellyfinClient(serverURL, userID, token string) *JellyfinClient {
+	return &JellyfinClient{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		serverURL:  serverURL,
+		userID:     userID,
+		token:      token,
+	}
+}
+
+func (c *JellyfinClient) do(ctx context.Context, method, path string, params url.Values, body interface{}, out interface{}) error {
+	endpoint := c.serverURL + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode Jellyfin request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create Jellyfin request: %w", err)
+	}
+	req.Header.Set("X-Emby-Authorization", jellyfinAuthHeader)
+	if c.token != "" {
+		req.Header.Set("X-Emby-Token", c.token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Jellyfin request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read Jellyfin response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Jellyfin server returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode Jellyfin response: %w", err)
+	}
+	return nil
+}
+
+// AuthenticateJellyfin logs in with a username and password and returns the user ID and access token
+func AuthenticateJellyfin(ctx context.Context, serverURL, username, password string) (string, string, error) {
+	client := NewJellyfinClient(serverURL, "", "")
+	var result struct {
+		AccessToken string `json:"AccessToken"`
+		User        struct {
+			ID string `json:"Id"`
+		} `json:"User"`
+	}
+	body := map[string]string{"Username": username, "Pw": password}
+	if err := client.do(ctx, "POST", "/Users/AuthenticateByName", nil, body, &result); err != nil {
+		return "", "", fmt.Errorf("failed to authenticate with Jellyfin: %w", err)
+	}
+	if result.AccessToken == "" || result.User.ID == "" {
+		return "", "", fmt.Errorf("Jellyfin did not return an access token")
+	}
+	return result.User.ID, result.AccessToken, nil
+}
+
+// GetJellyfinUserID resolves the user an API key should act as. API keys are not bound
+// to a user, so the username picks one from the server's user list.
+func GetJellyfinUserID(ctx context.Context, client *JellyfinClient, username string) (string, error) {
+	var users []struct {
+		ID   string `json:"Id"`
+		Name string `json:"Name"`
+	}
+	if err := client.do(ctx, "GET", "/Users", nil, nil, &users); err != nil {
+		return "", fmt.Errorf("failed to list Jellyfin users: %w", err)
+	}
+	for _, u := range users {
+		if strings.EqualFold(u.Name, username) {
+			return u.ID, nil
+		}
+	}
+	return "", fmt.Errorf("Jellyfin user %q not found", username)
+}
+
+// toPlaylist converts a playlist item. Its cover stays empty: server image URLs would expose
+// the user's private server address on public pages and are unreachable for anyone else.
+func (item jellyfinItem) toPlaylist() db.Playlist {
+	return db.Playlist{
+		ID:          uuid.New(),
+		Title:       item.Name,
+		Description: item.Overview,
+		Platform:    "jellyfin",
+		SourceID:    item.ID,
+		IsPublic:    false,
+		CreatedAt:   time.Now(),
+	}
+}
+
+func (item jellyfinItem) toTrack() db.Track {
+	artist := strings.Join(item.Artists, ", ")
+	if artist == "" {
+		artist = item.AlbumArtist
+	}
+	return db.Track{
+		ID:            uuid.New(),
+		Title:         item.Name,
+		Artist:        artist,
+		Album:         item.Album,
+		JellyfinID:    item.ID,
+		MusicBrainzID: item.ProviderIds["MusicBrainzTrack"],
+		DurationMs:    int(item.RunTimeTicks / 10000),
+		CreatedAt:     time.Now(),
+	}
+}
+
+// GetJellyfinPlaylists retrieves the user's audio playlists
+func GetJellyfinPlaylists(ctx context.Context, client *JellyfinClient) ([]db.Playlist, error) {
+	params := url.Values{}
+	params.Set("IncludeItemTypes", "Playlist")
+	params.Set("Recursive", "true")
+	params.Set("Fields", "Overview")
+
+	var result jellyfinItemsResponse
+	if err := client.do(ctx, "GET", "/Users/"+url.PathEscape(client.userID)+"/Items", params, nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to get Jellyfin playlists: %w", err)
+	}
+
+	var playlists []db.Playlist
+	for _, item := range result.Items {
+		// Video playlists share the Playlist item type
+		if item.MediaType != "" && item.MediaType != "Audio" {
+			continue
+		}
+		playlists = append(playlists, item.toPlaylist())
+	}
+	return playlists, nil
+}
+
+// GetJellyfinPlaylist retrieves a single playlist's metadata
+func GetJellyfinPlaylist(ctx context.Context, client *JellyfinClient, playlistID string) (db.Playlist, error) {
+	params := url.Values{}
+	params.Set("Fields", "Overview")
+
+	var item jellyfinItem
+	if err := client.do(ctx, "GET", "/Users/"+url.PathEscape(client.userID)+"/Items/"+url.PathEscape(playlistID), params, nil, &item); err != nil {
+		return db.Playlist{}, fmt.Errorf("failed to fetch Jellyfin playlist: %w", err)
+	}
+	return item.toPlaylist(), nil
+}
+
+// GetJellyfinPlaylistTracks retrieves the audio items of a playlist
+func GetJellyfinPlaylistTracks(ctx context.Context, client *JellyfinClient, playlistID string) ([]db.Track, error) {
+	var tracks []db.Track
+	startIndex := 0
+	for {
+		params := url.Values{}
+		params.Set("UserId", client.userID)
+		params.Set("Fields", "ProviderIds")
+		params.Set("StartIndex", fmt.Sprint(startIndex))
+		params.Set("Limit", "200")
+
+		var result jellyfinItemsResponse
+		if err := client.do(ctx, "GET", "/Playlists/"+url.PathEscape(playlistID)+"/Items", params, nil, &result); err != nil {
+			return nil, fmt.Errorf("failed to get Jellyfin playlist items: %w", err)
+		}
+		for _, item := range result.Items {
+			if item.MediaType != "" && item.MediaType != "Audio" {
+				continue
+			}
+			tracks = append(tracks, item.toTrack())
+		}
+
+		startIndex += len(result.Items)
+		if len(result.Items) == 0 || startIndex >= result.TotalRecordCount {
+			break
+		}
+	}
+	return tracks, nil
+}
+
+// SearchJellyfinTrack finds an audio item in the library, preferring a MusicBrainz match
+func SearchJellyfinTrack(ctx context.Context, client *JellyfinClient, title, artist, musicBrainzID string) (string, error) {
+	params := url.Values{}
+	params.Set("searchTerm", title)
+	params.Set("IncludeItemTypes", "Audio")
+	params.Set("Recursive", "true")
+	params.Set("Fields", "ProviderIds")
+	params.Set("Limit", "20")
+
+	var result jellyfinItemsResponse
+	if err := client.do(ctx, "GET", "/Users/"+url.PathEscape(client.userID)+"/Items", params, nil, &result); err != nil {
+		return "", fmt.Errorf("failed to search Jellyfin track: %w", err)
+	}
+	if len(result.Items) == 0 {
+		return "", nil
+	}
+
+	if musicBrainzID != "" {
+		for _, item := range result.Items {
+			if item.ProviderIds["MusicBrainzTrack"] == musicBrainzID {
+				return item.ID, nil
+			}
+		}
+	}
+	// Otherwise only accept the same title by the same artist; a loose hit would add the wrong song
+	for _, item := range result.Items {
+		if !strings.EqualFold(item.Name, title) {
+			continue
+		}
+		for _, a := range item.Artists {
+			if a != "" && strings.Contains(strings.ToLower(artist), strings.ToLower(a)) {
+				return item.ID, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// CreateJellyfinPlaylist creates an audio playlist owned by the user
+func CreateJellyfinPlaylist(ctx context.Context, client *JellyfinClient, title string) (string, error) {
+	body := map[string]interface{}{
+		"Name":      title,
+		"UserId":    client.userID,
+		"MediaType": "Audio",
+		"Ids":       []string{},
+	}
+	var result struct {
+		ID string `json:"Id"`
+	}
+	if err := client.do(ctx, "POST", "/Playlists", nil, body, &result); err != nil {
+		return "", fmt.Errorf("failed to create Jellyfin playlist: %w", err)
+	}
+	return result.ID, nil
+}
+
+// AddJellyfinPlaylistTracks appends items to a playlist
+func AddJellyfinPlaylistTracks(ctx context.Context, client *JellyfinClient, playlistID string, itemIDs ...string) error {
+	params := url.Values{}
+	params.Set("Ids", strings.Join(itemIDs, ","))
+	params.Set("UserId", client.userID)
+	if err := client.do(ctx, "POST", "/Playlists/"+url.PathEscape(playlistID)+"/Items", params, nil, nil); err != nil {
+		return fmt.Errorf("failed to add tracks to Jellyfin playlist: %w", err)
+	}
+	return nil
+}
+
+// ImportToJellyfin creates a Jellyfin playlist and adds tracks
+func ImportToJellyfin(ctx context.Context, client *JellyfinClient, playlist db.Playlist, tracks []db.Track) (string, error) {
+	return syncToJellyfin(ctx, client, playlist, tracks, uuid.Nil)
+}
+
+// syncToJellyfin adds tracks to the job's Jellyfin playlist, creating it on the first run.
+// Only items already in the user's library can be added, so unmatched tracks are skipped.
+func syncToJellyfin(ctx context.Context, client *JellyfinClient, playlist db.Playlist, tracks []db.Track, jobID uuid.UUID) (string, error) {
+	jfPlaylistID := loadSyncDestination(jobID, "jellyfin")
+	if jfPlaylistID == "" {
+		var err error
+		jfPlaylistID, err = CreateJellyfinPlaylist(ctx, client, playlist.Title)
+		if err != nil {
+			return "", err
+		}
+		SaveSyncDestination(jobID, "jellyfin", jfPlaylistID)
+	}
+
+	checkpoints, err := loadSyncCheckpoints(jobID, "jellyfin")
+	if err != nil {
+		return jfPlaylistID, err
+	}
+
+	successCount := 0
+	for _, track := range tracks {
+		if checkpoints.isDone(track.ID) {
+			continue
+		}
+		itemID := track.JellyfinID
+		if itemID == "" {
+			itemID, err = SearchJellyfinTrack(ctx, client, track.Title, track.Artist, track.MusicBrainzID)
+			if err != nil {
+				log.Printf("   ⚠️ Jellyfin search failed for %s - %s: %v", track.Title, track.Artist, err)
+				checkpoints.record(track.ID, "failed", "")
+				continue
+			}
+			if itemID == "" {
+				log.Printf("   ⚠️ Not in Jellyfin library: %s - %s", track.Title, track.Artist)
+				checkpoints.record(track.ID, "not_found", "")
+				continue
+			}
+		}
+		if err := AddJellyfinPlaylistTracks(ctx, client, jfPlaylistID, itemID); err != nil {
+			log.Printf("   ❌ Failed to add track to Jellyfin: %s: %v", track.Title, err)
+			checkpoints.record(track.ID, "failed", itemID)
+			continue
+		}
+		checkpoints.record(track.ID, "added", itemID)
+		successCount++
+	}
+	log.Printf("🏁 Jellyfin Sync Finished. Added %d/%d songs.", successCount, len(tracks))
+	return jfPlaylistID, nil
+}
+
+// ImportJellyfinPlaylist imports a single Jellyfin playlist to DB
+func ImportJellyfinPlaylist(ctx context.Context, client *JellyfinClient, user db.User, sourceID string) error {
+	playlist, err := GetJellyfinPlaylist(ctx, client, sourceID)
+	if err != nil {
+		return err
+	}
+	playlist.OwnerID = user.ID
+
+	var existing db.Playlist
+	if err := db.DB.Where("owner_id = ? AND source_id = ? AND platform = 'jellyfin'", user.ID, sourceID).First(&existing).Error; err == nil {
+		playlist = existing
+	} else {
+		if err := db.DB.Create(&playlist).Error; err != nil {
+			return fmt.Errorf("failed to create playlist in DB: %w", err)
+		}
+	}
+
+	tracks, err := GetJellyfinPlaylistTracks(ctx, client, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch tracks: %w", err)
+	}
+
+	for _, t := range tracks {
+		t.PlaylistID = playlist.ID
+		db.DB.Create(&t)
+	}
+	RecordImportVersion(playlist.ID, user.ID)
+	return nil
+}
+
+// ImportAllJellyfinPlaylists imports all audio playlists and tracks for a user
+func ImportAllJellyfinPlaylists(ctx context.Context, user db.User) error {
+	client, err := GetJellyfinClient(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	playlists, err := GetJellyfinPlaylists(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range playlists {
+		p.OwnerID = user.ID
+		var existing db.Playlist
+		if err := db.DB.Where("owner_id = ? AND source_id = ? AND platform = 'jellyfin'", user.ID, p.SourceID).First(&existing).Error; err == nil {
+			continue
+		}
+
+		if err := db.DB.Create(&p).Error; err != nil {
+			log.Printf("Failed to create playlist %s: %v", p.Title, err)
+			continue
+		}
+
+		tracks, err := GetJellyfinPlaylistTracks(ctx, client, p.SourceID)
+		if err != nil {
+			log.Printf("Failed to get tracks for %s: %v", p.Title, err)
+			continue
+		}
+
+		for _, t := range tracks {
+			t.PlaylistID = p.ID
+			db.DB.Create(&t)
+		}
+		RecordImportVersion(p.ID, user.ID)
+	}
+	return nil
+}
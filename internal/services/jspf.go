@@ -0,0 +1,152 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+)
+
+// --- JSPF (JSON XSPF) ---
+
+const (
+	musicBrainzRecordingURL = "https://musicbrainz.org/recording/"
+	jspfPlaylistExtension   = "https://musicbrainz.org/doc/jspf#playlist" // extension namespace, not a file suffix
+	jspfFileExtension       = ".jspf"
+)
+
+// JSPFDocument is a JSPF playlist as used by ListenBrainz, see https://www.xspf.org/jspf
+type JSPFDocument struct {
+	Playlist JSPFPlaylist `json:"playlist"`
+}
+
+type JSPFPlaylist struct {
+	Title      string                     `json:"title"`
+	Creator    string                     `json:"creator,omitempty"`
+	Annotation string                     `json:"annotation,omitempty"`
+	Identifier string                     `json:"identifier,omitempty"`
+	Image      string                     `json:"image,omitempty"`
+	Date       string                     `json:"date,omitempty"`
+	Extension  map[string]json.RawMessage `json:"extension,omitempty"`
+	Track      []JSPFTrack                `json:"track"`
+}
+
+type JSPFTrack struct {
+	Title      string          `json:"title,omitempty"`
+	Creator    string          `json:"creator,omitempty"`
+	Album      string          `json:"album,omitempty"`
+	Duration   int             `json:"duration,omitempty"` // milliseconds
+	Identifier JSPFIdentifiers `json:"identifier,omitempty"`
+	Location   JSPFIdentifiers `json:"location,omitempty"`
+}
+
+// JSPFIdentifiers accepts both a single URI and a list, since JSPF producers disagree
+type JSPFIdentifiers []string
+
+func (ids *JSPFIdentifiers) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		if single != "" {
+			*ids = JSPFIdentifiers{single}
+		}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*ids = list
+	return nil
+}
+
+// BuildJSPF converts a playlist to JSPF. Tracks are identified by MusicBrainz recording
+// and by every platform link known for them.
+func BuildJSPF(playlist db.Playlist, tracks []db.Track) JSPFDocument {
+	doc := JSPFDocument{Playlist: JSPFPlaylist{
+		Title:      playlist.Title,
+		Annotation: playlist.Description,
+		Image:      playlist.CoverImage,
+		Date:       playlist.CreatedAt.Format(time.RFC3339),
+		Track:      []JSPFTrack{},
+	}}
+	for _, t := range tracks {
+		var ids JSPFIdentifiers
+		if t.MusicBrainzID != "" {
+			ids = append(ids, musicBrainzRecordingURL+t.MusicBrainzID)
+		}
+		if t.SpotifyID != "" {
+			ids = append(ids, "https://open.spotify.com/track/"+t.SpotifyID)
+		}
+		if t.YouTubeID != "" {
+			ids = append(ids, "https://www.youtube.com/watch?v="+t.YouTubeID)
+		}
+		if t.DeezerID != "" {
+			ids = append(ids, "https://www.deezer.com/track/"+t.DeezerID)
+		}
+		if t.TidalID != "" {
+			ids = append(ids, "https://tidal.com/browse/track/"+t.TidalID)
+		}
+		if t.SoundCloudURL != "" {
+			ids = append(ids, t.SoundCloudURL)
+		}
+		doc.Playlist.Track = append(doc.Playlist.Track, JSPFTrack{
+			Title:      t.Title,
+			Creator:    t.Artist,
+			Album:      t.Album,
+			Duration:   t.DurationMs,
+			Identifier: ids,
+		})
+	}
+	return doc
+}
+
+// ParseJSPF converts a JSPF playlist to DB records for the given platform. Identifiers
+// that point at MusicBrainz, Spotify, YouTube, Deezer, TIDAL or SoundCloud are kept as track IDs.
+func ParseJSPF(doc JSPFDocument, platform, sourceID string) (db.Playlist, []db.Track) {
+	playlist := db.Playlist{
+		ID:          uuid.New(),
+		Title:       doc.Playlist.Title,
+		Description: doc.Playlist.Annotation,
+		Platform:    platform,
+		SourceID:    sourceID,
+		CoverImage:  doc.Playlist.Image,
+		CreatedAt:   time.Now(),
+	}
+
+	var tracks []db.Track
+	for _, jt := range doc.Playlist.Track {
+		track := db.Track{
+			ID:         uuid.New(),
+			Title:      jt.Title,
+			Artist:     jt.Creator,
+			Album:      jt.Album,
+			DurationMs: jt.Duration,
+			CreatedAt:  time.Now(),
+		}
+		for _, id := range append(jt.Identifier, jt.Location...) {
+			switch {
+			case strings.HasPrefix(id, musicBrainzRecordingURL):
+				track.MusicBrainzID = strings.TrimPrefix(id, musicBrainzRecordingURL)
+			case strings.HasPrefix(id, "https://open.spotify.com/track/"):
+				track.SpotifyID = strings.TrimPrefix(id, "https://open.spotify.com/track/")
+			case strings.HasPrefix(id, "https://www.youtube.com/watch?v="):
+				track.YouTubeID = strings.TrimPrefix(id, "https://www.youtube.com/watch?v=")
+			case strings.HasPrefix(id, "https://www.deezer.com/track/"):
+				if v := strings.TrimPrefix(id, "https://www.deezer.com/track/"); deezerIDPattern.MatchString(v) {
+					track.DeezerID = v
+				}
+			case strings.HasPrefix(id, "https://tidal.com/browse/track/"):
+				if v := strings.TrimPrefix(id, "https://tidal.com/browse/track/"); deezerIDPattern.MatchString(v) {
+					track.TidalID = v
+				}
+			case IsPlatformURL("soundcloud", id):
+				track.SoundCloudURL = id
+			}
+		}
+		tracks = append(tracks, track)
+	}
+	return playlist, tracks
+}
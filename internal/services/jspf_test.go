@@ -0,0 +1,25 @@
+package services
+
+import (
+	"testing"
+
+	"EchoBridge/db"
+)
+
+func TestJSPFRoundTrip(t *testing.T) {
+	want := db.Track{
+		Title: "Song", Artist: "Artist", Album: "Album", DurationMs: 215000,
+		MusicBrainzID: "c1a8a0e2-1f8e-4e8c-9d5f-6b1f4a2a3b4c", SpotifyID: "4uLU6hMCjMI75M1A2tKUQC",
+		YouTubeID: "dQw4w9WgXcQ", DeezerID: "3135556", TidalID: "5204441",
+		SoundCloudURL: "https://soundcloud.com/artist/song",
+	}
+	_, tracks := ParseJSPF(BuildJSPF(db.Playlist{Title: "Mix"}, []db.Track{want}), "listenbrainz", "mix")
+	if len(tracks) != 1 {
+		t.Fatalf("got %d tracks, want 1", len(tracks))
+	}
+	got := tracks[0]
+	got.ID, got.CreatedAt = want.ID, want.CreatedAt
+	if got != want {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", got, want)
+	}
+}
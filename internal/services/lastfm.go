@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+)
+
+// --- CONFIGURATION ---
+
+var lastFMAPIURL = "https://ws.audioscrobbler.com/2.0/"
+
+// LastFMTopPeriods are the periods accepted by user.getTopTracks, used as "top:<period>" source IDs
+var LastFMTopPeriods = map[string]string{
+	"7day":    "7 days",
+	"1month":  "1 month",
+	"3month":  "3 months",
+	"6month":  "6 months",
+	"12month": "12 months",
+	"overall": "all time",
+}
+
+// lastFMMaxTracks caps how much of a long loved-tracks or scrobble history is imported
+const lastFMMaxTracks = 1000
+
+// LastFMClient reads a user's listening history. Read methods only need the API key;
+// the session key proves the account was linked by its owner.
+type LastFMClient struct {
+	httpClient *http.Client
+	apiKey     string
+	username   string
+}
+
+type lastFMTrack struct {
+	Name     string `json:"name"`
+	MBID     string `json:"mbid"`
+	Duration string `json:"duration"` // seconds, top tracks only
+	Artist   struct {
+		Name string `json:"name"`
+		Text string `json:"#text"` // recent tracks use #text instead of name
+	} `json:"artist"`
+	Album struct {
+		Text string `json:"#text"`
+	} `json:"album"`
+	Attr struct {
+		NowPlaying string `json:"nowplaying"`
+	} `json:"@attr"`
+}
+
+// lastFMTrackList accepts both a list and a single object, which Last.fm returns for one result
+type lastFMTrackList []lastFMTrack
+
+func (l *lastFMTrackList) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '{' {
+		var single lastFMTrack
+		if err := json.Unmarshal(data, &single); err != nil {
+			return err
+		}
+		*l = lastFMTrackList{single}
+		return nil
+	}
+	var list []lastFMTrack
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+type lastFMTrackPage struct {
+	Track lastFMTrackList `json:"track"`
+	Attr  struct {
+		Page       string `json:"page"`
+		TotalPages string `json:"totalPages"`
+	} `json:"@attr"`
+}
+
+func getLastFMAPIKey() string {
+	return os.Getenv("LASTFM_API_KEY")
+}
+
+// LastFMAuthURL returns the Last.fm web auth URL. Last.fm appends ?token= to the callback.
+func LastFMAuthURL(callbackURL string) string {
+	params := url.Values{}
+	params.Set("api_key", getLastFMAPIKey())
+	params.Set("cb", callbackURL)
+	return "https://www.last.fm/api/auth/?" + params.Encode()
+}
+
+// signLastFM computes api_sig: md5 of the sorted name/value pairs followed by the secret
+func signLastFM(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		if k != "format" && k != "callback" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteString(params.Get(k))
+	}
+	sb.WriteString(os.Getenv("LASTFM_API_SECRET"))
+	sum := md5.Sum([]byte(sb.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetLastFMSession exchanges a web auth token for a session, returning username and session key
+func GetLastFMSession(ctx context.Context, token string) (string, string, error) {
+	params := url.Values{}
+	params.Set("method", "auth.getSession")
+	params.Set("api_key", getLastFMAPIKey())
+	params.Set("token", token)
+	params.Set("api_sig", signLastFM(params))
+
+	client := &LastFMClient{httpClient: &http.Client{Timeout: 15 * time.Second}}
+	var result struct {
+		Session struct {
+			Name string `json:"name"`
+			Key  string `json:"key"`
+		} `json:"session"`
+	}
+	if err := client.call(ctx, params, &result); err != nil {
+		return "", "", fmt.Errorf("failed to get Last.fm session: %w", err)
+	}
+	return result.Session.Name, result.Session.Key, nil
+}
+
+// --- LAST.FM FUNCTIONS ---
+
+// GetLastFMClient returns a Last.fm client for a user
+func GetLastFMClient(ctx context.Context, user db.User) (*LastFMClient, error) {
+	if user.LastFMUsername == "" || user.LastFMSessionKey == "" {
+		return nil, fmt.Errorf("no Last.fm account linked")
+	}
+	return &LastFMClient{
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		apiKey:     getLastFMAPIKey(),
+		username:   user.LastFMUsername,
+	}, nil
+}
+
+func (c *LastFMClient) call(ctx context.Context, params url.Values, out interface{}) error {
+	params.Set("format", "json")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", lastFMAPIURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create Last.fm request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Last.fm request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read Last.fm response: %w", err)
+	}
+
+	var apiErr struct {
+		Error   int    `json:"error"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != 0 {
+		if apiErr.Error == 29 {
+			return fmt.Errorf("Last.fm API returned 429: %s", apiErr.Message)
+		}
+		return fmt.Errorf("Last.fm API error %d: %s", apiErr.Error, apiErr.Message)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Last.fm API returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode Last.fm response: %w", err)
+	}
+	return nil
+}
+
+func (t lastFMTrack) toTrack() db.Track {
+	artist := t.Artist.Name
+	if artist == "" {
+		artist = t.Artist.Text
+	}
+	seconds, _ := strconv.Atoi(t.Duration)
+	return db.Track{
+		ID:            uuid.New(),
+		Title:         t.Name,
+		Artist:        artist,
+		Album:         t.Album.Text,
+		MusicBrainzID: t.MBID,
+		DurationMs:    seconds * 1000,
+		CreatedAt:     time.Now(),
+	}
+}
+
+// GetLastFMPlaylists lists the playlists that can be generated from the user's history
+func GetLastFMPlaylists(ctx context.Context, client *LastFMClient) ([]db.Playlist, error) {
+	playlists := []db.Playlist{}
+	for _, sourceID := range []string{"loved", "recent", "top:7day", "top:1month", "top:3month", "top:6month", "top:12month", "top:overall"} {
+		p, err := GetLastFMPlaylist(ctx, client, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		playlists = append(playlists, p)
+	}
+	return playlists, nil
+}
+
+// GetLastFMPlaylist describes a history playlist: "loved", "recent" or "top:<period>"
+func GetLastFMPlaylist(ctx context.Context, client *LastFMClient, sourceID string) (db.Playlist, error) {
+	playlist := db.Playlist{
+		ID:        uuid.New(),
+		Platform:  "lastfm",
+		SourceID:  sourceID,
+		IsPublic:  false,
+		CreatedAt: time.Now(),
+	}
+	switch {
+	case sourceID == "loved":
+		playlist.Title = "Last.fm Loved Tracks"
+		playlist.Description = fmt.Sprintf("Tracks loved by %s on Last.fm", client.username)
+	case sourceID == "recent":
+		playlist.Title = "Last.fm Recent Scrobbles"
+		playlist.Description = fmt.Sprintf("Recently scrobbled by %s on Last.fm", client.username)
+	case strings.HasPrefix(sourceID, "top:"):
+		label, ok := LastFMTopPeriods[strings.TrimPrefix(sourceID, "top:")]
+		if !ok {
+			return db.Playlist{}, fmt.Errorf("unknown Last.fm period: %s", strings.TrimPrefix(sourceID, "top:"))
+		}
+		playlist.Title = fmt.Sprintf("Last.fm Top Tracks (%s)", label)
+		playlist.Description = fmt.Sprintf("Most played by %s on Last.fm (%s)", client.username, label)
+	default:
+		return db.Playlist{}, fmt.Errorf("unknown Last.fm playlist: %s", sourceID)
+	}
+	return playlist, nil
+}
+
+// GetLastFMPlaylistTracks fetches the tracks of a history playlist
+func GetLastFMPlaylistTracks(ctx context.Context, client *LastFMClient, sourceID string) ([]db.Track, error) {
+	params := url.Values{}
+	params.Set("api_key", client.apiKey)
+	params.Set("user", client.username)
+
+	var listKey string
+	switch {
+	case sourceID == "loved":
+		params.Set("method", "user.getLovedTracks")
+		params.Set("limit", "200")
+		listKey = "lovedtracks"
+	case sourceID == "recent":
+		params.Set("method", "user.getRecentTracks")
+		params.Set("limit", "200")
+		listKey = "recenttracks"
+	case strings.HasPrefix(sourceID, "top:"):
+		period := strings.TrimPrefix(sourceID, "top:")
+		if _, ok := LastFMTopPeriods[period]; !ok {
+			return nil, fmt.Errorf("unknown Last.fm period: %s", period)
+		}
+		params.Set("method", "user.getTopTracks")
+		params.Set("period", period)
+		params.Set("limit", "100")
+		listKey = "toptracks"
+	default:
+		return nil, fmt.Errorf("unknown Last.fm playlist: %s", sourceID)
+	}
+
+	var tracks []db.Track
+	seen := make(map[string]bool)
+	for page := 1; ; page++ {
+		params.Set("page", strconv.Itoa(page))
+		var result map[string]lastFMTrackPage
+		if err := client.call(ctx, params, &result); err != nil {
+			return nil, fmt.Errorf("failed to get Last.fm tracks: %w", err)
+		}
+		list := result[listKey]
+		for _, t := range list.Track {
+			if t.Attr.NowPlaying == "true" {
+				continue
+			}
+			track := t.toTrack()
+			// Scrobble history repeats tracks; keep the first (most recent) play
+			key := strings.ToLower(track.Artist + "\x00" + track.Title)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			tracks = append(tracks, track)
+		}
+
+		totalPages, _ := strconv.Atoi(list.Attr.TotalPages)
+		// Top tracks and recent scrobbles are a single page; loved tracks are paged through
+		if sourceID != "loved" || page >= totalPages || len(tracks) >= lastFMMaxTracks {
+			break
+		}
+	}
+	return tracks, nil
+}
+
+// ImportLastFMPlaylist imports (or refreshes) a single history playlist to DB
+func ImportLastFMPlaylist(ctx context.Context, client *LastFMClient, user db.User, sourceID string) error {
+	playlist, err := GetLastFMPlaylist(ctx, client, sourceID)
+	if err != nil {
+		return err
+	}
+	tracks, err := GetLastFMPlaylistTracks(ctx, client, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch tracks: %w", err)
+	}
+	_, err = saveHistoryPlaylist(user, playlist, tracks)
+	return err
+}
+
+// ImportAllLastFMPlaylists imports loved tracks, recent scrobbles and monthly top tracks for a user
+func ImportAllLastFMPlaylists(ctx context.Context, user db.User) error {
+	client, err := GetLastFMClient(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	for _, sourceID := range []string{"loved", "recent", "top:1month"} {
+		if err := ImportLastFMPlaylist(ctx, client, user, sourceID); err != nil {
+			log.Printf("Failed to import Last.fm %s: %v", sourceID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,470 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+)
+
+// --- CONFIGURATION ---
+
+var listenBrainzAPIURL = "https://api.listenbrainz.org/1"
+
+// ListenBrainzRanges are the statistics ranges accepted by the stats API, used as "top:<range>" source IDs
+var ListenBrainzRanges = map[string]string{
+	"week":        "last week",
+	"month":       "last month",
+	"quarter":     "last quarter",
+	"half_yearly": "last 6 months",
+	"year":        "last year",
+	"all_time":    "all time",
+}
+
+const listenBrainzMaxTracks = 1000
+
+// ListenBrainzClient calls the ListenBrainz API with a user token
+type ListenBrainzClient struct {
+	httpClient *http.Client
+	token      string
+	username   string
+}
+
+type listenBrainzTrackMetadata struct {
+	ArtistName     string `json:"artist_name"`
+	TrackName      string `json:"track_name"`
+	ReleaseName    string `json:"release_name"`
+	AdditionalInfo struct {
+		RecordingMBID string `json:"recording_mbid"`
+		DurationMs    int    `json:"duration_ms"`
+		ISRC          string `json:"isrc"`
+	} `json:"additional_info"`
+	MBIDMapping *struct {
+		RecordingMBID string `json:"recording_mbid"`
+	} `json:"mbid_mapping"`
+}
+
+// --- LISTENBRAINZ FUNCTIONS ---
+
+// GetListenBrainzClient returns a ListenBrainz client for a user
+func GetListenBrainzClient(ctx context.Context, user db.User) (*ListenBrainzClient, error) {
+	if user.ListenBrainzToken == "" {
+		return nil, fmt.Errorf("no ListenBrainz token available")
+	}
+	return NewListenBrainzClient(user.ListenBrainzToken, user.ListenBrainzUsername), nil
+}
+
+// NewListenBrainzClient builds a client from a user token
+func NewListenBrainzClient(token, username string) *ListenBrainzClient {
+	return &ListenBrainzClient{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		token:      token,
+		username:   username,
+	}
+}
+
+func (c *ListenBrainzClient) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode ListenBrainz request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, listenBrainzAPIURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create ListenBrainz request: %w", err)
+	}
+	req.Header.Set("Authorization", "Token "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("ListenBrainz request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("ListenBrainz API returned %d: %s", resp.StatusCode, string(respBody))
+	}
+	// Statistics that have not been calculated yet come back as 204
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode ListenBrainz response: %w", err)
+	}
+	return nil
+}
+
+// ValidateListenBrainzToken checks a user token and returns the username it belongs to
+func ValidateListenBrainzToken(ctx context.Context, token string) (string, error) {
+	client := NewListenBrainzClient(token, "")
+	var result struct {
+		Valid    bool   `json:"valid"`
+		UserName string `json:"user_name"`
+		Message  string `json:"message"`
+	}
+	if err := client.do(ctx, "GET", "/validate-token", nil, &result); err != nil {
+		return "", err
+	}
+	if !result.Valid {
+		return "", fmt.Errorf("invalid ListenBrainz token: %s", result.Message)
+	}
+	return result.UserName, nil
+}
+
+func (m listenBrainzTrackMetadata) toTrack() db.Track {
+	mbid := m.AdditionalInfo.RecordingMBID
+	if mbid == "" && m.MBIDMapping != nil {
+		mbid = m.MBIDMapping.RecordingMBID
+	}
+	return db.Track{
+		ID:            uuid.New(),
+		Title:         m.TrackName,
+		Artist:        m.ArtistName,
+		Album:         m.ReleaseName,
+		MusicBrainzID: mbid,
+		ISRC:          m.AdditionalInfo.ISRC,
+		DurationMs:    m.AdditionalInfo.DurationMs,
+		CreatedAt:     time.Now(),
+	}
+}
+
+// listenBrainzPlaylistMBID extracts the MBID from a playlist identifier URL
+func listenBrainzPlaylistMBID(identifier string) string {
+	return identifier[strings.LastIndex(identifier, "/")+1:]
+}
+
+// GetListenBrainzPlaylists lists the history playlists plus the user's own and
+// "created for" (e.g. Weekly Jams) ListenBrainz playlists
+func GetListenBrainzPlaylists(ctx context.Context, client *ListenBrainzClient) ([]db.Playlist, error) {
+	playlists := []db.Playlist{}
+	for _, sourceID := range []string{"loved", "recent", "top:week", "top:month", "top:quarter", "top:half_yearly", "top:year", "top:all_time"} {
+		p, err := GetListenBrainzPlaylist(ctx, client, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		playlists = append(playlists, p)
+	}
+
+	for _, path := range []string{"/user/%s/playlists", "/user/%s/playlists/createdfor"} {
+		var result struct {
+			Playlists []JSPFDocument `json:"playlists"`
+		}
+		if err := client.do(ctx, "GET", fmt.Sprintf(path, url.PathEscape(client.username))+"?count=100", nil, &result); err != nil {
+			return nil, fmt.Errorf("failed to get ListenBrainz playlists: %w", err)
+		}
+		for _, doc := range result.Playlists {
+			p, _ := ParseJSPF(doc, "listenbrainz", listenBrainzPlaylistMBID(doc.Playlist.Identifier))
+			playlists = append(playlists, p)
+		}
+	}
+	return playlists, nil
+}
+
+// GetListenBrainzPlaylist describes a playlist: "loved", "recent", "top:<range>" or a playlist MBID
+func GetListenBrainzPlaylist(ctx context.Context, client *ListenBrainzClient, sourceID string) (db.Playlist, error) {
+	playlist := db.Playlist{
+		ID:        uuid.New(),
+		Platform:  "listenbrainz",
+		SourceID:  sourceID,
+		IsPublic:  false,
+		CreatedAt: time.Now(),
+	}
+	switch {
+	case sourceID == "loved":
+		playlist.Title = "ListenBrainz Loved Tracks"
+		playlist.Description = fmt.Sprintf("Tracks loved by %s on ListenBrainz", client.username)
+	case sourceID == "recent":
+		playlist.Title = "ListenBrainz Recent Listens"
+		playlist.Description = fmt.Sprintf("Recently listened to by %s on ListenBrainz", client.username)
+	case strings.HasPrefix(sourceID, "top:"):
+		label, ok := ListenBrainzRanges[strings.TrimPrefix(sourceID, "top:")]
+		if !ok {
+			return db.Playlist{}, fmt.Errorf("unknown ListenBrainz range: %s", strings.TrimPrefix(sourceID, "top:"))
+		}
+		playlist.Title = fmt.Sprintf("ListenBrainz Top Tracks (%s)", label)
+		playlist.Description = fmt.Sprintf("Most played by %s on ListenBrainz (%s)", client.username, label)
+	default:
+		doc, err := getListenBrainzJSPF(ctx, client, sourceID)
+		if err != nil {
+			return db.Playlist{}, err
+		}
+		playlist, _ = ParseJSPF(doc, "listenbrainz", sourceID)
+	}
+	return playlist, nil
+}
+
+// getListenBrainzJSPF fetches a ListenBrainz playlist as JSPF
+func getListenBrainzJSPF(ctx context.Context, client *ListenBrainzClient, playlistMBID string) (JSPFDocument, error) {
+	var doc JSPFDocument
+	if err := client.do(ctx, "GET", "/playlist/"+url.PathEscape(playlistMBID), nil, &doc); err != nil {
+		return doc, fmt.Errorf("failed to fetch ListenBrainz playlist: %w", err)
+	}
+	return doc, nil
+}
+
+// GetListenBrainzPlaylistTracks fetches the tracks of a history or JSPF playlist
+func GetListenBrainzPlaylistTracks(ctx context.Context, client *ListenBrainzClient, sourceID string) ([]db.Track, error) {
+	user := url.PathEscape(client.username)
+	var tracks []db.Track
+
+	switch {
+	case sourceID == "loved":
+		for offset := 0; offset < listenBrainzMaxTracks; offset += 100 {
+			var result struct {
+				Feedback []struct {
+					RecordingMBID string                     `json:"recording_mbid"`
+					TrackMetadata *listenBrainzTrackMetadata `json:"track_metadata"`
+				} `json:"feedback"`
+				TotalCount int `json:"total_count"`
+			}
+			path := fmt.Sprintf("/feedback/user/%s/get-feedback?score=1&metadata=true&count=100&offset=%d", user, offset)
+			if err := client.do(ctx, "GET", path, nil, &result); err != nil {
+				return nil, fmt.Errorf("failed to get ListenBrainz loved tracks: %w", err)
+			}
+			for _, f := range result.Feedback {
+				// Feedback for recordings without metadata has nothing to match on
+				if f.TrackMetadata == nil {
+					continue
+				}
+				track := f.TrackMetadata.toTrack()
+				track.MusicBrainzID = f.RecordingMBID
+				tracks = append(tracks, track)
+			}
+			if len(result.Feedback) == 0 || offset+100 >= result.TotalCount {
+				break
+			}
+		}
+	case sourceID == "recent":
+		var result struct {
+			Payload struct {
+				Listens []struct {
+					TrackMetadata listenBrainzTrackMetadata `json:"track_metadata"`
+				} `json:"listens"`
+			} `json:"payload"`
+		}
+		if err := client.do(ctx, "GET", fmt.Sprintf("/user/%s/listens?count=100", user), nil, &result); err != nil {
+			return nil, fmt.Errorf("failed to get ListenBrainz listens: %w", err)
+		}
+		seen := make(map[string]bool)
+		for _, l := range result.Payload.Listens {
+			track := l.TrackMetadata.toTrack()
+			key := strings.ToLower(track.Artist + "\x00" + track.Title)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			tracks = append(tracks, track)
+		}
+	case strings.HasPrefix(sourceID, "top:"):
+		statsRange := strings.TrimPrefix(sourceID, "top:")
+		if _, ok := ListenBrainzRanges[statsRange]; !ok {
+			return nil, fmt.Errorf("unknown ListenBrainz range: %s", statsRange)
+		}
+		var result struct {
+			Payload struct {
+				Recordings []struct {
+					TrackName     string `json:"track_name"`
+					ArtistName    string `json:"artist_name"`
+					ReleaseName   string `json:"release_name"`
+					RecordingMBID string `json:"recording_mbid"`
+				} `json:"recordings"`
+			} `json:"payload"`
+		}
+		path := fmt.Sprintf("/stats/user/%s/recordings?range=%s&count=100", user, statsRange)
+		if err := client.do(ctx, "GET", path, nil, &result); err != nil {
+			return nil, fmt.Errorf("failed to get ListenBrainz top tracks: %w", err)
+		}
+		for _, r := range result.Payload.Recordings {
+			tracks = append(tracks, db.Track{
+				ID:            uuid.New(),
+				Title:         r.TrackName,
+				Artist:        r.ArtistName,
+				Album:         r.ReleaseName,
+				MusicBrainzID: r.RecordingMBID,
+				CreatedAt:     time.Now(),
+			})
+		}
+	default:
+		doc, err := getListenBrainzJSPF(ctx, client, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		_, tracks = ParseJSPF(doc, "listenbrainz", sourceID)
+	}
+	return tracks, nil
+}
+
+// SearchListenBrainzRecording resolves a recording MBID from artist and title
+func SearchListenBrainzRecording(ctx context.Context, client *ListenBrainzClient, title, artist string) (string, error) {
+	params := url.Values{}
+	params.Set("artist_name", artist)
+	params.Set("recording_name", title)
+	var result struct {
+		RecordingMBID string `json:"recording_mbid"`
+	}
+	if err := client.do(ctx, "GET", "/metadata/lookup/?"+params.Encode(), nil, &result); err != nil {
+		return "", fmt.Errorf("failed to look up recording: %w", err)
+	}
+	return result.RecordingMBID, nil
+}
+
+// CreateListenBrainzPlaylist creates a private ListenBrainz playlist and returns its MBID
+func CreateListenBrainzPlaylist(ctx context.Context, client *ListenBrainzClient, title, description string) (string, error) {
+	body := map[string]interface{}{
+		"playlist": map[string]interface{}{
+			"title":      title,
+			"annotation": description,
+			"track":      []interface{}{},
+			"extension": map[string]interface{}{
+				jspfPlaylistExtension: map[string]interface{}{"public": false},
+			},
+		},
+	}
+	var result struct {
+		PlaylistMBID string `json:"playlist_mbid"`
+	}
+	if err := client.do(ctx, "POST", "/playlist/create", body, &result); err != nil {
+		return "", fmt.Errorf("failed to create ListenBrainz playlist: %w", err)
+	}
+	return result.PlaylistMBID, nil
+}
+
+// AddListenBrainzPlaylistTracks appends recordings to a playlist
+func AddListenBrainzPlaylistTracks(ctx context.Context, client *ListenBrainzClient, playlistMBID string, recordingMBIDs ...string) error {
+	var items []map[string]string
+	for _, mbid := range recordingMBIDs {
+		items = append(items, map[string]string{"identifier": musicBrainzRecordingURL + mbid})
+	}
+	body := map[string]interface{}{
+		"playlist": map[string]interface{}{"track": items},
+	}
+	if err := client.do(ctx, "POST", fmt.Sprintf("/playlist/%s/item/add", url.PathEscape(playlistMBID)), body, nil); err != nil {
+		return fmt.Errorf("failed to add tracks to ListenBrainz playlist: %w", err)
+	}
+	return nil
+}
+
+// ImportToListenBrainz exports a playlist to ListenBrainz
+func ImportToListenBrainz(ctx context.Context, client *ListenBrainzClient, playlist db.Playlist, tracks []db.Track) (string, error) {
+	return syncToListenBrainz(ctx, client, playlist, tracks, uuid.Nil)
+}
+
+// syncToListenBrainz resolves recording MBIDs and appends them in chunks. ListenBrainz
+// playlists can only hold tracks known to MusicBrainz.
+func syncToListenBrainz(ctx context.Context, client *ListenBrainzClient, playlist db.Playlist, tracks []db.Track, jobID uuid.UUID) (string, error) {
+	lbPlaylistID := loadSyncDestination(jobID, "listenbrainz")
+	if lbPlaylistID == "" {
+		var err error
+		lbPlaylistID, err = CreateListenBrainzPlaylist(ctx, client, playlist.Title, playlist.Description)
+		if err != nil {
+			return "", err
+		}
+		SaveSyncDestination(jobID, "listenbrainz", lbPlaylistID)
+	}
+
+	checkpoints, err := loadSyncCheckpoints(jobID, "listenbrainz")
+	if err != nil {
+		return lbPlaylistID, err
+	}
+
+	var pending []db.Track
+	for _, t := range tracks {
+		if checkpoints.isDone(t.ID) {
+			continue
+		}
+		if t.MusicBrainzID == "" {
+			mbid, err := SearchListenBrainzRecording(ctx, client, t.Title, t.Artist)
+			if err != nil {
+				if strings.Contains(err.Error(), "429") {
+					return lbPlaylistID, fmt.Errorf("ListenBrainz rate limit reached: %w", err)
+				}
+				log.Printf("   ⚠️ ListenBrainz lookup failed for %s - %s: %v", t.Title, t.Artist, err)
+				checkpoints.record(t.ID, "failed", "")
+				continue
+			}
+			if mbid == "" {
+				checkpoints.record(t.ID, "not_found", "")
+				continue
+			}
+			t.MusicBrainzID = mbid
+		}
+		pending = append(pending, t)
+	}
+
+	chunkSize := 100
+	for i := 0; i < len(pending); i += chunkSize {
+		end := i + chunkSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		var mbids []string
+		for _, t := range pending[i:end] {
+			mbids = append(mbids, t.MusicBrainzID)
+		}
+		if err := AddListenBrainzPlaylistTracks(ctx, client, lbPlaylistID, mbids...); err != nil {
+			return lbPlaylistID, err
+		}
+		for _, t := range pending[i:end] {
+			checkpoints.record(t.ID, "added", t.MusicBrainzID)
+		}
+	}
+	log.Printf("🏁 ListenBrainz Sync Finished. Added %d/%d songs.", len(pending), len(tracks))
+	return lbPlaylistID, nil
+}
+
+// ImportListenBrainzPlaylist imports a history playlist or a JSPF playlist to DB.
+// Re-importing refreshes the tracks in place.
+func ImportListenBrainzPlaylist(ctx context.Context, client *ListenBrainzClient, user db.User, sourceID string) error {
+	playlist, err := GetListenBrainzPlaylist(ctx, client, sourceID)
+	if err != nil {
+		return err
+	}
+	tracks, err := GetListenBrainzPlaylistTracks(ctx, client, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch tracks: %w", err)
+	}
+	_, err = saveHistoryPlaylist(user, playlist, tracks)
+	return err
+}
+
+// ImportAllListenBrainzPlaylists imports loved tracks, recent listens, monthly top tracks
+// and the user's ListenBrainz playlists
+func ImportAllListenBrainzPlaylists(ctx context.Context, user db.User) error {
+	client, err := GetListenBrainzClient(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	playlists, err := GetListenBrainzPlaylists(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range playlists {
+		if strings.HasPrefix(p.SourceID, "top:") && p.SourceID != "top:month" {
+			continue
+		}
+		if err := ImportListenBrainzPlaylist(ctx, client, user, p.SourceID); err != nil {
+			log.Printf("Failed to import ListenBrainz %s: %v", p.Title, err)
+		}
+	}
+	return nil
+}
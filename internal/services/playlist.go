@@ -2,29 +2,35 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"time"
+	"slices"
 
 	"EchoBridge/db"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // --- MAIN SYNC LOGIC ---
 
-// SyncPlaylist syncs a playlist to specified platforms
-func SyncPlaylist(ctx context.Context, user db.User, playlistID uuid.UUID, platforms []string) (map[string]string, error) {
+// SyncPlaylist syncs a playlist to specified platforms.
+// When jobID is set, progress is checkpointed per track and a later call with the
+// same job resumes into the destination playlists created by the earlier run.
+func SyncPlaylist(ctx context.Context, user db.User, playlistID uuid.UUID, platforms []string, jobID uuid.UUID) (map[string]string, error) {
 	log.Printf("🚀 Starting Sync for Playlist ID: %s", playlistID)
 
-	var playlist db.Playlist
-	if err := db.DB.Where("id = ? AND owner_id = ?", playlistID, user.ID).First(&playlist).Error; err != nil {
-		return nil, fmt.Errorf("playlist not found: %w", err)
+	// Any member may sync a shared playlist into their own linked accounts
+	playlist, _, err := LoadPlaylistWithRole(playlistID, user.ID, PlaylistRoleViewer)
+	if err != nil {
+		return nil, err
 	}
 
-	var tracks []db.Track
-	if err := db.DB.Where("playlist_id = ?", playlistID).Find(&tracks).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch tracks: %w", err)
+	tracks, err := PlaylistTracks(playlistID)
+	if err != nil {
+		return nil, err
 	}
 
 	log.Printf("📊 Database Check: Found %d tracks for playlist '%s'", len(tracks), playlist.Title)
@@ -32,6 +38,9 @@ func SyncPlaylist(ctx context.Context, user db.User, playlistID uuid.UUID, platf
 		log.Println("⚠️ WARNING: No tracks found in DB! Ensure the Import step worked correctly.")
 		return nil, nil
 	}
+	if slices.ContainsFunc(platforms, func(p string) bool { return p != "deezer" }) {
+		fillDeezerISRCs(ctx, tracks)
+	}
 
 	result := make(map[string]string)
 	for _, platform := range platforms {
@@ -43,7 +52,7 @@ func SyncPlaylist(ctx context.Context, user db.User, playlistID uuid.UUID, platf
 				if err != nil {
 					return nil, fmt.Errorf("failed to get Spotify client: %w", err)
 				}
-				spotifyID, err := ImportToSpotify(ctx, client, user, playlist, tracks)
+				spotifyID, err := syncToSpotify(ctx, client, user, playlist, tracks, jobID)
 				if err != nil {
 					return nil, fmt.Errorf("failed to sync to Spotify: %w", err)
 				}
@@ -56,33 +65,140 @@ func SyncPlaylist(ctx context.Context, user db.User, playlistID uuid.UUID, platf
 				if err != nil {
 					return nil, fmt.Errorf("failed to get YouTube client: %w", err)
 				}
-
-				log.Println("🔨 Creating YouTube Playlist...")
-				ytPlaylistID, err := CreateYouTubePlaylist(ctx, client, playlist.Title, playlist.Description)
+				ytPlaylistID, err := syncToYouTube(ctx, client, playlist, tracks, jobID)
 				if err != nil {
-					return nil, fmt.Errorf("failed to create YouTube playlist: %w", err)
+					return nil, fmt.Errorf("failed to sync to YouTube: %w", err)
 				}
-
-				successCount := 0
-				for _, track := range tracks {
-					videoID, err := SearchYouTubeVideo(ctx, client, track.Title, track.Artist)
-					if err != nil || videoID == "" {
-						log.Printf("   ⚠️ Failed to find track: %s - %s", track.Title, track.Artist)
-						continue
-					}
-					if err := AddYouTubePlaylistItem(ctx, client, ytPlaylistID, videoID); err != nil {
-						log.Printf("   ❌ Failed to add track: %s", track.Title)
-					} else {
-						log.Printf("   ✅ Added: %s", track.Title)
-						successCount++
-					}
-					// Sleep to respect API rate limits
-					time.Sleep(200 * time.Millisecond)
-				}
-				log.Printf("🏁 YouTube Sync Finished. Added %d/%d songs.", successCount, len(tracks))
 				result["youtube"] = ytPlaylistID
 			}
+		case "applemusic":
+			if user.AppleMusicToken != "" {
+				log.Println("🍎 Starting Apple Music Sync...")
+				client, err := GetAppleMusicClient(ctx, user)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get Apple Music client: %w", err)
+				}
+				amPlaylistID, err := syncToAppleMusic(ctx, client, playlist, tracks, jobID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to sync to Apple Music: %w", err)
+				}
+				result["applemusic"] = amPlaylistID
+			}
+		case "deezer":
+			if user.DeezerToken != "" {
+				log.Println("🎧 Starting Deezer Sync...")
+				client, err := GetDeezerClient(ctx, user)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get Deezer client: %w", err)
+				}
+				dzPlaylistID, err := syncToDeezer(ctx, client, playlist, tracks, jobID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to sync to Deezer: %w", err)
+				}
+				result["deezer"] = dzPlaylistID
+			}
+		case "tidal":
+			if user.TidalToken != "" {
+				log.Println("🌊 Starting TIDAL Sync...")
+				client, err := GetTidalClient(ctx, user)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get TIDAL client: %w", err)
+				}
+				tdPlaylistID, err := syncToTidal(ctx, client, playlist, tracks, jobID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to sync to TIDAL: %w", err)
+				}
+				result["tidal"] = tdPlaylistID
+			}
+		case "subsonic":
+			if user.SubsonicToken != "" {
+				log.Println("🗄️ Starting Subsonic Sync...")
+				client, err := GetSubsonicClient(ctx, user)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get Subsonic client: %w", err)
+				}
+				ssPlaylistID, err := syncToSubsonic(ctx, client, playlist, tracks, jobID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to sync to Subsonic: %w", err)
+				}
+				result["subsonic"] = ssPlaylistID
+			}
+		case "jellyfin":
+			if user.JellyfinToken != "" {
+				log.Println("🪼 Starting Jellyfin Sync...")
+				client, err := GetJellyfinClient(ctx, user)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get Jellyfin client: %w", err)
+				}
+				jfPlaylistID, err := syncToJellyfin(ctx, client, playlist, tracks, jobID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to sync to Jellyfin: %w", err)
+				}
+				result["jellyfin"] = jfPlaylistID
+			}
+		case "soundcloud":
+			if user.SoundCloudToken != "" {
+				log.Println("☁️ Starting SoundCloud Sync...")
+				client, err := GetSoundCloudClient(ctx, user)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get SoundCloud client: %w", err)
+				}
+				scPlaylistID, err := syncToSoundCloud(ctx, client, playlist, tracks, jobID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to sync to SoundCloud: %w", err)
+				}
+				result["soundcloud"] = scPlaylistID
+			}
+		case "listenbrainz":
+			if user.ListenBrainzToken != "" {
+				log.Println("🧠 Starting ListenBrainz Sync...")
+				client, err := GetListenBrainzClient(ctx, user)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get ListenBrainz client: %w", err)
+				}
+				lbPlaylistID, err := syncToListenBrainz(ctx, client, playlist, tracks, jobID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to sync to ListenBrainz: %w", err)
+				}
+				result["listenbrainz"] = lbPlaylistID
+			}
 		}
 	}
 	return result, nil
 }
+
+// saveHistoryPlaylist stores a playlist generated from listening history. Unlike platform
+// playlists these change over time, so re-importing replaces the tracks of the existing copy;
+// songs still on the list keep their track rows.
+func saveHistoryPlaylist(user db.User, playlist db.Playlist, tracks []db.Track) (db.Playlist, error) {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var existing db.Playlist
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("owner_id = ? AND source_id = ? AND platform = ?", user.ID, playlist.SourceID, playlist.Platform).
+			First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to look up playlist: %w", err)
+		}
+
+		var current []db.Track
+		if err == nil {
+			playlist = existing
+			if err := tx.Where("playlist_id = ?", playlist.ID).Order(TrackOrder).Find(&current).Error; err != nil {
+				return fmt.Errorf("failed to fetch tracks: %w", err)
+			}
+		} else {
+			playlist.OwnerID = user.ID
+			if err := tx.Create(&playlist).Error; err != nil {
+				return fmt.Errorf("failed to create playlist in DB: %w", err)
+			}
+		}
+
+		_, err = replacePlaylistTracks(tx, playlist.ID, current, tracks)
+		return err
+	})
+	if err != nil {
+		return playlist, err
+	}
+	RecordImportVersion(playlist.ID, user.ID)
+	return playlist, nil
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// --- PLAYLIST BROWSING ---
+
+// Orders for browsing playlists
+const (
+	PlaylistSortNewest  = "newest"  // most recently created first
+	PlaylistSortTitle   = "title"   // alphabetical
+	PlaylistSortTracks  = "tracks"  // most tracks first
+	PlaylistSortPopular = "popular" // most used first, see popularityExpr
+)
+
+// PlaylistSorts lists the accepted sort orders; the first is the default
+var PlaylistSorts = []string{PlaylistSortNewest, PlaylistSortTitle, PlaylistSortTracks, PlaylistSortPopular}
+
+const (
+	DefaultPlaylistPageSize = 20
+	MaxPlaylistPageSize     = 100
+)
+
+var (
+	ErrInvalidPlaylistSort = errors.New("unknown sort order")
+	ErrInvalidCursor       = errors.New("invalid or expired cursor")
+)
+
+const trackCountExpr = "(SELECT COUNT(*) FROM tracks WHERE tracks.playlist_id = playlists.id)"
+
+// popularityExpr weighs views, imports (each worth importWeight views) and accepted members
+var popularityExpr = fmt.Sprintf("(playlists.view_count + %d * playlists.import_count"+
+	" + (SELECT COUNT(*) FROM playlist_members WHERE playlist_members.playlist_id = playlists.id AND playlist_members.status = 'accepted'))",
+	importWeight)
+
+// PlaylistQuery selects a page of playlists. Platforms and Categories match any of their
+// values; Search is a web-style query ("quoted phrase", -exclude, or) over playlist titles and
+// descriptions and the titles and artists of their tracks.
+type PlaylistQuery struct {
+	Sort       string
+	Platforms  []string
+	Categories []string
+	Search     string
+	Cursor     string
+	Limit      int
+}
+
+// PlaylistPage is one page of playlists with their track counts and popularity.
+// NextCursor is empty on the last page.
+type PlaylistPage struct {
+	Playlists   []db.Playlist
+	TrackCounts map[uuid.UUID]int64
+	Popularity  map[uuid.UUID]int64
+	NextCursor  string
+}
+
+// playlistCursor is the position after the last playlist of a page: its sort value and ID
+type playlistCursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v"`
+	ID    uuid.UUID       `json:"id"`
+}
+
+// sortColumn returns the SQL sort expression and whether the order is descending
+func sortColumn(sort string) (string, bool) {
+	switch sort {
+	case PlaylistSortTitle:
+		return "playlists.title", false
+	case PlaylistSortTracks:
+		return trackCountExpr, true
+	case PlaylistSortPopular:
+		return popularityExpr, true
+	}
+	return "playlists.created_at", true
+}
+
+// decodeCursorValue returns the sort value stored in a cursor, typed for the sort order
+func decodeCursorValue(cursor playlistCursor) (interface{}, error) {
+	var err error
+	switch cursor.Sort {
+	case PlaylistSortTitle:
+		var v string
+		err = json.Unmarshal(cursor.Value, &v)
+		return v, err
+	case PlaylistSortTracks, PlaylistSortPopular:
+		var v int64
+		err = json.Unmarshal(cursor.Value, &v)
+		return v, err
+	}
+	var v time.Time
+	err = json.Unmarshal(cursor.Value, &v)
+	return v, err
+}
+
+func encodePlaylistCursor(sort string, value interface{}, id uuid.UUID) string {
+	raw, _ := json.Marshal(value)
+	data, _ := json.Marshal(playlistCursor{Sort: sort, Value: raw, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePlaylistCursor returns the sort value and playlist ID of a cursor made for the given sort
+func decodePlaylistCursor(encoded, sort string) (interface{}, uuid.UUID, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, uuid.Nil, ErrInvalidCursor
+	}
+	var cursor playlistCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort != sort {
+		return nil, uuid.Nil, ErrInvalidCursor
+	}
+	value, err := decodeCursorValue(cursor)
+	if err != nil {
+		return nil, uuid.Nil, ErrInvalidCursor
+	}
+	return value, cursor.ID, nil
+}
+
+// BrowsePlaylists returns a page of the playlists selected by scope, e.g. the public ones.
+// Pages are keyed by the last playlist's sort value and ID, so they stay stable while
+// playlists are added.
+func BrowsePlaylists(scope *gorm.DB, q PlaylistQuery) (PlaylistPage, error) {
+	page := PlaylistPage{}
+	if q.Sort == "" {
+		q.Sort = PlaylistSorts[0]
+	}
+	if !slices.Contains(PlaylistSorts, q.Sort) {
+		return page, ErrInvalidPlaylistSort
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultPlaylistPageSize
+	}
+	q.Limit = min(q.Limit, MaxPlaylistPageSize)
+
+	query := scope.Session(&gorm.Session{}).Model(&db.Playlist{})
+	if len(q.Platforms) > 0 {
+		query = query.Where("playlists.platform IN ?", q.Platforms)
+	}
+	if len(q.Categories) > 0 {
+		categories := make([]string, len(q.Categories))
+		for i, c := range q.Categories {
+			categories[i] = strings.ToLower(c)
+		}
+		query = query.Where("LOWER(playlists.category) IN ?", categories)
+	}
+	if search := strings.TrimSpace(q.Search); search != "" {
+		query = query.Where(
+			db.PlaylistSearchDocument+" @@ websearch_to_tsquery('simple', ?)"+
+				" OR EXISTS (SELECT 1 FROM tracks WHERE tracks.playlist_id = playlists.id AND "+db.TrackSearchDocument+" @@ websearch_to_tsquery('simple', ?))",
+			search, search,
+		)
+	}
+
+	column, desc := sortColumn(q.Sort)
+	direction, compare := "ASC", ">"
+	if desc {
+		direction, compare = "DESC", "<"
+	}
+	if q.Cursor != "" {
+		value, id, err := decodePlaylistCursor(q.Cursor, q.Sort)
+		if err != nil {
+			return page, err
+		}
+		query = query.Where(fmt.Sprintf("(%s, playlists.id) %s (?, ?)", column, compare), value, id)
+	}
+
+	// One extra row tells whether there is a next page
+	var playlists []db.Playlist
+	err := query.Order(fmt.Sprintf("%s %s, playlists.id %s", column, direction, direction)).
+		Limit(q.Limit + 1).Find(&playlists).Error
+	if err != nil {
+		return page, fmt.Errorf("failed to fetch playlists: %w", err)
+	}
+	hasMore := len(playlists) > q.Limit
+	if hasMore {
+		playlists = playlists[:q.Limit]
+	}
+
+	page.Playlists = playlists
+	page.TrackCounts, page.Popularity, err = playlistCounts(playlists)
+	if err != nil {
+		return page, err
+	}
+
+	if hasMore {
+		last := playlists[len(playlists)-1]
+		var value interface{}
+		switch q.Sort {
+		case PlaylistSortTitle:
+			value = last.Title
+		case PlaylistSortTracks:
+			value = page.TrackCounts[last.ID]
+		case PlaylistSortPopular:
+			value = page.Popularity[last.ID]
+		default:
+			value = last.CreatedAt
+		}
+		page.NextCursor = encodePlaylistCursor(q.Sort, value, last.ID)
+	}
+	return page, nil
+}
+
+// playlistCounts returns the track count and popularity of each playlist
+func playlistCounts(playlists []db.Playlist) (map[uuid.UUID]int64, map[uuid.UUID]int64, error) {
+	tracks := make(map[uuid.UUID]int64, len(playlists))
+	popularity := make(map[uuid.UUID]int64, len(playlists))
+	if len(playlists) == 0 {
+		return tracks, popularity, nil
+	}
+	ids := make([]uuid.UUID, len(playlists))
+	for i, p := range playlists {
+		ids[i] = p.ID
+	}
+
+	var rows []struct {
+		ID         uuid.UUID
+		TrackCount int64
+		Popularity int64
+	}
+	err := db.DB.Model(&db.Playlist{}).
+		Select("playlists.id, "+trackCountExpr+" AS track_count, "+popularityExpr+" AS popularity").
+		Where("playlists.id IN ?", ids).Scan(&rows).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to count tracks: %w", err)
+	}
+	for _, r := range rows {
+		tracks[r.ID] = r.TrackCount
+		popularity[r.ID] = r.Popularity
+	}
+	return tracks, popularity, nil
+}
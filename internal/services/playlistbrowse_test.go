@@ -0,0 +1,62 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPlaylistCursorRoundTrip(t *testing.T) {
+	id := uuid.New()
+	created := time.Date(2025, 3, 14, 15, 9, 26, 535897932, time.UTC)
+	tests := []struct {
+		sort  string
+		value interface{}
+	}{
+		{PlaylistSortNewest, created},
+		{PlaylistSortTitle, `Road "trip", vol. 2`},
+		{PlaylistSortTracks, int64(42)},
+		{PlaylistSortPopular, int64(1 << 40)},
+		{feedCursorSort, created},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			value, gotID, err := decodePlaylistCursor(encodePlaylistCursor(tt.sort, tt.value, id), tt.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotID != id {
+				t.Errorf("id = %s, want %s", gotID, id)
+			}
+			if want, ok := tt.value.(time.Time); ok {
+				if got, ok := value.(time.Time); !ok || !got.Equal(want) {
+					t.Errorf("value = %v, want %v", value, want)
+				}
+			} else if value != tt.value {
+				t.Errorf("value = %#v, want %#v", value, tt.value)
+			}
+		})
+	}
+}
+
+func TestDecodePlaylistCursorRejects(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name, cursor, sort string
+	}{
+		{"other sort order", encodePlaylistCursor(PlaylistSortTitle, "a", id), PlaylistSortNewest},
+		{"wrong value type", encodePlaylistCursor(PlaylistSortTracks, "many", id), PlaylistSortTracks},
+		{"not base64", "not a cursor!", PlaylistSortNewest},
+		{"not JSON", base64.RawURLEncoding.EncodeToString([]byte("{")), PlaylistSortNewest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := decodePlaylistCursor(tt.cursor, tt.sort); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("got %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
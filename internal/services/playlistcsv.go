@@ -0,0 +1,330 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+)
+
+// --- CSV PLAYLIST IMPORT ---
+
+// CSVColumnMapping names the CSV header used for each track field. Empty fields are not imported.
+type CSVColumnMapping struct {
+	Title      string `json:"title"`
+	Artist     string `json:"artist"`
+	Album      string `json:"album"`
+	ISRC       string `json:"isrc"`
+	Duration   string `json:"duration"`
+	SpotifyURI string `json:"spotify_uri"`
+	Playlist   string `json:"playlist"` // playlist name column, used for the title
+
+	MusicBrainzID string `json:"musicbrainz_id"`
+	YouTubeID     string `json:"youtube_id"`
+	AppleMusicID  string `json:"applemusic_id"`
+	DeezerID      string `json:"deezer_id"`
+	TidalID       string `json:"tidal_id"`
+	SoundCloudURL string `json:"soundcloud_url"`
+}
+
+// csvLayout is a known export format, recognised by its signature headers
+type csvLayout struct {
+	Name      string
+	Signature []string
+	Mapping   CSVColumnMapping
+}
+
+// csvLayouts are tried in order; the first whose signature headers are all present wins.
+// Headers are compared case-insensitively.
+var csvLayouts = []csvLayout{
+	{
+		Name:      "exportify",
+		Signature: []string{"track uri", "track name"},
+		Mapping: CSVColumnMapping{
+			Title:      "track name",
+			Artist:     "artist name(s)",
+			Album:      "album name",
+			ISRC:       "isrc",
+			Duration:   "track duration (ms)|duration (ms)",
+			SpotifyURI: "track uri",
+		},
+	},
+	{
+		Name:      "tunemymusic",
+		Signature: []string{"track name", "artist name"},
+		Mapping: CSVColumnMapping{
+			Title:      "track name",
+			Artist:     "artist name",
+			Album:      "album",
+			ISRC:       "isrc",
+			SpotifyURI: "spotify - id",
+			Playlist:   "playlist name",
+		},
+	},
+	{
+		Name:      "echobridge",
+		Signature: []string{"title", "artist", "spotify_id"},
+		Mapping: CSVColumnMapping{
+			Title:         "title",
+			Artist:        "artist",
+			Album:         "album",
+			ISRC:          "isrc",
+			Duration:      "duration_ms",
+			SpotifyURI:    "spotify_id",
+			MusicBrainzID: "musicbrainz_id",
+			YouTubeID:     "youtube_id",
+			AppleMusicID:  "applemusic_id",
+			DeezerID:      "deezer_id",
+			TidalID:       "tidal_id",
+			SoundCloudURL: "soundcloud_url",
+		},
+	},
+	{
+		Name:      "soundiiz",
+		Signature: []string{"title", "artist"},
+		Mapping: CSVColumnMapping{
+			Title:    "title",
+			Artist:   "artist",
+			Album:    "album",
+			ISRC:     "isrc",
+			Playlist: "playlist",
+		},
+	},
+}
+
+// CSVRowError describes a row that could not be imported. Row is 1-based and counts the header.
+type CSVRowError struct {
+	Row    int    `json:"row"`
+	Reason string `json:"reason"`
+}
+
+// CSVLayoutError is returned when no known layout matches and no mapping was given
+type CSVLayoutError struct {
+	Headers []string
+}
+
+func (e *CSVLayoutError) Error() string {
+	return "unrecognised CSV layout; provide a column mapping"
+}
+
+// CSVImportResult is the outcome of parsing a playlist CSV
+type CSVImportResult struct {
+	Playlist  db.Playlist
+	Tracks    []db.Track
+	Format    string
+	RowErrors []CSVRowError
+}
+
+// ParsePlaylistCSV reads a playlist CSV. The layout is auto-detected unless mapping is given.
+func ParsePlaylistCSV(filename string, r io.Reader, mapping *CSVColumnMapping) (CSVImportResult, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxPlaylistFileSize+1))
+	if err != nil {
+		return CSVImportResult{}, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	if len(data) > MaxPlaylistFileSize {
+		return CSVImportResult{}, fmt.Errorf("CSV file is larger than %d bytes", MaxPlaylistFileSize)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = sniffCSVDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return CSVImportResult{}, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		key := strings.ToLower(strings.TrimSpace(h))
+		if _, exists := columns[key]; !exists {
+			columns[key] = i
+		}
+	}
+
+	result := CSVImportResult{Format: "custom"}
+	if mapping == nil {
+		for _, layout := range csvLayouts {
+			if hasCSVColumns(columns, layout.Signature) {
+				m := layout.Mapping
+				mapping, result.Format = &m, layout.Name
+				break
+			}
+		}
+		if mapping == nil {
+			return CSVImportResult{}, &CSVLayoutError{Headers: header}
+		}
+	} else if _, ok := lookupCSVColumn(columns, mapping.Title); !ok {
+		return CSVImportResult{}, fmt.Errorf("title column %q not found in CSV header", mapping.Title)
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := lookupCSVColumn(columns, name); ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	row := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row++
+		if err != nil {
+			result.RowErrors = append(result.RowErrors, CSVRowError{Row: row, Reason: err.Error()})
+			continue
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		track := db.Track{
+			Title:  csvUnescapeText(field(record, mapping.Title)),
+			Artist: csvUnescapeText(field(record, mapping.Artist)),
+			Album:  csvUnescapeText(field(record, mapping.Album)),
+			ISRC:   strings.ToUpper(csvUnescapeText(field(record, mapping.ISRC))),
+
+			MusicBrainzID: csvUnescapeText(field(record, mapping.MusicBrainzID)),
+			YouTubeID:     csvUnescapeText(field(record, mapping.YouTubeID)),
+			AppleMusicID:  csvUnescapeText(field(record, mapping.AppleMusicID)),
+			DeezerID:      csvUnescapeText(field(record, mapping.DeezerID)),
+			TidalID:       csvUnescapeText(field(record, mapping.TidalID)),
+			SoundCloudURL: csvUnescapeText(field(record, mapping.SoundCloudURL)),
+		}
+		if track.Title == "" {
+			result.RowErrors = append(result.RowErrors, CSVRowError{Row: row, Reason: "missing title"})
+			continue
+		}
+		if raw := field(record, mapping.Duration); raw != "" {
+			ms, ok := parseCSVDuration(raw)
+			if !ok {
+				result.RowErrors = append(result.RowErrors, CSVRowError{Row: row, Reason: fmt.Sprintf("invalid duration %q", raw)})
+				continue
+			}
+			track.DurationMs = ms
+		}
+		// Exportify lists local files as spotify:local:...; they are imported without an ID
+		if raw := csvUnescapeText(field(record, mapping.SpotifyURI)); raw != "" && !strings.HasPrefix(raw, "spotify:local:") {
+			id, ok := parseSpotifyTrackRef(raw)
+			if !ok {
+				result.RowErrors = append(result.RowErrors, CSVRowError{Row: row, Reason: fmt.Sprintf("invalid Spotify track %q", raw)})
+				continue
+			}
+			track.SpotifyID = id
+		}
+		if err := ValidateTrackLinks(track); err != nil {
+			result.RowErrors = append(result.RowErrors, CSVRowError{Row: row, Reason: err.Error()})
+			continue
+		}
+		if result.Playlist.Title == "" {
+			result.Playlist.Title = field(record, mapping.Playlist)
+		}
+
+		track.ID = uuid.New()
+		track.CreatedAt = time.Now()
+		result.Tracks = append(result.Tracks, track)
+	}
+
+	result.Playlist.ID = uuid.New()
+	result.Playlist.Platform = "file"
+	result.Playlist.SourceID = filename
+	result.Playlist.CreatedAt = time.Now()
+	if result.Playlist.Title == "" {
+		result.Playlist.Title = strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+	}
+	return result, nil
+}
+
+// sniffCSVDelimiter picks comma, semicolon or tab from the header line
+func sniffCSVDelimiter(data []byte) rune {
+	line, _ := bufio.NewReader(bytes.NewReader(data)).ReadString('\n')
+	best, bestCount := ',', strings.Count(line, ",")
+	for _, d := range []rune{';', '\t'} {
+		if n := strings.Count(line, string(d)); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+func hasCSVColumns(columns map[string]int, names []string) bool {
+	for _, name := range names {
+		if _, ok := columns[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupCSVColumn resolves a mapping entry; "a|b" lists alternative header names
+func lookupCSVColumn(columns map[string]int, name string) (int, bool) {
+	if name == "" {
+		return 0, false
+	}
+	for _, alt := range strings.Split(name, "|") {
+		if i, ok := columns[strings.ToLower(strings.TrimSpace(alt))]; ok {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// parseCSVDuration accepts milliseconds or "m:ss" / "h:mm:ss"
+func parseCSVDuration(raw string) (int, bool) {
+	if ms, err := strconv.Atoi(raw); err == nil && ms >= 0 {
+		return ms, true
+	}
+	parts := strings.Split(raw, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, false
+	}
+	seconds := 0
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		seconds = seconds*60 + n
+	}
+	return seconds * 1000, true
+}
+
+// parseSpotifyTrackRef extracts a track ID from "spotify:track:<id>", an open.spotify.com URL or a bare ID
+func parseSpotifyTrackRef(raw string) (string, bool) {
+	id := raw
+	if strings.HasPrefix(raw, "spotify:") {
+		parts := strings.Split(raw, ":")
+		if len(parts) != 3 || parts[1] != "track" {
+			return "", false
+		}
+		id = parts[2]
+	} else if u, err := url.Parse(raw); err == nil && hostOnDomain(strings.ToLower(u.Hostname()), "spotify.com") {
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(segments) < 2 || segments[len(segments)-2] != "track" {
+			return "", false
+		}
+		id = segments[len(segments)-1]
+	}
+	if len(id) != 22 {
+		return "", false
+	}
+	for _, r := range id {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return "", false
+		}
+	}
+	return id, true
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"EchoBridge/db"
+)
+
+func TestPlaylistCSVRoundTrip(t *testing.T) {
+	want := db.Track{
+		Title: "=Song", Artist: "Artist", Album: "Album", DurationMs: 215000, ISRC: "USRC17607839",
+		MusicBrainzID: "c1a8a0e2-1f8e-4e8c-9d5f-6b1f4a2a3b4c", SpotifyID: "4uLU6hMCjMI75M1A2tKUQC",
+		YouTubeID: "dQw4w9WgXcQ", AppleMusicID: "1440857781", DeezerID: "3135556", TidalID: "5204441",
+		SoundCloudURL: "https://soundcloud.com/artist/song",
+	}
+	data, err := exportCSV([]db.Track{want})
+	if err != nil {
+		t.Fatalf("exportCSV: %v", err)
+	}
+	result, err := ParsePlaylistCSV("export.csv", bytes.NewReader(data), nil)
+	if err != nil {
+		t.Fatalf("ParsePlaylistCSV: %v", err)
+	}
+	if result.Format != "echobridge" || len(result.Tracks) != 1 || len(result.RowErrors) != 0 {
+		t.Fatalf("got format %q, %d tracks, row errors %v", result.Format, len(result.Tracks), result.RowErrors)
+	}
+	got := result.Tracks[0]
+	got.ID, got.CreatedAt = want.ID, want.CreatedAt
+	if got != want {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseCSVDuration(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want int
+		ok   bool
+	}{
+		{"215000", 215000, true},
+		{"3:35", 215000, true},
+		{"1:02:03", 3723000, true},
+		{"0", 0, true},
+		{"-5", 0, false},
+		{"3:xx", 0, false},
+		{"1:2:3:4", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseCSVDuration(tt.raw)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseCSVDuration(%q) = %d, %v, want %d, %v", tt.raw, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParsePlaylistCSV(t *testing.T) {
+	type track struct {
+		Title, Artist, SpotifyID string
+		DurationMs               int
+	}
+	tests := []struct {
+		name, data string
+		mapping    *CSVColumnMapping
+		format     string
+		title      string
+		want       []track
+		rowErrors  []int
+	}{
+		{
+			name: "exportify",
+			data: "Track URI,Track Name,Artist Name(s),Album Name,Track Duration (ms),ISRC\n" +
+				"spotify:track:4uLU6hMCjMI75M1A2tKUQC,Song,Artist,Album,215000,usrc17607839\n" +
+				"spotify:local:Artist:Album:Local:200,Local,Artist,Album,200000,\n" +
+				"spotify:album:4uLU6hMCjMI75M1A2tKUQC,Bad,Artist,Album,1,\n",
+			format: "exportify",
+			title:  "list",
+			want: []track{
+				{"Song", "Artist", "4uLU6hMCjMI75M1A2tKUQC", 215000},
+				{"Local", "Artist", "", 200000},
+			},
+			rowErrors: []int{4},
+		},
+		{
+			name:   "tunemymusic with semicolons and a playlist column",
+			data:   "Track name;Artist name;Album;Playlist name;Spotify - id\nSong;Artist;Album;Road trip;4uLU6hMCjMI75M1A2tKUQC\n",
+			format: "tunemymusic",
+			title:  "Road trip",
+			want:   []track{{"Song", "Artist", "4uLU6hMCjMI75M1A2tKUQC", 0}},
+		},
+		{
+			name:      "soundiiz with tabs and quoted commas",
+			data:      "Title\tArtist\tAlbum\n\"Song, Part 2\"\tArtist\tAlbum\n\t\t\n\tNo title\t\n",
+			format:    "soundiiz",
+			title:     "list",
+			want:      []track{{"Song, Part 2", "Artist", "", 0}},
+			rowErrors: []int{4},
+		},
+		{
+			name:    "custom mapping",
+			data:    "Name,By,Length,Link\nSong,Artist,3:35,https://open.spotify.com/intl-de/track/4uLU6hMCjMI75M1A2tKUQC?si=x\n",
+			mapping: &CSVColumnMapping{Title: "name", Artist: "by", Duration: "length", SpotifyURI: "link"},
+			format:  "custom",
+			title:   "list",
+			want:    []track{{"Song", "Artist", "4uLU6hMCjMI75M1A2tKUQC", 215000}},
+		},
+		{
+			name:      "spotify link on another host",
+			data:      "Name,Link\nSong,https://open.spotify.com.evil.example/track/4uLU6hMCjMI75M1A2tKUQC\n",
+			mapping:   &CSVColumnMapping{Title: "name", SpotifyURI: "link"},
+			format:    "custom",
+			title:     "list",
+			rowErrors: []int{2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParsePlaylistCSV("list.csv", strings.NewReader(tt.data), tt.mapping)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Format != tt.format || result.Playlist.Title != tt.title {
+				t.Errorf("format %q, title %q; want %q, %q", result.Format, result.Playlist.Title, tt.format, tt.title)
+			}
+			var got []track
+			for _, tr := range result.Tracks {
+				got = append(got, track{tr.Title, tr.Artist, tr.SpotifyID, tr.DurationMs})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("tracks = %+v, want %+v", got, tt.want)
+			}
+			var rows []int
+			for _, e := range result.RowErrors {
+				rows = append(rows, e.Row)
+			}
+			if !slices.Equal(rows, tt.rowErrors) {
+				t.Errorf("row errors = %+v, want rows %v", result.RowErrors, tt.rowErrors)
+			}
+		})
+	}
+
+	var layoutErr *CSVLayoutError
+	if _, err := ParsePlaylistCSV("list.csv", strings.NewReader("foo,bar\n1,2\n"), nil); !errors.As(err, &layoutErr) {
+		t.Errorf("got %v, want a CSVLayoutError", err)
+	}
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"EchoBridge/db"
+)
+
+// --- PLAYLIST DIFF ---
+
+// DiffTrack is a track present in only one of the compared playlists, with its 1-based position
+type DiffTrack struct {
+	Track    db.Track
+	Position int
+}
+
+// DiffMatch pairs the same song in both playlists. Moved marks songs whose relative order
+// changed: the smallest set of moves that turns A's order into B's.
+type DiffMatch struct {
+	A         db.Track
+	B         db.Track
+	PositionA int
+	PositionB int
+	Match     string // DuplicateExact or DuplicateCanonical
+	Moved     bool
+}
+
+// PlaylistDiff compares two track lists by song identity rather than raw titles
+type PlaylistDiff struct {
+	OnlyInA []DiffTrack
+	OnlyInB []DiffTrack
+	InBoth  []DiffMatch
+	Moved   int
+}
+
+// Identical reports whether both lists hold the same songs in the same order
+func (d PlaylistDiff) Identical() bool {
+	return len(d.OnlyInA) == 0 && len(d.OnlyInB) == 0 && d.Moved == 0
+}
+
+// DiffTracks compares two ordered track lists. Songs match by platform ID or ISRC first, then by
+// canonical artist and title; each track is matched at most once, so repeats are compared too.
+func DiffTracks(a, b []db.Track) PlaylistDiff {
+	exact := make(map[string][]int)
+	canonical := make(map[string][]int)
+	for j, t := range b {
+		for _, key := range exactTrackKeys(t) {
+			exact[key] = append(exact[key], j)
+		}
+		if song := CanonicalSongKey(t); song != "" {
+			canonical[song] = append(canonical[song], j)
+		}
+	}
+
+	used := make([]bool, len(b))
+	firstUnused := func(candidates []int) int {
+		for _, j := range candidates {
+			if !used[j] {
+				return j
+			}
+		}
+		return -1
+	}
+
+	var diff PlaylistDiff
+	for i, t := range a {
+		j, match := -1, DuplicateExact
+		for _, key := range exactTrackKeys(t) {
+			if j = firstUnused(exact[key]); j >= 0 {
+				break
+			}
+		}
+		if j < 0 {
+			if song := CanonicalSongKey(t); song != "" {
+				j, match = firstUnused(canonical[song]), DuplicateCanonical
+			}
+		}
+		if j < 0 {
+			diff.OnlyInA = append(diff.OnlyInA, DiffTrack{Track: t, Position: i + 1})
+			continue
+		}
+		used[j] = true
+		diff.InBoth = append(diff.InBoth, DiffMatch{A: t, B: b[j], PositionA: i + 1, PositionB: j + 1, Match: match})
+	}
+	for j, t := range b {
+		if !used[j] {
+			diff.OnlyInB = append(diff.OnlyInB, DiffTrack{Track: t, Position: j + 1})
+		}
+	}
+
+	// Songs on the longest run that keeps its order in both lists stay put; the rest moved
+	inOrder := longestIncreasingRun(diff.InBoth)
+	for i := range diff.InBoth {
+		if !inOrder[i] {
+			diff.InBoth[i].Moved = true
+			diff.Moved++
+		}
+	}
+	return diff
+}
+
+// longestIncreasingRun marks the matches (ordered by PositionA) that form the longest
+// subsequence with increasing PositionB
+func longestIncreasingRun(matches []DiffMatch) []bool {
+	tails := []int{}                  // index of the smallest tail for each run length
+	prev := make([]int, len(matches)) // predecessor of each match in its run
+	for i, m := range matches {
+		k := sort.Search(len(tails), func(n int) bool { return matches[tails[n]].PositionB >= m.PositionB })
+		prev[i] = -1
+		if k > 0 {
+			prev[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	keep := make([]bool, len(matches))
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+			keep[i] = true
+		}
+	}
+	return keep
+}
+
+// FetchPlatformPlaylistTracks reads the current tracks of a playlist on a platform, using the
+// user's account where linked and server credentials for public catalogs otherwise
+func FetchPlatformPlaylistTracks(ctx context.Context, user db.User, platform, sourceID string) ([]db.Track, error) {
+	switch platform {
+	case "spotify":
+		client, err := GetSpotifyReadClient(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return GetSpotifyPlaylistTracks(ctx, client, sourceID)
+	case "youtube":
+		client, err := GetYouTubeReadClient(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return GetYouTubePlaylistTracks(ctx, client, sourceID)
+	case "applemusic":
+		client, err := GetAppleMusicCatalogClient()
+		if user.AppleMusicToken != "" {
+			client, err = GetAppleMusicClient(ctx, user)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return GetAppleMusicPlaylistTracks(ctx, client, sourceID)
+	case "deezer":
+		client := NewDeezerClient("")
+		if user.DeezerToken != "" {
+			var err error
+			if client, err = GetDeezerClient(ctx, user); err != nil {
+				return nil, err
+			}
+		}
+		return GetDeezerPlaylistTracks(ctx, client, sourceID)
+	case "tidal":
+		client, err := GetTidalClient(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return GetTidalPlaylistTracks(ctx, client, sourceID)
+	case "subsonic":
+		client, err := GetSubsonicClient(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return GetSubsonicPlaylistTracks(ctx, client, sourceID)
+	case "jellyfin":
+		client, err := GetJellyfinClient(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return GetJellyfinPlaylistTracks(ctx, client, sourceID)
+	case "soundcloud":
+		client, err := GetSoundCloudClient(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return GetSoundCloudPlaylistTracks(ctx, client, sourceID)
+	case "lastfm":
+		client, err := GetLastFMClient(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return GetLastFMPlaylistTracks(ctx, client, sourceID)
+	case "listenbrainz":
+		client, err := GetListenBrainzClient(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return GetListenBrainzPlaylistTracks(ctx, client, sourceID)
+	}
+	return nil, fmt.Errorf("reading playlists from %s is not supported", platform)
+}
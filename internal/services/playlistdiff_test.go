@@ -0,0 +1,86 @@
+package services
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"EchoBridge/db"
+)
+
+// diffTracks builds tracks from letters, each letter one song with its own Spotify ID
+func diffTracks(songs string) []db.Track {
+	var tracks []db.Track
+	for _, s := range strings.Split(songs, "") {
+		tracks = append(tracks, db.Track{Title: "Song " + s, Artist: "Artist", SpotifyID: s})
+	}
+	return tracks
+}
+
+func TestDiffTracks(t *testing.T) {
+	tests := []struct {
+		name, a, b   string
+		onlyA, onlyB []int // positions
+		moved        int
+		identical    bool
+	}{
+		{name: "identical", a: "abcd", b: "abcd", identical: true},
+		{name: "one song moved to the end", a: "abcd", b: "bcda", moved: 1},
+		{name: "swap", a: "ab", b: "ba", moved: 1},
+		{name: "reversed", a: "abcd", b: "dcba", moved: 3},
+		{name: "added and removed", a: "abc", b: "acd", onlyA: []int{2}, onlyB: []int{3}},
+		{name: "repeats are matched once", a: "aab", b: "ab", onlyA: []int{2}},
+		{name: "two songs moved", a: "abcde", b: "acebd", moved: 2},
+		{name: "removal does not count as a move", a: "abcd", b: "acd", onlyA: []int{2}},
+		{name: "empty", a: "", b: "ab", onlyB: []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffTracks(diffTracks(tt.a), diffTracks(tt.b))
+			var onlyA, onlyB []int
+			for _, d := range diff.OnlyInA {
+				onlyA = append(onlyA, d.Position)
+			}
+			for _, d := range diff.OnlyInB {
+				onlyB = append(onlyB, d.Position)
+			}
+			if !slices.Equal(onlyA, tt.onlyA) || !slices.Equal(onlyB, tt.onlyB) {
+				t.Errorf("only in A %v, only in B %v; want %v, %v", onlyA, onlyB, tt.onlyA, tt.onlyB)
+			}
+			if diff.Moved != tt.moved || diff.Identical() != tt.identical {
+				t.Errorf("moved %d, identical %v; want %d, %v", diff.Moved, diff.Identical(), tt.moved, tt.identical)
+			}
+		})
+	}
+}
+
+func TestDiffTracksCanonicalMatch(t *testing.T) {
+	a := []db.Track{{Title: "Song - 2011 Remaster", Artist: "Artist feat. Guest", SpotifyID: "x"}}
+	b := []db.Track{{Title: "Song", Artist: "Artist", DeezerID: "1"}}
+	diff := DiffTracks(a, b)
+	if len(diff.InBoth) != 1 || diff.InBoth[0].Match != DuplicateCanonical {
+		t.Fatalf("got %+v, want one canonical match", diff)
+	}
+}
+
+func TestLongestIncreasingRun(t *testing.T) {
+	tests := []struct {
+		positionsB []int
+		want       []bool
+	}{
+		{nil, []bool{}},
+		{[]int{1, 2, 3}, []bool{true, true, true}},
+		{[]int{3, 1, 2, 4}, []bool{false, true, true, true}},
+		{[]int{2, 1}, []bool{false, true}},
+		{[]int{5, 1, 4, 2, 3}, []bool{false, true, false, true, true}},
+	}
+	for _, tt := range tests {
+		matches := make([]DiffMatch, len(tt.positionsB))
+		for i, p := range tt.positionsB {
+			matches[i] = DiffMatch{PositionA: i + 1, PositionB: p}
+		}
+		if got := longestIncreasingRun(matches); !slices.Equal(got, tt.want) {
+			t.Errorf("longestIncreasingRun(%v) = %v, want %v", tt.positionsB, got, tt.want)
+		}
+	}
+}
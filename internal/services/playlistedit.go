@@ -0,0 +1,200 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// --- NATIVE PLAYLISTS ---
+
+// NativePlatform is the Platform of playlists created and edited in EchoBridge rather than imported.
+// Their SourceID is their own ID, so they sync and re-import like any other playlist.
+const NativePlatform = "echobridge"
+
+// PlaylistDetails holds editable playlist fields; nil fields are left unchanged
+type PlaylistDetails struct {
+	Title       *string
+	Description *string
+	CoverImage  *string
+}
+
+// CreateNativePlaylist creates an empty playlist owned by the user
+func CreateNativePlaylist(ownerID uuid.UUID, title, description, coverImage string, isPublic bool) (db.Playlist, error) {
+	id := uuid.New()
+	playlist := db.Playlist{
+		ID:          id,
+		OwnerID:     ownerID,
+		Title:       title,
+		Description: description,
+		Platform:    NativePlatform,
+		SourceID:    id.String(),
+		IsPublic:    isPublic,
+		CoverImage:  coverImage,
+		CreatedAt:   time.Now(),
+	}
+	if isPublic {
+		playlist.PublishedAt = &playlist.CreatedAt
+	}
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&playlist).Error; err != nil {
+			return fmt.Errorf("failed to create playlist: %w", err)
+		}
+		return LogPlaylistActivity(tx, playlist.ID, ownerID, "playlist_created", map[string]interface{}{
+			"title": title,
+		})
+	})
+	return playlist, err
+}
+
+// UpdatePlaylistDetails changes the title, description or cover of a playlist
+func UpdatePlaylistDetails(playlist db.Playlist, userID uuid.UUID, details PlaylistDetails) (db.Playlist, error) {
+	updates := map[string]interface{}{}
+	if details.Title != nil && *details.Title != playlist.Title {
+		updates["title"] = *details.Title
+	}
+	if details.Description != nil && *details.Description != playlist.Description {
+		updates["description"] = *details.Description
+	}
+	if details.CoverImage != nil && *details.CoverImage != playlist.CoverImage {
+		updates["cover_image"] = *details.CoverImage
+	}
+	if len(updates) == 0 {
+		return playlist, nil
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&playlist).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update playlist: %w", err)
+		}
+		if err := tx.Where("id = ?", playlist.ID).First(&playlist).Error; err != nil {
+			return fmt.Errorf("failed to reload playlist: %w", err)
+		}
+		return LogPlaylistActivity(tx, playlist.ID, userID, "details_updated", updates)
+	})
+	return playlist, err
+}
+
+// DeletePlaylist removes a playlist with its tracks, members, activity, versions and share links
+func DeletePlaylist(playlistID uuid.UUID) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		trackIDs := tx.Model(&db.Track{}).Select("id").Where("playlist_id = ?", playlistID)
+		shareIDs := tx.Model(&db.Share{}).Select("id").Where("track_id IN (?)", trackIDs)
+		if err := tx.Where("share_id IN (?)", shareIDs).Delete(&db.ShareClick{}).Error; err != nil {
+			return fmt.Errorf("failed to delete share clicks: %w", err)
+		}
+		if err := tx.Where("track_id IN (?)", trackIDs).Delete(&db.Share{}).Error; err != nil {
+			return fmt.Errorf("failed to delete shares: %w", err)
+		}
+		for _, model := range []interface{}{&db.Track{}, &db.PlaylistMember{}, &db.PlaylistActivity{}, &db.PlaylistVersion{}, &db.PlaylistEvent{}, &db.PlaylistShareToken{}} {
+			if err := tx.Where("playlist_id = ?", playlistID).Delete(model).Error; err != nil {
+				return fmt.Errorf("failed to delete playlist data: %w", err)
+			}
+		}
+		if err := tx.Where("id = ?", playlistID).Delete(&db.Playlist{}).Error; err != nil {
+			return fmt.Errorf("failed to delete playlist: %w", err)
+		}
+		return nil
+	})
+}
+
+// copyTrack returns a copy of a track for another playlist
+func copyTrack(t db.Track, playlistID uuid.UUID, position int) db.Track {
+	t.ID = uuid.New()
+	t.PlaylistID = playlistID
+	t.Position = position
+	t.CreatedAt = time.Now()
+	return t
+}
+
+// DuplicatePlaylist copies a playlist and its tracks into a new private native playlist owned by the user
+func DuplicatePlaylist(source db.Playlist, ownerID uuid.UUID, title string) (db.Playlist, error) {
+	tracks, err := PlaylistTracks(source.ID)
+	if err != nil {
+		return db.Playlist{}, err
+	}
+	if title == "" {
+		title = source.Title + " (copy)"
+	}
+
+	id := uuid.New()
+	playlist := db.Playlist{
+		ID:          id,
+		OwnerID:     ownerID,
+		Title:       title,
+		Description: source.Description,
+		Platform:    NativePlatform,
+		SourceID:    id.String(),
+		CoverImage:  source.CoverImage,
+		Category:    source.Category,
+		CreatedAt:   time.Now(),
+	}
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&playlist).Error; err != nil {
+			return fmt.Errorf("failed to create playlist: %w", err)
+		}
+		copies := make([]db.Track, 0, len(tracks))
+		for i, t := range tracks {
+			copies = append(copies, copyTrack(t, playlist.ID, i+1))
+		}
+		if len(copies) > 0 {
+			if err := tx.CreateInBatches(&copies, 100).Error; err != nil {
+				return fmt.Errorf("failed to copy tracks: %w", err)
+			}
+		}
+		return logTrackChange(tx, playlist.ID, ownerID, "playlist_duplicated", map[string]interface{}{
+			"source_playlist_id": source.ID,
+			"tracks":             len(copies),
+		})
+	})
+	return playlist, err
+}
+
+// MergePlaylists appends the tracks of source that are not already in target, in source order.
+// Songs are matched by platform ID, ISRC or normalized artist and title, so duplicates inside
+// source are dropped too. It returns the number of tracks added and skipped.
+func MergePlaylists(target, source db.Playlist, userID uuid.UUID) (int, int, error) {
+	sourceTracks, err := PlaylistTracks(source.ID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	added, skipped := 0, 0
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		tracks, err := lockPlaylistTracks(tx, target.ID)
+		if err != nil {
+			return err
+		}
+		if err := renumberTracks(tx, tracks); err != nil {
+			return err
+		}
+
+		seen := newTrackSet(tracks)
+		var copies []db.Track
+		for _, t := range sourceTracks {
+			if seen.contains(t) {
+				skipped++
+				continue
+			}
+			seen.add(t)
+			copies = append(copies, copyTrack(t, target.ID, len(tracks)+len(copies)+1))
+		}
+		added = len(copies)
+		if added > 0 {
+			if err := tx.CreateInBatches(&copies, 100).Error; err != nil {
+				return fmt.Errorf("failed to add tracks: %w", err)
+			}
+		}
+
+		return logTrackChange(tx, target.ID, userID, "playlists_merged", map[string]interface{}{
+			"source_playlist_id": source.ID,
+			"added":              added,
+			"skipped":            skipped,
+		})
+	})
+	return added, skipped, err
+}
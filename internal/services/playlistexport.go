@@ -0,0 +1,347 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"EchoBridge/db"
+)
+
+// --- PLAYLIST EXPORT ---
+
+// ExportFormats maps each supported export format to its content type and file extension
+var ExportFormats = map[string]struct {
+	ContentType string
+	Extension   string
+}{
+	"m3u8": {"audio/x-mpegurl; charset=utf-8", "m3u8"},
+	"xspf": {"application/xspf+xml; charset=utf-8", "xspf"},
+	"jspf": {"application/json; charset=utf-8", "jspf"},
+	"csv":  {"text/csv; charset=utf-8", "csv"},
+	"json": {"application/json; charset=utf-8", "json"},
+}
+
+// PlaylistExportVersion is bumped when the JSON export layout changes
+const PlaylistExportVersion = 1
+
+// PlaylistExport is the full-fidelity JSON export of a playlist
+type PlaylistExport struct {
+	Version     int           `json:"version"`
+	ExportedAt  time.Time     `json:"exported_at"`
+	ID          string        `json:"id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Platform    string        `json:"platform"`
+	SourceID    string        `json:"source_id"`
+	IsPublic    bool          `json:"is_public"`
+	CoverImage  string        `json:"cover_image"`
+	Category    string        `json:"category"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Tracks      []TrackExport `json:"tracks"`
+}
+
+type TrackExport struct {
+	ID            string            `json:"id"`
+	Title         string            `json:"title"`
+	Artist        string            `json:"artist"`
+	Album         string            `json:"album"`
+	DurationMs    int               `json:"duration_ms"`
+	ISRC          string            `json:"isrc"`
+	MusicBrainzID string            `json:"musicbrainz_id"`
+	SpotifyID     string            `json:"spotify_id"`
+	YouTubeID     string            `json:"youtube_id"`
+	AppleMusicID  string            `json:"applemusic_id"`
+	DeezerID      string            `json:"deezer_id"`
+	TidalID       string            `json:"tidal_id"`
+	SoundCloudID  string            `json:"soundcloud_id"`
+	SoundCloudURL string            `json:"soundcloud_url"`
+	SubsonicID    string            `json:"subsonic_id"`
+	JellyfinID    string            `json:"jellyfin_id"`
+	PreviewURL    string            `json:"preview_url"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Links         map[string]string `json:"links"`
+}
+
+// StreamingPlatforms are the platforms TrackLinks can link to a playable track on, in display order
+var StreamingPlatforms = []string{"spotify", "youtube", "applemusic", "deezer", "tidal", "soundcloud"}
+
+// PlatformNames are display names for platform keys
+var PlatformNames = map[string]string{
+	"spotify":    "Spotify",
+	"youtube":    "YouTube Music",
+	"applemusic": "Apple Music",
+	"deezer":     "Deezer",
+	"tidal":      "TIDAL",
+	"soundcloud": "SoundCloud",
+}
+
+// platformDomains are the domains, with their subdomains, that links to each platform may point to
+var platformDomains = map[string][]string{
+	"spotify":    {"spotify.com"},
+	"youtube":    {"youtube.com", "youtu.be"},
+	"applemusic": {"apple.com"},
+	"deezer":     {"deezer.com"},
+	"tidal":      {"tidal.com"},
+	"soundcloud": {"soundcloud.com"},
+}
+
+// IsPlatformURL reports whether link is an https URL on one of the platform's domains
+func IsPlatformURL(platform, link string) bool {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range platformDomains[platform] {
+		if hostOnDomain(host, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// soundCloudTrackURL links to a track by ID through the SoundCloud player, for tracks
+// without a stored permalink
+func soundCloudTrackURL(id string) string {
+	return "https://w.soundcloud.com/player/?url=" + url.QueryEscape("https://api.soundcloud.com/tracks/"+id)
+}
+
+// TrackLinks returns public links for every platform the track is known on.
+// Apple Music links need a storefront; "us" is used when none is given.
+func TrackLinks(t db.Track, appleMusicStorefront string) map[string]string {
+	if appleMusicStorefront == "" {
+		appleMusicStorefront = "us"
+	}
+	links := make(map[string]string)
+	if t.SpotifyID != "" {
+		links["spotify"] = "https://open.spotify.com/track/" + t.SpotifyID
+	}
+	if t.YouTubeID != "" {
+		links["youtube"] = "https://music.youtube.com/watch?v=" + t.YouTubeID
+	}
+	if t.AppleMusicID != "" {
+		links["applemusic"] = fmt.Sprintf("https://music.apple.com/%s/song/%s", appleMusicStorefront, t.AppleMusicID)
+	}
+	if t.DeezerID != "" {
+		links["deezer"] = "https://www.deezer.com/track/" + t.DeezerID
+	}
+	if t.TidalID != "" {
+		links["tidal"] = "https://tidal.com/browse/track/" + t.TidalID
+	}
+	if IsPlatformURL("soundcloud", t.SoundCloudURL) {
+		links["soundcloud"] = t.SoundCloudURL
+	} else if t.SoundCloudID != "" {
+		links["soundcloud"] = soundCloudTrackURL(t.SoundCloudID)
+	}
+	if t.MusicBrainzID != "" {
+		links["musicbrainz"] = musicBrainzRecordingURL + t.MusicBrainzID
+	}
+	return links
+}
+
+// primaryTrackLink picks one playable location for formats that allow a single location
+func primaryTrackLink(links map[string]string) string {
+	for _, platform := range StreamingPlatforms {
+		if link, ok := links[platform]; ok {
+			return link
+		}
+	}
+	return ""
+}
+
+// ExportPlaylist renders a playlist in one of ExportFormats
+func ExportPlaylist(format string, playlist db.Playlist, tracks []db.Track) ([]byte, error) {
+	switch format {
+	case "m3u8":
+		return exportM3U8(playlist, tracks), nil
+	case "xspf":
+		return exportXSPF(playlist, tracks)
+	case "jspf":
+		return json.MarshalIndent(BuildJSPF(playlist, tracks), "", "  ")
+	case "csv":
+		return exportCSV(tracks)
+	case "json":
+		return json.MarshalIndent(BuildPlaylistExport(playlist, tracks), "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// BuildPlaylistExport converts a playlist and its tracks to the JSON export layout
+func BuildPlaylistExport(playlist db.Playlist, tracks []db.Track) PlaylistExport {
+	export := PlaylistExport{
+		Version:     PlaylistExportVersion,
+		ExportedAt:  time.Now().UTC(),
+		ID:          playlist.ID.String(),
+		Title:       playlist.Title,
+		Description: playlist.Description,
+		Platform:    playlist.Platform,
+		SourceID:    playlist.SourceID,
+		IsPublic:    playlist.IsPublic,
+		CoverImage:  playlist.CoverImage,
+		Category:    playlist.Category,
+		CreatedAt:   playlist.CreatedAt,
+		Tracks:      []TrackExport{},
+	}
+	for _, t := range tracks {
+		export.Tracks = append(export.Tracks, TrackExport{
+			ID:            t.ID.String(),
+			Title:         t.Title,
+			Artist:        t.Artist,
+			Album:         t.Album,
+			DurationMs:    t.DurationMs,
+			ISRC:          t.ISRC,
+			MusicBrainzID: t.MusicBrainzID,
+			SpotifyID:     t.SpotifyID,
+			YouTubeID:     t.YouTubeID,
+			AppleMusicID:  t.AppleMusicID,
+			DeezerID:      t.DeezerID,
+			TidalID:       t.TidalID,
+			SoundCloudID:  t.SoundCloudID,
+			SoundCloudURL: t.SoundCloudURL,
+			SubsonicID:    t.SubsonicID,
+			JellyfinID:    t.JellyfinID,
+			PreviewURL:    t.PreviewURL,
+			CreatedAt:     t.CreatedAt,
+			Links:         TrackLinks(t, ""),
+		})
+	}
+	return export
+}
+
+// exportM3U8 writes extended M3U. Tracks without any link get an "Artist - Title" location,
+// which local players can resolve against a music folder.
+func exportM3U8(playlist db.Playlist, tracks []db.Track) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("#EXTM3U\n")
+	fmt.Fprintf(&buf, "#PLAYLIST:%s\n", oneLine(playlist.Title))
+	for _, t := range tracks {
+		seconds := -1
+		if t.DurationMs > 0 {
+			seconds = t.DurationMs / 1000
+		}
+		display := oneLine(t.Title)
+		if t.Artist != "" {
+			display = oneLine(t.Artist) + " - " + display
+		}
+		fmt.Fprintf(&buf, "#EXTINF:%d,%s\n", seconds, display)
+		if t.Album != "" {
+			fmt.Fprintf(&buf, "#EXTALB:%s\n", oneLine(t.Album))
+		}
+		location := primaryTrackLink(TrackLinks(t, ""))
+		if location == "" {
+			location = display
+		}
+		buf.WriteString(location + "\n")
+	}
+	return buf.Bytes()
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+type xspfExport struct {
+	XMLName    xml.Name          `xml:"playlist"`
+	Version    string            `xml:"version,attr"`
+	Xmlns      string            `xml:"xmlns,attr"`
+	Title      string            `xml:"title,omitempty"`
+	Annotation string            `xml:"annotation,omitempty"`
+	Image      string            `xml:"image,omitempty"`
+	Date       string            `xml:"date,omitempty"`
+	Tracks     []xspfExportTrack `xml:"trackList>track"`
+}
+
+type xspfExportTrack struct {
+	Location   []string `xml:"location,omitempty"`
+	Identifier []string `xml:"identifier,omitempty"`
+	Title      string   `xml:"title,omitempty"`
+	Creator    string   `xml:"creator,omitempty"`
+	Album      string   `xml:"album,omitempty"`
+	Duration   int      `xml:"duration,omitempty"` // milliseconds
+}
+
+func exportXSPF(playlist db.Playlist, tracks []db.Track) ([]byte, error) {
+	doc := xspfExport{
+		Version:    "1",
+		Xmlns:      "http://xspf.org/ns/0/",
+		Title:      playlist.Title,
+		Annotation: playlist.Description,
+		Image:      playlist.CoverImage,
+	}
+	if !playlist.CreatedAt.IsZero() {
+		doc.Date = playlist.CreatedAt.Format(time.RFC3339)
+	}
+	doc.Tracks = make([]xspfExportTrack, len(tracks))
+	for i, t := range tracks {
+		links := TrackLinks(t, "")
+		xt := &doc.Tracks[i]
+		xt.Title, xt.Creator, xt.Album, xt.Duration = t.Title, t.Artist, t.Album, t.DurationMs
+		if link := primaryTrackLink(links); link != "" {
+			xt.Location = []string{link}
+		}
+		for _, platform := range []string{"musicbrainz", "spotify", "youtube", "applemusic", "deezer", "tidal", "soundcloud"} {
+			if link, ok := links[platform]; ok {
+				xt.Identifier = append(xt.Identifier, link)
+			}
+		}
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode XSPF: %w", err)
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// PlaylistCSVHeader is the column layout of CSV exports, also recognised by the CSV importer
+var PlaylistCSVHeader = []string{
+	"title", "artist", "album", "duration_ms", "isrc", "musicbrainz_id",
+	"spotify_id", "youtube_id", "applemusic_id", "deezer_id", "tidal_id", "soundcloud_url",
+}
+
+// csvFormulaPrefixes start cells that spreadsheets evaluate as formulas
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvText escapes free text for a CSV cell. Titles, IDs and links come from imported and
+// public playlists, so a leading formula character is quoted with ' to keep spreadsheets
+// from running it. ParsePlaylistCSV strips the quote again.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune(csvFormulaPrefixes, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// csvUnescapeText reverses csvText
+func csvUnescapeText(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+func exportCSV(tracks []db.Track) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(PlaylistCSVHeader)
+	for _, t := range tracks {
+		w.Write([]string{
+			csvText(t.Title), csvText(t.Artist), csvText(t.Album), strconv.Itoa(t.DurationMs), csvText(t.ISRC), csvText(t.MusicBrainzID),
+			csvText(t.SpotifyID), csvText(t.YouTubeID), csvText(t.AppleMusicID), csvText(t.DeezerID), csvText(t.TidalID),
+			csvText(t.SoundCloudURL),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to encode CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"EchoBridge/db"
+)
+
+func TestIsPlatformURL(t *testing.T) {
+	tests := []struct {
+		platform, link string
+		want           bool
+	}{
+		{"soundcloud", "https://soundcloud.com/artist/song", true},
+		{"soundcloud", "https://m.soundcloud.com/artist/song", true},
+		{"soundcloud", "https://evilsoundcloud.com/artist/song", false},
+		{"soundcloud", "https://soundcloud.com.evil.example/song", false},
+		{"soundcloud", "http://soundcloud.com/artist/song", false},
+		{"soundcloud", "https://soundcloud.com@evil.example/song", false},
+		{"soundcloud", "javascript:alert(1)", false},
+		{"soundcloud", "", false},
+		{"youtube", "https://music.youtube.com/watch?v=abc", true},
+		{"youtube", "https://youtu.be/abc", true},
+		{"spotify", "https://open.spotify.com/track/abc", true},
+		{"spotify", "https://open.spotify.com.evil.example/track/abc", false},
+		{"musicbrainz", "https://musicbrainz.org/recording/abc", false},
+	}
+	for _, tt := range tests {
+		if got := IsPlatformURL(tt.platform, tt.link); got != tt.want {
+			t.Errorf("IsPlatformURL(%q, %q) = %v, want %v", tt.platform, tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestTrackLinksSoundCloud(t *testing.T) {
+	tests := []struct {
+		name  string
+		track db.Track
+		want  string
+	}{
+		{"permalink", db.Track{SoundCloudURL: "https://soundcloud.com/a/b", SoundCloudID: "42"}, "https://soundcloud.com/a/b"},
+		{"foreign host falls back to the ID", db.Track{SoundCloudURL: "https://phish.example/a", SoundCloudID: "42"},
+			"https://w.soundcloud.com/player/?url=https%3A%2F%2Fapi.soundcloud.com%2Ftracks%2F42"},
+		{"foreign host without an ID", db.Track{SoundCloudURL: "https://phish.example/a"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TrackLinks(tt.track, "")["soundcloud"]; got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportCSVEscapesEveryTextCell(t *testing.T) {
+	track := db.Track{
+		Title: "=HYPERLINK(\"x\")", ISRC: "+ISRC", MusicBrainzID: "-mbid", SpotifyID: "@spotify",
+		YouTubeID: "=yt", AppleMusicID: "=apple", DeezerID: "=deezer", TidalID: "=tidal", SoundCloudURL: "=sc",
+	}
+	data, err := exportCSV([]db.Track{track})
+	if err != nil {
+		t.Fatalf("exportCSV: %v", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read exported CSV: %v", err)
+	}
+	for i, cell := range records[1] {
+		if cell != "" && strings.ContainsRune(csvFormulaPrefixes, rune(cell[0])) {
+			t.Errorf("column %s starts with a formula character: %q", PlaylistCSVHeader[i], cell)
+		}
+	}
+}
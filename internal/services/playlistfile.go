@@ -0,0 +1,274 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+)
+
+// --- PLAYLIST FILES (M3U/M3U8, XSPF, JSPF) ---
+
+// MaxPlaylistFileSize bounds uploaded playlist files
+const MaxPlaylistFileSize = 5 << 20
+
+var (
+	leadingTrackNumber = regexp.MustCompile(`^\d{1,3}[\s.\-_]+`)
+	trackNumberOnly    = regexp.MustCompile(`^\d{1,3}$`)
+)
+
+type xspfDocument struct {
+	Title      string `xml:"title"`
+	Creator    string `xml:"creator"`
+	Annotation string `xml:"annotation"`
+	Image      string `xml:"image"`
+	Tracks     []struct {
+		Location   []string `xml:"location"`
+		Identifier []string `xml:"identifier"`
+		Title      string   `xml:"title"`
+		Creator    string   `xml:"creator"`
+		Album      string   `xml:"album"`
+		Duration   int      `xml:"duration"` // milliseconds
+	} `xml:"trackList>track"`
+}
+
+// ParsePlaylistFile parses an uploaded playlist file into a "file" playlist and its tracks.
+// The format is picked from the file extension.
+func ParsePlaylistFile(filename string, r io.Reader) (db.Playlist, []db.Track, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxPlaylistFileSize+1))
+	if err != nil {
+		return db.Playlist{}, nil, fmt.Errorf("failed to read playlist file: %w", err)
+	}
+	if len(data) > MaxPlaylistFileSize {
+		return db.Playlist{}, nil, fmt.Errorf("playlist file is larger than %d bytes", MaxPlaylistFileSize)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var playlist db.Playlist
+	var tracks []db.Track
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".m3u", ".m3u8":
+		playlist, tracks = parseM3U(data)
+	case ".xspf":
+		playlist, tracks, err = parseXSPF(data)
+	case jspfFileExtension:
+		var doc JSPFDocument
+		if err = json.Unmarshal(data, &doc); err != nil {
+			err = fmt.Errorf("invalid JSPF: %w", err)
+		} else {
+			playlist, tracks = ParseJSPF(doc, "file", "")
+		}
+	default:
+		return db.Playlist{}, nil, fmt.Errorf("unsupported playlist file type: %s", path.Ext(filename))
+	}
+	if err != nil {
+		return db.Playlist{}, nil, err
+	}
+
+	playlist.ID = uuid.New()
+	playlist.Platform = "file"
+	playlist.SourceID = filename
+	playlist.CreatedAt = time.Now()
+	if playlist.Title == "" {
+		playlist.Title = strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+	}
+	return playlist, tracks, nil
+}
+
+// parseM3U reads extended and plain M3U. #EXTINF supplies duration and "Artist - Title";
+// without it, the track is described from its path ("Artist/Album/01 Title.mp3").
+func parseM3U(data []byte) (db.Playlist, []db.Track) {
+	// Plain .m3u files are often Latin-1 rather than UTF-8
+	if !utf8.Valid(data) {
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		data = []byte(string(runes))
+	}
+
+	var playlist db.Playlist
+	var tracks []db.Track
+	var pending *db.Track
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line == "#EXTM3U":
+			continue
+		case strings.HasPrefix(line, "#PLAYLIST:"):
+			playlist.Title = strings.TrimSpace(strings.TrimPrefix(line, "#PLAYLIST:"))
+		case strings.HasPrefix(line, "#EXTINF:"):
+			pending = parseExtInf(strings.TrimPrefix(line, "#EXTINF:"))
+		case strings.HasPrefix(line, "#EXTALB:"):
+			if pending != nil {
+				pending.Album = strings.TrimSpace(strings.TrimPrefix(line, "#EXTALB:"))
+			}
+		case strings.HasPrefix(line, "#EXTART:"):
+			if pending != nil && pending.Artist == "" {
+				pending.Artist = strings.TrimSpace(strings.TrimPrefix(line, "#EXTART:"))
+			}
+		case strings.HasPrefix(line, "#"):
+			continue
+		default:
+			track := db.Track{}
+			if pending != nil {
+				track = *pending
+			}
+			applyTrackLocation(&track, line)
+			pending = nil
+			if track.Title == "" {
+				continue
+			}
+			track.ID = uuid.New()
+			track.CreatedAt = time.Now()
+			tracks = append(tracks, track)
+		}
+	}
+	return playlist, tracks
+}
+
+// parseExtInf parses `<seconds> [attributes],<Artist - Title>`. Attribute values may
+// contain commas, so the separator is the first comma outside quotes.
+func parseExtInf(info string) *db.Track {
+	inQuotes := false
+	sep := -1
+	for i, r := range info {
+		if r == '"' {
+			inQuotes = !inQuotes
+		} else if r == ',' && !inQuotes {
+			sep = i
+			break
+		}
+	}
+
+	track := &db.Track{}
+	head, display := info, ""
+	if sep >= 0 {
+		head, display = info[:sep], strings.TrimSpace(info[sep+1:])
+	}
+	if fields := strings.Fields(head); len(fields) > 0 {
+		if seconds, err := strconv.Atoi(fields[0]); err == nil && seconds > 0 {
+			track.DurationMs = seconds * 1000
+		}
+	}
+	if artist, title, ok := strings.Cut(display, " - "); ok {
+		track.Artist, track.Title = strings.TrimSpace(artist), strings.TrimSpace(title)
+	} else {
+		track.Title = display
+	}
+	return track
+}
+
+// hostOnDomain reports whether host is domain or one of its subdomains
+func hostOnDomain(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// applyTrackLocation keeps platform IDs from streaming URLs and, for local files without
+// metadata, derives title, artist and album from the path
+func applyTrackLocation(track *db.Track, location string) {
+	if u, err := url.Parse(location); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		host := strings.ToLower(u.Hostname())
+		switch {
+		case hostOnDomain(host, "spotify.com") && strings.HasPrefix(u.Path, "/track/"):
+			track.SpotifyID = strings.TrimPrefix(u.Path, "/track/")
+		case hostOnDomain(host, "youtube.com") && u.Query().Get("v") != "":
+			track.YouTubeID = u.Query().Get("v")
+		case host == "youtu.be":
+			track.YouTubeID = strings.TrimPrefix(u.Path, "/")
+		case hostOnDomain(host, "deezer.com") && strings.Contains(u.Path, "/track/"):
+			track.DeezerID = path.Base(u.Path)
+		case hostOnDomain(host, "tidal.com") && strings.Contains(u.Path, "/track/"):
+			track.TidalID = path.Base(u.Path)
+		case hostOnDomain(host, "soundcloud.com") && u.Scheme == "https":
+			track.SoundCloudURL = location
+		}
+		return
+	}
+	if track.Title != "" {
+		return
+	}
+
+	location = strings.TrimPrefix(location, "file://")
+	if decoded, err := url.PathUnescape(location); err == nil {
+		location = decoded
+	}
+	parts := strings.FieldsFunc(location, func(r rune) bool { return r == '/' || r == '\\' })
+	if len(parts) == 0 {
+		return
+	}
+	name := parts[len(parts)-1]
+	name = strings.TrimSuffix(name, path.Ext(name))
+
+	if artist, title, ok := strings.Cut(name, " - "); ok {
+		track.Artist, track.Title = strings.TrimSpace(leadingTrackNumber.ReplaceAllString(artist, "")), strings.TrimSpace(title)
+		if trackNumberOnly.MatchString(track.Artist) {
+			track.Artist = "" // "02 - Title"
+		}
+	} else {
+		track.Title = strings.TrimSpace(leadingTrackNumber.ReplaceAllString(name, ""))
+	}
+	if len(parts) >= 3 {
+		if track.Artist == "" {
+			track.Artist = parts[len(parts)-3]
+		}
+		track.Album = parts[len(parts)-2]
+	}
+}
+
+// parseXSPF reads an XSPF (XML Shareable Playlist Format) document
+func parseXSPF(data []byte) (db.Playlist, []db.Track, error) {
+	var doc xspfDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return db.Playlist{}, nil, fmt.Errorf("invalid XSPF: %w", err)
+	}
+
+	playlist := db.Playlist{
+		Title:       doc.Title,
+		Description: doc.Annotation,
+		CoverImage:  doc.Image,
+	}
+
+	var tracks []db.Track
+	for _, xt := range doc.Tracks {
+		track := db.Track{
+			Title:      xt.Title,
+			Artist:     xt.Creator,
+			Album:      xt.Album,
+			DurationMs: xt.Duration,
+		}
+		for _, id := range xt.Identifier {
+			if strings.HasPrefix(id, musicBrainzRecordingURL) {
+				track.MusicBrainzID = strings.TrimPrefix(id, musicBrainzRecordingURL)
+			} else {
+				applyTrackLocation(&track, id)
+			}
+		}
+		for _, loc := range xt.Location {
+			applyTrackLocation(&track, loc)
+		}
+		if track.Title == "" {
+			continue
+		}
+		track.ID = uuid.New()
+		track.CreatedAt = time.Now()
+		tracks = append(tracks, track)
+	}
+	return playlist, tracks, nil
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"EchoBridge/db"
+)
+
+func TestParseExtInf(t *testing.T) {
+	tests := []struct {
+		name, info string
+		want       db.Track
+	}{
+		{"artist and title", `215,Artist - Song`, db.Track{DurationMs: 215000, Artist: "Artist", Title: "Song"}},
+		{"title only", `180,Song`, db.Track{DurationMs: 180000, Title: "Song"}},
+		{"unknown duration", `-1,Artist - Song`, db.Track{Artist: "Artist", Title: "Song"}},
+		{"quoted comma in attributes", `200 tvg-name="Doe, Jane" group-title="A,B",Jane Doe - Song, Part 2`,
+			db.Track{DurationMs: 200000, Artist: "Jane Doe", Title: "Song, Part 2"}},
+		{"no comma", `120`, db.Track{DurationMs: 120000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := *parseExtInf(tt.info); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePlaylistFile(t *testing.T) {
+	type track struct{ Title, Artist, Album, SpotifyID, YouTubeID, SoundCloudURL string }
+	tests := []struct {
+		name, filename, data string
+		title                string
+		want                 []track
+	}{
+		{
+			name:     "extended M3U",
+			filename: "mix.m3u8",
+			data: "#EXTM3U\n#PLAYLIST:Road trip\n#EXTINF:215,Artist - Song\n#EXTALB:Album\n/music/song.mp3\n" +
+				"#EXTINF:200,Other\nhttps://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC\n",
+			title: "Road trip",
+			want: []track{
+				{Title: "Song", Artist: "Artist", Album: "Album"},
+				{Title: "Other", SpotifyID: "4uLU6hMCjMI75M1A2tKUQC"},
+			},
+		},
+		{
+			name:     "plain M3U described from paths",
+			filename: "old.m3u",
+			data:     "Artist/Album/01 Song.mp3\r\nC:\\Music\\Band\\Record\\02 - Tune.flac\r\n",
+			title:    "old",
+			want: []track{
+				{Title: "Song", Artist: "Artist", Album: "Album"},
+				{Title: "Tune", Artist: "Band", Album: "Record"},
+			},
+		},
+		{
+			name:     "Latin-1 M3U",
+			filename: "latin1.m3u",
+			data:     "#EXTINF:100,Bj\xf6rk - J\xf3ga\nbjork.mp3\n",
+			title:    "latin1",
+			want:     []track{{Title: "Jóga", Artist: "Björk"}},
+		},
+		{
+			name:     "streaming links keep their IDs",
+			filename: "links.m3u",
+			data: "#EXTINF:1,A - Video\nhttps://music.youtube.com/watch?v=dQw4w9WgXcQ\n" +
+				"#EXTINF:1,B - Short\nhttps://youtu.be/dQw4w9WgXcQ\n" +
+				"#EXTINF:1,C - Cloud\nhttps://soundcloud.com/c/cloud\n" +
+				"#EXTINF:1,D - Fake\nhttps://evilsoundcloud.com/d/fake\n",
+			title: "links",
+			want: []track{
+				{Title: "Video", Artist: "A", YouTubeID: "dQw4w9WgXcQ"},
+				{Title: "Short", Artist: "B", YouTubeID: "dQw4w9WgXcQ"},
+				{Title: "Cloud", Artist: "C", SoundCloudURL: "https://soundcloud.com/c/cloud"},
+				{Title: "Fake", Artist: "D"},
+			},
+		},
+		{
+			name:     "XSPF",
+			filename: "list.xspf",
+			data: `<?xml version="1.0"?><playlist version="1" xmlns="http://xspf.org/ns/0/"><title>Mix</title><trackList>` +
+				`<track><title>Song</title><creator>Artist</creator><album>Album</album>` +
+				`<identifier>https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC</identifier></track>` +
+				`<track><location>file:///music/Band/Record/03%20Tune.mp3</location></track></trackList></playlist>`,
+			title: "Mix",
+			want: []track{
+				{Title: "Song", Artist: "Artist", Album: "Album", SpotifyID: "4uLU6hMCjMI75M1A2tKUQC"},
+				{Title: "Tune", Artist: "Band", Album: "Record"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playlist, tracks, err := ParsePlaylistFile(tt.filename, strings.NewReader(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if playlist.Title != tt.title || playlist.Platform != "file" || playlist.SourceID != tt.filename {
+				t.Errorf("playlist = %q on %q from %q", playlist.Title, playlist.Platform, playlist.SourceID)
+			}
+			if len(tracks) != len(tt.want) {
+				t.Fatalf("got %d tracks, want %d", len(tracks), len(tt.want))
+			}
+			for i, tr := range tracks {
+				got := track{tr.Title, tr.Artist, tr.Album, tr.SpotifyID, tr.YouTubeID, tr.SoundCloudURL}
+				if got != tt.want[i] {
+					t.Errorf("track %d = %+v, want %+v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+
+	if _, _, err := ParsePlaylistFile("list.pls", strings.NewReader("[playlist]")); err == nil {
+		t.Error("expected an error for an unsupported file type")
+	}
+}
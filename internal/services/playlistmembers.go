@@ -0,0 +1,486 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// --- COLLABORATIVE PLAYLISTS ---
+
+// Playlist roles, from most to least privileged. The owner is Playlist.OwnerID; editors
+// change tracks, viewers can read and sync private playlists.
+const (
+	PlaylistRoleOwner  = "owner"
+	PlaylistRoleEditor = "editor"
+	PlaylistRoleViewer = "viewer"
+)
+
+// TrackOrder sorts a playlist's tracks: explicit positions first, then import order
+const TrackOrder = "position, created_at"
+
+var playlistRoleRank = map[string]int{
+	PlaylistRoleViewer: 1,
+	PlaylistRoleEditor: 2,
+	PlaylistRoleOwner:  3,
+}
+
+var (
+	ErrPlaylistNotFound    = errors.New("playlist not found")
+	ErrPlaylistForbidden   = errors.New("your role on this playlist does not allow this")
+	ErrInvalidPlaylistRole = errors.New("role must be editor or viewer")
+	ErrInvalidInviteEmail  = errors.New("invalid email address")
+	ErrInviteOwner         = errors.New("the owner is already a member of the playlist")
+	ErrInviteNotFound      = errors.New("invitation not found")
+	ErrMemberNotFound      = errors.New("member not found")
+	ErrTrackNotFound       = errors.New("track not found in playlist")
+	ErrTrackOrderMismatch  = errors.New("track_ids must list every track of the playlist exactly once")
+	ErrInvalidTrackLink    = errors.New("invalid platform track ID or link")
+)
+
+// PlaylistRole returns the user's role on a playlist, or "" when they are not a member
+func PlaylistRole(playlist db.Playlist, userID uuid.UUID) string {
+	if userID == uuid.Nil {
+		return ""
+	}
+	if playlist.OwnerID == userID {
+		return PlaylistRoleOwner
+	}
+	var member db.PlaylistMember
+	if err := db.DB.Where("playlist_id = ? AND user_id = ? AND status = ?", playlist.ID, userID, "accepted").First(&member).Error; err != nil {
+		return ""
+	}
+	return member.Role
+}
+
+// HasPlaylistRole reports whether role grants at least the permissions of min
+func HasPlaylistRole(role, min string) bool {
+	return role != "" && playlistRoleRank[role] >= playlistRoleRank[min]
+}
+
+// CanViewPlaylist reports whether the user may read the playlist: it is public or they are a member
+func CanViewPlaylist(playlist db.Playlist, userID uuid.UUID) bool {
+	return playlist.IsPublic || PlaylistRole(playlist, userID) != ""
+}
+
+// LoadPlaylistWithRole loads a playlist the user holds at least minRole on, returning their role.
+// Private playlists the user is not a member of are reported as not found.
+func LoadPlaylistWithRole(playlistID, userID uuid.UUID, minRole string) (db.Playlist, string, error) {
+	var playlist db.Playlist
+	if err := db.DB.Where("id = ?", playlistID).First(&playlist).Error; err != nil {
+		return playlist, "", ErrPlaylistNotFound
+	}
+	role := PlaylistRole(playlist, userID)
+	if role == "" && !playlist.IsPublic {
+		return playlist, "", ErrPlaylistNotFound
+	}
+	if !HasPlaylistRole(role, minRole) {
+		return playlist, role, ErrPlaylistForbidden
+	}
+	return playlist, role, nil
+}
+
+// PlaylistTracks returns a playlist's tracks in playlist order
+func PlaylistTracks(playlistID uuid.UUID) ([]db.Track, error) {
+	var tracks []db.Track
+	if err := db.DB.Where("playlist_id = ?", playlistID).Order(TrackOrder).Find(&tracks).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch tracks: %w", err)
+	}
+	return tracks, nil
+}
+
+// LogPlaylistActivity appends an entry to the playlist's activity log
+func LogPlaylistActivity(tx *gorm.DB, playlistID, userID uuid.UUID, action string, details map[string]interface{}) error {
+	detailsJSON, _ := json.Marshal(details)
+	return tx.Create(&db.PlaylistActivity{
+		ID:         uuid.New(),
+		PlaylistID: playlistID,
+		UserID:     userID,
+		Action:     action,
+		Details:    string(detailsJSON),
+		CreatedAt:  time.Now(),
+	}).Error
+}
+
+// --- MEMBERS ---
+
+// InvitePlaylistMember invites an email address to a playlist. Inviting an address again
+// updates the role. The invitee accepts from their pending invites once signed in with that email.
+func InvitePlaylistMember(playlist db.Playlist, inviterID uuid.UUID, email, role string) (db.PlaylistMember, error) {
+	var member db.PlaylistMember
+	if role != PlaylistRoleEditor && role != PlaylistRoleViewer {
+		return member, ErrInvalidPlaylistRole
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return member, ErrInvalidInviteEmail
+	}
+
+	var invitee db.User
+	hasAccount := db.DB.Where("LOWER(email) = ?", email).First(&invitee).Error == nil
+	if hasAccount && invitee.ID == playlist.OwnerID {
+		return member, ErrInviteOwner
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("playlist_id = ? AND email = ?", playlist.ID, email).First(&member).Error
+		if err == nil {
+			if err := tx.Model(&member).Update("role", role).Error; err != nil {
+				return fmt.Errorf("failed to update invitation: %w", err)
+			}
+		} else {
+			member = db.PlaylistMember{
+				ID:         uuid.New(),
+				PlaylistID: playlist.ID,
+				Email:      email,
+				Role:       role,
+				Status:     "pending",
+				InvitedBy:  inviterID,
+				CreatedAt:  time.Now(),
+			}
+			if hasAccount {
+				member.UserID = &invitee.ID
+			}
+			if err := tx.Create(&member).Error; err != nil {
+				return fmt.Errorf("failed to save invitation: %w", err)
+			}
+		}
+		return LogPlaylistActivity(tx, playlist.ID, inviterID, "member_invited", map[string]interface{}{
+			"email": email,
+			"role":  role,
+		})
+	})
+	return member, err
+}
+
+// PendingPlaylistInvites returns the invitations waiting for the user, matched by account or email
+func PendingPlaylistInvites(user db.User) ([]db.PlaylistMember, error) {
+	var invites []db.PlaylistMember
+	err := db.DB.Where("status = ? AND (user_id = ? OR (user_id IS NULL AND email = ?))", "pending", user.ID, strings.ToLower(user.Email)).
+		Order("created_at").
+		Find(&invites).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch invitations: %w", err)
+	}
+	return invites, nil
+}
+
+// pendingInvite loads an invitation addressed to the user
+func pendingInvite(tx *gorm.DB, inviteID uuid.UUID, user db.User) (db.PlaylistMember, error) {
+	var member db.PlaylistMember
+	err := tx.Where("id = ? AND status = ? AND (user_id = ? OR (user_id IS NULL AND email = ?))", inviteID, "pending", user.ID, strings.ToLower(user.Email)).
+		First(&member).Error
+	if err != nil {
+		return member, ErrInviteNotFound
+	}
+	return member, nil
+}
+
+// AcceptPlaylistInvite makes the user a member of the playlist they were invited to
+func AcceptPlaylistInvite(inviteID uuid.UUID, user db.User) (db.PlaylistMember, error) {
+	var member db.PlaylistMember
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if member, err = pendingInvite(tx, inviteID, user); err != nil {
+			return err
+		}
+		now := time.Now()
+		member.UserID = &user.ID
+		member.Status = "accepted"
+		member.AcceptedAt = &now
+		if err := tx.Save(&member).Error; err != nil {
+			return fmt.Errorf("failed to accept invitation: %w", err)
+		}
+		return LogPlaylistActivity(tx, member.PlaylistID, user.ID, "member_joined", map[string]interface{}{
+			"role": member.Role,
+		})
+	})
+	return member, err
+}
+
+// DeclinePlaylistInvite deletes an invitation addressed to the user
+func DeclinePlaylistInvite(inviteID uuid.UUID, user db.User) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		member, err := pendingInvite(tx, inviteID, user)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&member).Error; err != nil {
+			return fmt.Errorf("failed to decline invitation: %w", err)
+		}
+		return LogPlaylistActivity(tx, member.PlaylistID, user.ID, "invite_declined", map[string]interface{}{
+			"email": member.Email,
+		})
+	})
+}
+
+// UpdatePlaylistMemberRole changes a member's role
+func UpdatePlaylistMemberRole(playlistID, actorID, memberID uuid.UUID, role string) (db.PlaylistMember, error) {
+	var member db.PlaylistMember
+	if role != PlaylistRoleEditor && role != PlaylistRoleViewer {
+		return member, ErrInvalidPlaylistRole
+	}
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND playlist_id = ?", memberID, playlistID).First(&member).Error; err != nil {
+			return ErrMemberNotFound
+		}
+		previous := member.Role
+		if err := tx.Model(&member).Update("role", role).Error; err != nil {
+			return fmt.Errorf("failed to update member: %w", err)
+		}
+		return LogPlaylistActivity(tx, playlistID, actorID, "member_role_changed", map[string]interface{}{
+			"email": member.Email,
+			"from":  previous,
+			"to":    role,
+		})
+	})
+	return member, err
+}
+
+// RemovePlaylistMember removes a member or cancels an invitation. Members may also remove themselves.
+func RemovePlaylistMember(playlistID, actorID, memberID uuid.UUID) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		var member db.PlaylistMember
+		if err := tx.Where("id = ? AND playlist_id = ?", memberID, playlistID).First(&member).Error; err != nil {
+			return ErrMemberNotFound
+		}
+		if err := tx.Delete(&member).Error; err != nil {
+			return fmt.Errorf("failed to remove member: %w", err)
+		}
+		action := "member_removed"
+		if member.UserID != nil && *member.UserID == actorID {
+			action = "member_left"
+		}
+		return LogPlaylistActivity(tx, playlistID, actorID, action, map[string]interface{}{
+			"email": member.Email,
+		})
+	})
+}
+
+// SetPlaylistMemberSync records whether a member lets the owner sync the playlist into their
+// linked accounts
+func SetPlaylistMemberSync(playlistID, userID uuid.UUID, enabled bool) (db.PlaylistMember, error) {
+	var member db.PlaylistMember
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("playlist_id = ? AND user_id = ? AND status = ?", playlistID, userID, "accepted").First(&member).Error; err != nil {
+			return ErrMemberNotFound
+		}
+		if err := tx.Model(&member).Update("sync_opt_in", enabled).Error; err != nil {
+			return fmt.Errorf("failed to update member: %w", err)
+		}
+		member.SyncOptIn = enabled
+		return LogPlaylistActivity(tx, playlistID, userID, "member_sync_changed", map[string]interface{}{
+			"enabled": enabled,
+		})
+	})
+	return member, err
+}
+
+// PlaylistSyncMembers returns the owner and the accepted members who opted in to member syncs
+func PlaylistSyncMembers(playlist db.Playlist) ([]db.User, error) {
+	userIDs := []uuid.UUID{playlist.OwnerID}
+	var memberIDs []uuid.UUID
+	if err := db.DB.Model(&db.PlaylistMember{}).
+		Where("playlist_id = ? AND status = ? AND sync_opt_in = ? AND user_id IS NOT NULL", playlist.ID, "accepted", true).
+		Pluck("user_id", &memberIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch members: %w", err)
+	}
+	userIDs = append(userIDs, memberIDs...)
+
+	var users []db.User
+	if err := db.DB.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch members: %w", err)
+	}
+	return users, nil
+}
+
+// LinkedSyncPlatforms returns the sync destinations the user has connected
+func LinkedSyncPlatforms(user db.User) []string {
+	var platforms []string
+	if user.SpotifyToken != "" {
+		platforms = append(platforms, "spotify")
+	}
+	if user.YouTubeToken != "" {
+		platforms = append(platforms, "youtube")
+	}
+	if user.AppleMusicToken != "" {
+		platforms = append(platforms, "applemusic")
+	}
+	if user.DeezerToken != "" {
+		platforms = append(platforms, "deezer")
+	}
+	if user.TidalToken != "" {
+		platforms = append(platforms, "tidal")
+	}
+	if user.SubsonicToken != "" {
+		platforms = append(platforms, "subsonic")
+	}
+	if user.JellyfinToken != "" {
+		platforms = append(platforms, "jellyfin")
+	}
+	if user.SoundCloudToken != "" {
+		platforms = append(platforms, "soundcloud")
+	}
+	if user.ListenBrainzToken != "" {
+		platforms = append(platforms, "listenbrainz")
+	}
+	return platforms
+}
+
+// --- TRACK EDITS ---
+
+// lockPlaylistTracks locks the playlist row so concurrent edits by several members apply
+// one after another, and returns its tracks in order. A playlist without versions gets its
+// tracks recorded as the first version before they are edited.
+func lockPlaylistTracks(tx *gorm.DB, playlistID uuid.UUID) ([]db.Track, error) {
+	var playlist db.Playlist
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", playlistID).First(&playlist).Error; err != nil {
+		return nil, ErrPlaylistNotFound
+	}
+	var tracks []db.Track
+	if err := tx.Where("playlist_id = ?", playlistID).Order(TrackOrder).Find(&tracks).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch tracks: %w", err)
+	}
+	if err := recordBaseVersion(tx, playlist, tracks); err != nil {
+		return nil, err
+	}
+	return tracks, nil
+}
+
+// renumberTracks stores 1-based positions matching the slice order, touching only changed rows
+func renumberTracks(tx *gorm.DB, tracks []db.Track) error {
+	for i := range tracks {
+		if tracks[i].Position == i+1 {
+			continue
+		}
+		tracks[i].Position = i + 1
+		if err := tx.Model(&db.Track{}).Where("id = ?", tracks[i].ID).Update("position", i+1).Error; err != nil {
+			return fmt.Errorf("failed to update track positions: %w", err)
+		}
+	}
+	return nil
+}
+
+// ValidateTrackLinks checks platform IDs and the SoundCloud permalink given by a client, which
+// end up in share links and redirects
+func ValidateTrackLinks(track db.Track) error {
+	checks := []struct {
+		name, value string
+		ok          func(string) bool
+	}{
+		{"spotify_id", track.SpotifyID, spotifyIDPattern.MatchString},
+		{"youtube_id", track.YouTubeID, youTubeVideoIDPattern.MatchString},
+		{"applemusic_id", track.AppleMusicID, deezerIDPattern.MatchString},
+		{"deezer_id", track.DeezerID, deezerIDPattern.MatchString},
+		{"tidal_id", track.TidalID, deezerIDPattern.MatchString},
+		{"soundcloud_url", track.SoundCloudURL, func(v string) bool { return IsPlatformURL("soundcloud", v) }},
+	}
+	for _, check := range checks {
+		if check.value != "" && !check.ok(check.value) {
+			return fmt.Errorf("%w: %s %q", ErrInvalidTrackLink, check.name, check.value)
+		}
+	}
+	return nil
+}
+
+// AddPlaylistTrack inserts a track at a 1-based position; 0 or a position past the end appends
+func AddPlaylistTrack(playlistID, userID uuid.UUID, track db.Track, position int) (db.Track, error) {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		tracks, err := lockPlaylistTracks(tx, playlistID)
+		if err != nil {
+			return err
+		}
+		if position <= 0 || position > len(tracks) {
+			position = len(tracks) + 1
+		}
+
+		track.ID = uuid.New()
+		track.PlaylistID = playlistID
+		track.Position = position
+		track.CreatedAt = time.Now()
+		if err := tx.Create(&track).Error; err != nil {
+			return fmt.Errorf("failed to add track: %w", err)
+		}
+
+		ordered := make([]db.Track, 0, len(tracks)+1)
+		ordered = append(ordered, tracks[:position-1]...)
+		ordered = append(ordered, track)
+		ordered = append(ordered, tracks[position-1:]...)
+		if err := renumberTracks(tx, ordered); err != nil {
+			return err
+		}
+
+		return logTrackChange(tx, playlistID, userID, "track_added", map[string]interface{}{
+			"track_id": track.ID,
+			"title":    track.Title,
+			"artist":   track.Artist,
+			"position": position,
+		})
+	})
+	return track, err
+}
+
+// RemovePlaylistTrack deletes a track from a playlist
+func RemovePlaylistTrack(playlistID, userID, trackID uuid.UUID) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockPlaylistTracks(tx, playlistID); err != nil {
+			return err
+		}
+		var track db.Track
+		if err := tx.Where("id = ? AND playlist_id = ?", trackID, playlistID).First(&track).Error; err != nil {
+			return ErrTrackNotFound
+		}
+		if err := tx.Delete(&track).Error; err != nil {
+			return fmt.Errorf("failed to remove track: %w", err)
+		}
+		return logTrackChange(tx, playlistID, userID, "track_removed", map[string]interface{}{
+			"track_id": track.ID,
+			"title":    track.Title,
+			"artist":   track.Artist,
+		})
+	})
+}
+
+// ReorderPlaylistTracks sets the playlist order. trackIDs must be a permutation of the
+// playlist's tracks, so an edit based on a stale copy is rejected instead of dropping tracks.
+func ReorderPlaylistTracks(playlistID, userID uuid.UUID, trackIDs []uuid.UUID) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		tracks, err := lockPlaylistTracks(tx, playlistID)
+		if err != nil {
+			return err
+		}
+		if len(trackIDs) != len(tracks) {
+			return ErrTrackOrderMismatch
+		}
+		byID := make(map[uuid.UUID]db.Track, len(tracks))
+		for _, t := range tracks {
+			byID[t.ID] = t
+		}
+
+		ordered := make([]db.Track, 0, len(trackIDs))
+		for _, id := range trackIDs {
+			t, ok := byID[id]
+			if !ok {
+				return ErrTrackOrderMismatch
+			}
+			delete(byID, id)
+			ordered = append(ordered, t)
+		}
+		if err := renumberTracks(tx, ordered); err != nil {
+			return err
+		}
+
+		return logTrackChange(tx, playlistID, userID, "tracks_reordered", map[string]interface{}{
+			"tracks": len(ordered),
+		})
+	})
+}
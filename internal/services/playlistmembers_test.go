@@ -0,0 +1,36 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"EchoBridge/db"
+)
+
+func TestValidateTrackLinks(t *testing.T) {
+	tests := []struct {
+		name  string
+		track db.Track
+		ok    bool
+	}{
+		{"metadata only", db.Track{Title: "Song"}, true},
+		{"valid IDs", db.Track{SpotifyID: "4uLU6hMCjMI75M1A2tKUQC", YouTubeID: "dQw4w9WgXcQ", AppleMusicID: "1440857781",
+			DeezerID: "3135556", TidalID: "5204441", SoundCloudURL: "https://soundcloud.com/artist/song"}, true},
+		{"soundcloud on another host", db.Track{SoundCloudURL: "https://evilsoundcloud.com/phish"}, false},
+		{"soundcloud over http", db.Track{SoundCloudURL: "http://soundcloud.com/artist/song"}, false},
+		{"spotify ID with a path", db.Track{SpotifyID: "../../evil"}, false},
+		{"youtube ID with a query", db.Track{YouTubeID: "abc?next=https://evil"}, false},
+		{"non-numeric deezer ID", db.Track{DeezerID: "12ab"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTrackLinks(tt.track)
+			if tt.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidTrackLink) {
+				t.Errorf("got %v, want ErrInvalidTrackLink", err)
+			}
+		})
+	}
+}
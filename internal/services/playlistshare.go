@@ -0,0 +1,73 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+)
+
+// --- PLAYLIST SHARE TOKENS ---
+
+// Playlist share token permissions. "import" also allows viewing.
+const (
+	PlaylistShareView   = "view"
+	PlaylistShareImport = "import"
+)
+
+var (
+	ErrShareTokenInvalid    = errors.New("invalid share token")
+	ErrShareTokenExpired    = errors.New("share token has expired")
+	ErrShareTokenRevoked    = errors.New("share token has been revoked")
+	ErrShareTokenPermission = errors.New("share token does not allow importing")
+)
+
+// CreatePlaylistShareToken creates an unlisted link token for a playlist. expiresAt may be nil.
+func CreatePlaylistShareToken(playlistID uuid.UUID, permission string, expiresAt *time.Time) (db.PlaylistShareToken, error) {
+	if permission != PlaylistShareView && permission != PlaylistShareImport {
+		return db.PlaylistShareToken{}, fmt.Errorf("unknown permission: %s", permission)
+	}
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return db.PlaylistShareToken{}, fmt.Errorf("failed to generate share token: %w", err)
+	}
+
+	token := db.PlaylistShareToken{
+		ID:         uuid.New(),
+		PlaylistID: playlistID,
+		Token:      base64.RawURLEncoding.EncodeToString(buf),
+		Permission: permission,
+		ExpiresAt:  expiresAt,
+		CreatedAt:  time.Now(),
+	}
+	if err := db.DB.Create(&token).Error; err != nil {
+		return db.PlaylistShareToken{}, fmt.Errorf("failed to save share token: %w", err)
+	}
+	return token, nil
+}
+
+// CheckPlaylistShareToken validates a token for a playlist and the permission needed
+func CheckPlaylistShareToken(playlistID uuid.UUID, token, permission string) (db.PlaylistShareToken, error) {
+	var shareToken db.PlaylistShareToken
+	if token == "" {
+		return shareToken, ErrShareTokenInvalid
+	}
+	if err := db.DB.Where("token = ? AND playlist_id = ?", token, playlistID).First(&shareToken).Error; err != nil {
+		return shareToken, ErrShareTokenInvalid
+	}
+	if shareToken.RevokedAt != nil {
+		return shareToken, ErrShareTokenRevoked
+	}
+	if shareToken.ExpiresAt != nil && time.Now().After(*shareToken.ExpiresAt) {
+		return shareToken, ErrShareTokenExpired
+	}
+	if permission == PlaylistShareImport && shareToken.Permission != PlaylistShareImport {
+		return shareToken, ErrShareTokenPermission
+	}
+	return shareToken, nil
+}
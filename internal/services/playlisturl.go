@@ -0,0 +1,127 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// --- PLAYLIST URL RESOLVER ---
+
+// Error codes returned by ResolvePlaylistURL
+const (
+	PlaylistURLMissing          = "missing_source"
+	PlaylistURLInvalid          = "invalid_url"
+	PlaylistURLUnsupportedHost  = "unsupported_platform"
+	PlaylistURLNotPlaylist      = "not_a_playlist"
+	PlaylistURLShortLink        = "short_link_unsupported"
+	PlaylistURLPlatformMismatch = "platform_mismatch"
+)
+
+// PlaylistURLError explains why a URL could not be resolved; Code is stable for clients
+type PlaylistURLError struct {
+	Code    string
+	Message string
+}
+
+func (e *PlaylistURLError) Error() string {
+	return e.Message
+}
+
+var (
+	spotifyIDPattern    = regexp.MustCompile(`^[0-9A-Za-z]{22}$`)
+	youTubeListPattern  = regexp.MustCompile(`^[0-9A-Za-z_-]{10,}$`)
+	tidalUUIDPattern    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	deezerIDPattern     = regexp.MustCompile(`^\d+$`)
+	appleMusicIDPattern = regexp.MustCompile(`^p(l)?\.[0-9A-Za-z-]+$`)
+)
+
+// LooksLikePlaylistURL reports whether a source ID is really a URL or URI to resolve
+func LooksLikePlaylistURL(s string) bool {
+	s = strings.TrimSpace(s)
+	return strings.Contains(s, "://") || strings.HasPrefix(s, "spotify:") || strings.Contains(s, ".com/")
+}
+
+// ResolvePlaylistURL infers platform and source ID from a playlist link or URI. Supported:
+// open.spotify.com/playlist/<id> and spotify:playlist:<id>; youtube.com, music.youtube.com and
+// youtu.be links with ?list=; music.apple.com/<storefront>/playlist/<name>/<id>;
+// deezer.com/<lang>/playlist/<id>; tidal.com and listen.tidal.com /playlist/<uuid>.
+func ResolvePlaylistURL(raw string) (string, string, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "spotify:") {
+		return resolveSpotifyURI(raw)
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", "", &PlaylistURLError{PlaylistURLInvalid, "Not a valid URL"}
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+
+	switch {
+	case host == "open.spotify.com" || host == "play.spotify.com":
+		// Localised links look like /intl-de/playlist/<id>; old ones like /user/<name>/playlist/<id>
+		if id := segmentAfter(segments, "playlist"); spotifyIDPattern.MatchString(id) {
+			return "spotify", id, nil
+		}
+		return "", "", notAPlaylist("Spotify")
+	case host == "spotify.link":
+		return "", "", &PlaylistURLError{PlaylistURLShortLink, "Spotify short links are not supported; open the link and copy the full playlist URL"}
+
+	case host == "youtube.com" || host == "m.youtube.com" || host == "music.youtube.com" || host == "youtu.be":
+		list := u.Query().Get("list")
+		// Radio mixes (RD...) are generated per viewer and cannot be read through the API
+		if youTubeListPattern.MatchString(list) && !strings.HasPrefix(list, "RD") {
+			return "youtube", list, nil
+		}
+		return "", "", notAPlaylist("YouTube")
+
+	case host == "music.apple.com" || host == "itunes.apple.com":
+		if len(segments) > 0 && appleMusicIDPattern.MatchString(segments[len(segments)-1]) && segmentAfter(segments, "playlist") != "" {
+			return "applemusic", segments[len(segments)-1], nil
+		}
+		return "", "", notAPlaylist("Apple Music")
+
+	case host == "deezer.com":
+		if id := segmentAfter(segments, "playlist"); deezerIDPattern.MatchString(id) {
+			return "deezer", id, nil
+		}
+		return "", "", notAPlaylist("Deezer")
+	case host == "deezer.page.link" || host == "link.deezer.com":
+		return "", "", &PlaylistURLError{PlaylistURLShortLink, "Deezer short links are not supported; open the link and copy the full playlist URL"}
+
+	case host == "tidal.com" || host == "listen.tidal.com":
+		if id := segmentAfter(segments, "playlist"); tidalUUIDPattern.MatchString(id) {
+			return "tidal", id, nil
+		}
+		return "", "", notAPlaylist("TIDAL")
+	}
+	return "", "", &PlaylistURLError{PlaylistURLUnsupportedHost, fmt.Sprintf("Links from %s are not supported", host)}
+}
+
+// resolveSpotifyURI accepts spotify:playlist:<id> and the legacy spotify:user:<name>:playlist:<id>
+func resolveSpotifyURI(uri string) (string, string, error) {
+	parts := strings.Split(uri, ":")
+	if id := segmentAfter(parts, "playlist"); spotifyIDPattern.MatchString(id) {
+		return "spotify", id, nil
+	}
+	return "", "", notAPlaylist("Spotify")
+}
+
+func segmentAfter(segments []string, name string) string {
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == name {
+			return segments[i+1]
+		}
+	}
+	return ""
+}
+
+func notAPlaylist(platform string) *PlaylistURLError {
+	return &PlaylistURLError{PlaylistURLNotPlaylist, fmt.Sprintf("This %s link does not point to a playlist", platform)}
+}
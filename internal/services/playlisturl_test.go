@@ -0,0 +1,48 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolvePlaylistURL(t *testing.T) {
+	const tidalID = "36ea71a8-445e-41a4-82ab-6628c581535d"
+	tests := []struct {
+		raw, platform, id, code string
+	}{
+		{"https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abc", "spotify", "37i9dQZF1DXcBWIGoYBM5M", ""},
+		{"open.spotify.com/intl-de/playlist/37i9dQZF1DXcBWIGoYBM5M", "spotify", "37i9dQZF1DXcBWIGoYBM5M", ""},
+		{"https://open.spotify.com/user/someone/playlist/37i9dQZF1DXcBWIGoYBM5M", "spotify", "37i9dQZF1DXcBWIGoYBM5M", ""},
+		{"spotify:playlist:37i9dQZF1DXcBWIGoYBM5M", "spotify", "37i9dQZF1DXcBWIGoYBM5M", ""},
+		{"spotify:user:someone:playlist:37i9dQZF1DXcBWIGoYBM5M", "spotify", "37i9dQZF1DXcBWIGoYBM5M", ""},
+		{"https://open.spotify.com/album/37i9dQZF1DXcBWIGoYBM5M", "", "", PlaylistURLNotPlaylist},
+		{"https://spotify.link/abc", "", "", PlaylistURLShortLink},
+		{"https://www.youtube.com/playlist?list=PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG", "youtube", "PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG", ""},
+		{"https://music.youtube.com/playlist?list=short", "", "", PlaylistURLNotPlaylist},
+		{"https://youtu.be/dQw4w9WgXcQ?list=PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG", "youtube", "PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG", ""},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ&list=RDdQw4w9WgXcQ", "", "", PlaylistURLNotPlaylist},
+		{"https://music.apple.com/us/playlist/todays-hits/pl.f4d106fed2bd41149aaacabb233eb5eb", "applemusic", "pl.f4d106fed2bd41149aaacabb233eb5eb", ""},
+		{"https://music.apple.com/us/album/some-album/1440857781", "", "", PlaylistURLNotPlaylist},
+		{"https://www.deezer.com/fr/playlist/908622995", "deezer", "908622995", ""},
+		{"https://link.deezer.com/s/abc", "", "", PlaylistURLShortLink},
+		{"https://tidal.com/browse/playlist/" + tidalID, "tidal", tidalID, ""},
+		{"https://listen.tidal.com/playlist/" + tidalID, "tidal", tidalID, ""},
+		{"https://soundcloud.com/someone/sets/mix", "", "", PlaylistURLUnsupportedHost},
+		{"https://", "", "", PlaylistURLInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			platform, id, err := ResolvePlaylistURL(tt.raw)
+			var urlErr *PlaylistURLError
+			if tt.code != "" {
+				if !errors.As(err, &urlErr) || urlErr.Code != tt.code {
+					t.Fatalf("got %q, %q, %v; want error code %q", platform, id, err, tt.code)
+				}
+				return
+			}
+			if err != nil || platform != tt.platform || id != tt.id {
+				t.Errorf("got %q, %q, %v; want %q, %q", platform, id, err, tt.platform, tt.id)
+			}
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+	"github.com/zmb3/spotify/v2"
+	"gorm.io/gorm"
+)
+
+// --- VERSION HISTORY ---
+
+// What caused a new version
+const (
+	VersionTriggerImport  = "import"  // first import from a platform or file
+	VersionTriggerSync    = "sync"    // re-import refreshing the tracks from the source
+	VersionTriggerEdit    = "edit"    // a track change made in EchoBridge
+	VersionTriggerRestore = "restore" // rollback to an earlier version
+)
+
+var (
+	ErrVersionNotFound               = errors.New("playlist version not found")
+	ErrDestinationReplaceUnsupported = errors.New("replacing the tracks of a synced playlist is not supported on this platform")
+)
+
+// versionTracks encodes tracks for a snapshot. IDs and timestamps are left out so that
+// identical contents encode identically, and positions follow the slice order.
+func versionTracks(tracks []db.Track) (string, error) {
+	snapshot := make([]db.Track, len(tracks))
+	for i, t := range tracks {
+		t.ID = uuid.Nil
+		t.PlaylistID = uuid.Nil
+		t.Position = i + 1
+		t.CreatedAt = time.Time{}
+		snapshot[i] = t
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode version: %w", err)
+	}
+	return string(data), nil
+}
+
+// VersionTracks decodes the tracks of a version, in playlist order
+func VersionTracks(v db.PlaylistVersion) ([]db.Track, error) {
+	var tracks []db.Track
+	if err := json.Unmarshal([]byte(v.Tracks), &tracks); err != nil {
+		return nil, fmt.Errorf("failed to decode version %d: %w", v.Number, err)
+	}
+	return tracks, nil
+}
+
+// recordVersion stores tracks as the next version of a playlist, unless they equal the latest one
+func recordVersion(tx *gorm.DB, playlistID, userID uuid.UUID, trigger, action string, tracks []db.Track) error {
+	encoded, err := versionTracks(tracks)
+	if err != nil {
+		return err
+	}
+
+	var latest db.PlaylistVersion
+	number := 1
+	err = tx.Where("playlist_id = ?", playlistID).Order("number DESC").First(&latest).Error
+	if err == nil {
+		if latest.Tracks == encoded {
+			return nil
+		}
+		number = latest.Number + 1
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to load latest version: %w", err)
+	}
+
+	if err := tx.Create(&db.PlaylistVersion{
+		ID:         uuid.New(),
+		PlaylistID: playlistID,
+		Number:     number,
+		Trigger:    trigger,
+		Action:     action,
+		UserID:     userID,
+		TrackCount: len(tracks),
+		Tracks:     encoded,
+		CreatedAt:  time.Now(),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to save version: %w", err)
+	}
+	return nil
+}
+
+// SnapshotPlaylist records the current tracks of a playlist as a new version
+func SnapshotPlaylist(tx *gorm.DB, playlistID, userID uuid.UUID, trigger, action string) error {
+	var tracks []db.Track
+	if err := tx.Where("playlist_id = ?", playlistID).Order(TrackOrder).Find(&tracks).Error; err != nil {
+		return fmt.Errorf("failed to fetch tracks: %w", err)
+	}
+	return recordVersion(tx, playlistID, userID, trigger, action, tracks)
+}
+
+// RecordImportVersion snapshots a playlist after its tracks were imported: the first time as
+// an import, afterwards as a sync from the source. Failures are logged, never failing the import.
+func RecordImportVersion(playlistID, userID uuid.UUID) {
+	trigger, action := VersionTriggerImport, "imported"
+	var count int64
+	if err := db.DB.Model(&db.PlaylistVersion{}).Where("playlist_id = ?", playlistID).Count(&count).Error; err == nil && count > 0 {
+		trigger, action = VersionTriggerSync, "refreshed"
+	}
+	if err := SnapshotPlaylist(db.DB, playlistID, userID, trigger, action); err != nil {
+		log.Printf("Failed to record version of playlist %s: %v", playlistID, err)
+	}
+}
+
+// recordBaseVersion stores the tracks as the first version of a playlist that has none yet,
+// i.e. one imported before versions were kept, so that its first edit can be rolled back
+func recordBaseVersion(tx *gorm.DB, playlist db.Playlist, tracks []db.Track) error {
+	var count int64
+	if err := tx.Model(&db.PlaylistVersion{}).Where("playlist_id = ?", playlist.ID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count versions: %w", err)
+	}
+	if count > 0 || len(tracks) == 0 {
+		return nil
+	}
+	return recordVersion(tx, playlist.ID, playlist.OwnerID, VersionTriggerImport, "imported", tracks)
+}
+
+// logTrackChange records a track edit in the activity log and as a new version
+func logTrackChange(tx *gorm.DB, playlistID, userID uuid.UUID, action string, details map[string]interface{}) error {
+	if err := LogPlaylistActivity(tx, playlistID, userID, action, details); err != nil {
+		return err
+	}
+	return SnapshotPlaylist(tx, playlistID, userID, VersionTriggerEdit, action)
+}
+
+// PlaylistVersions lists the versions of a playlist, newest first, without their tracks
+func PlaylistVersions(playlistID uuid.UUID) ([]db.PlaylistVersion, error) {
+	var versions []db.PlaylistVersion
+	err := db.DB.Omit("tracks").Where("playlist_id = ?", playlistID).Order("number DESC").Find(&versions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch versions: %w", err)
+	}
+	return versions, nil
+}
+
+// LoadPlaylistVersion returns a version of a playlist with its tracks
+func LoadPlaylistVersion(playlistID uuid.UUID, number int) (db.PlaylistVersion, []db.Track, error) {
+	var version db.PlaylistVersion
+	if err := db.DB.Where("playlist_id = ? AND number = ?", playlistID, number).First(&version).Error; err != nil {
+		return version, nil, ErrVersionNotFound
+	}
+	tracks, err := VersionTracks(version)
+	return version, tracks, err
+}
+
+// replacePlaylistTracks makes tracks the tracks of a playlist, in order. Songs found among the
+// current tracks keep their rows and IDs, so shares, sync checkpoints and activity entries that
+// point at them stay valid; only songs no longer present are deleted.
+func replacePlaylistTracks(tx *gorm.DB, playlistID uuid.UUID, current, tracks []db.Track) ([]db.Track, error) {
+	diff := DiffTracks(current, tracks)
+	kept := make(map[int]db.Track, len(diff.InBoth)) // index in tracks -> current row
+	for _, m := range diff.InBoth {
+		kept[m.PositionB-1] = m.A
+	}
+	if len(diff.OnlyInA) > 0 {
+		removed := make([]uuid.UUID, len(diff.OnlyInA))
+		for i, d := range diff.OnlyInA {
+			removed[i] = d.Track.ID
+		}
+		if err := tx.Where("id IN ?", removed).Delete(&db.Track{}).Error; err != nil {
+			return nil, fmt.Errorf("failed to remove tracks: %w", err)
+		}
+	}
+
+	result := make([]db.Track, 0, len(tracks))
+	var added []db.Track
+	for i, t := range tracks {
+		old, ok := kept[i]
+		if !ok {
+			t = copyTrack(t, playlistID, i+1)
+			added = append(added, t)
+			result = append(result, t)
+			continue
+		}
+		t.ID, t.PlaylistID, t.Position, t.CreatedAt = old.ID, playlistID, i+1, old.CreatedAt
+		if t != old {
+			if err := tx.Save(&t).Error; err != nil {
+				return nil, fmt.Errorf("failed to update track: %w", err)
+			}
+		}
+		result = append(result, t)
+	}
+	if len(added) > 0 {
+		if err := tx.CreateInBatches(&added, 100).Error; err != nil {
+			return nil, fmt.Errorf("failed to add tracks: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// RestorePlaylistVersion replaces the tracks of a playlist with those of an earlier version.
+// The restore itself becomes the newest version, so it can be undone like any other change.
+func RestorePlaylistVersion(playlistID, userID uuid.UUID, number int) ([]db.Track, error) {
+	var restored []db.Track
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		current, err := lockPlaylistTracks(tx, playlistID)
+		if err != nil {
+			return err
+		}
+		var version db.PlaylistVersion
+		if err := tx.Where("playlist_id = ? AND number = ?", playlistID, number).First(&version).Error; err != nil {
+			return ErrVersionNotFound
+		}
+		tracks, err := VersionTracks(version)
+		if err != nil {
+			return err
+		}
+
+		if restored, err = replacePlaylistTracks(tx, playlistID, current, tracks); err != nil {
+			return err
+		}
+
+		if err := LogPlaylistActivity(tx, playlistID, userID, "version_restored", map[string]interface{}{
+			"version": number,
+			"tracks":  len(restored),
+		}); err != nil {
+			return err
+		}
+		return recordVersion(tx, playlistID, userID, VersionTriggerRestore, "version_restored", restored)
+	})
+	return restored, err
+}
+
+// --- PUSHING RESTORES ---
+
+// SyncDestinations returns the platform playlists the user's completed sync jobs wrote this
+// playlist to, keyed by platform. The most recent destination per platform wins.
+func SyncDestinations(userID, playlistID uuid.UUID) (map[string]string, error) {
+	var jobs []db.SyncJob
+	err := db.DB.Where("user_id = ? AND playlist_id = ? AND status = ?", userID, playlistID, "completed").
+		Order("created_at").Find(&jobs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sync jobs: %w", err)
+	}
+
+	destinations := make(map[string]string)
+	for _, job := range jobs {
+		var result map[string]interface{}
+		if json.Unmarshal([]byte(job.Result), &result) != nil {
+			continue
+		}
+		for platform, id := range result {
+			if s, ok := id.(string); ok && s != "" {
+				destinations[platform] = s
+			}
+		}
+	}
+	return destinations, nil
+}
+
+// ReplaceablePlatforms are the platforms ClearSyncDestination supports
+var ReplaceablePlatforms = []string{"spotify", "youtube", "deezer"}
+
+// PrepareSyncJob clears the destination playlists of a job created with Replace before its
+// first run adds tracks. A resumed job that already added tracks is left as it is.
+func PrepareSyncJob(ctx context.Context, user db.User, jobID uuid.UUID) error {
+	var job db.SyncJob
+	if err := db.DB.Where("id = ?", jobID).First(&job).Error; err != nil {
+		return fmt.Errorf("failed to load sync job: %w", err)
+	}
+	if !job.Replace {
+		return nil
+	}
+	var started int64
+	if err := db.DB.Model(&db.SyncCheckpoint{}).Where("job_id = ?", jobID).Count(&started).Error; err != nil {
+		return fmt.Errorf("failed to load sync checkpoints: %w", err)
+	}
+	if started > 0 {
+		return nil
+	}
+
+	var destinations map[string]string
+	if err := json.Unmarshal([]byte(job.Result), &destinations); err != nil {
+		return fmt.Errorf("invalid sync job result: %w", err)
+	}
+	platforms := make([]string, 0, len(destinations))
+	for platform := range destinations {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	for _, platform := range platforms {
+		if err := ClearSyncDestination(ctx, user, platform, destinations[platform]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClearSyncDestination removes every track from a synced platform playlist, so that a sync
+// into it afterwards leaves exactly the playlist's current tracks. Supported for
+// ReplaceablePlatforms.
+func ClearSyncDestination(ctx context.Context, user db.User, platform, playlistID string) error {
+	switch platform {
+	case "spotify":
+		client, err := GetSpotifyClient(ctx, user)
+		if err != nil {
+			return err
+		}
+		if err := client.ReplacePlaylistTracks(ctx, spotify.ID(playlistID)); err != nil {
+			return fmt.Errorf("failed to clear Spotify playlist: %w", err)
+		}
+		return nil
+
+	case "youtube":
+		client, err := GetYouTubeClient(ctx, user)
+		if err != nil {
+			return err
+		}
+		var itemIDs []string
+		pageToken := ""
+		for {
+			response, err := client.PlaylistItems.List([]string{"id"}).PlaylistId(playlistID).MaxResults(50).PageToken(pageToken).Context(ctx).Do()
+			if err != nil {
+				return fmt.Errorf("failed to read YouTube playlist: %w", err)
+			}
+			for _, item := range response.Items {
+				itemIDs = append(itemIDs, item.Id)
+			}
+			if pageToken = response.NextPageToken; pageToken == "" {
+				break
+			}
+		}
+		for _, itemID := range itemIDs {
+			if err := client.PlaylistItems.Delete(itemID).Context(ctx).Do(); err != nil {
+				return fmt.Errorf("failed to remove YouTube playlist item: %w", err)
+			}
+		}
+		return nil
+
+	case "deezer":
+		client, err := GetDeezerClient(ctx, user)
+		if err != nil {
+			return err
+		}
+		tracks, err := GetDeezerPlaylistTracks(ctx, client, playlistID)
+		if err != nil {
+			return fmt.Errorf("failed to read Deezer playlist: %w", err)
+		}
+		var ids []string
+		for _, t := range tracks {
+			if t.DeezerID != "" {
+				ids = append(ids, t.DeezerID)
+			}
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		params := url.Values{}
+		params.Set("songs", strings.Join(ids, ","))
+		if err := client.do(ctx, "DELETE", "/playlist/"+url.PathEscape(playlistID)+"/tracks", params, nil); err != nil {
+			return fmt.Errorf("failed to clear Deezer playlist: %w", err)
+		}
+		return nil
+	}
+	return ErrDestinationReplaceUnsupported
+}
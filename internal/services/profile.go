@@ -0,0 +1,325 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// --- USER PROFILES AND FOLLOWING ---
+
+const (
+	MaxDisplayNameLength = 50
+	MaxBioLength         = 500
+)
+
+// Kinds of FeedItem
+const (
+	FeedItemPublished = "published" // the playlist was made public
+	FeedItemUpdated   = "updated"   // its tracks changed after it was published
+)
+
+// feedCursorSort marks feed cursors, which are keyed by activity time like the newest sort
+const feedCursorSort = "feed"
+
+var (
+	ErrProfileNotFound  = errors.New("profile not found")
+	ErrCannotFollowSelf = errors.New("you cannot follow yourself")
+	ErrInvalidProfile   = errors.New("invalid profile")
+)
+
+const (
+	// A playlist was published when it last became public; older ones fall back to their creation
+	publishedAtExpr = "COALESCE(playlists.published_at, playlists.created_at)"
+	// Feed activity is the later of publishing and the last version of the tracks. Import
+	// versions snapshot the playlist as it was created, so they do not count as updates.
+	feedActivityExpr = "GREATEST(" + publishedAtExpr + ", COALESCE((SELECT MAX(playlist_versions.created_at)" +
+		" FROM playlist_versions WHERE playlist_versions.playlist_id = playlists.id" +
+		" AND playlist_versions.trigger <> '" + VersionTriggerImport + "'), playlists.created_at))"
+)
+
+// ProfileUpdate holds the profile fields to change; nil fields are left as they are
+type ProfileUpdate struct {
+	DisplayName *string
+	AvatarURL   *string
+	Bio         *string
+	Hidden      *bool
+}
+
+// FeedItem is a public playlist of a followed user with what happened to it and when
+type FeedItem struct {
+	Playlist   db.Playlist
+	Owner      db.User
+	Kind       string
+	At         time.Time
+	TrackCount int64
+}
+
+// FeedPage is one page of a feed, newest first. NextCursor is empty on the last page.
+type FeedPage struct {
+	Items      []FeedItem
+	NextCursor string
+}
+
+// ProfileName is the name shown on a user's profile: the display name, else the username
+func ProfileName(user db.User) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	return user.Username
+}
+
+// LoadProfile returns a user whose profile the viewer may see. Hidden profiles are only
+// visible to their owner. viewerID is uuid.Nil for anonymous visitors.
+func LoadProfile(userID, viewerID uuid.UUID) (db.User, error) {
+	var user db.User
+	if err := db.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		return user, ErrProfileNotFound
+	}
+	if user.ProfileHidden && user.ID != viewerID {
+		return db.User{}, ErrProfileNotFound
+	}
+	return user, nil
+}
+
+// UpdateProfile validates and saves changes to a user's profile
+func UpdateProfile(userID uuid.UUID, update ProfileUpdate) (db.User, error) {
+	updates := make(map[string]interface{})
+	if update.DisplayName != nil {
+		name := strings.TrimSpace(*update.DisplayName)
+		if utf8.RuneCountInString(name) > MaxDisplayNameLength {
+			return db.User{}, fmt.Errorf("%w: display name is longer than %d characters", ErrInvalidProfile, MaxDisplayNameLength)
+		}
+		updates["display_name"] = name
+	}
+	if update.AvatarURL != nil {
+		avatar := strings.TrimSpace(*update.AvatarURL)
+		if avatar != "" {
+			u, err := url.Parse(avatar)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return db.User{}, fmt.Errorf("%w: avatar must be an http(s) URL", ErrInvalidProfile)
+			}
+		}
+		updates["avatar_url"] = avatar
+	}
+	if update.Bio != nil {
+		bio := strings.TrimSpace(*update.Bio)
+		if utf8.RuneCountInString(bio) > MaxBioLength {
+			return db.User{}, fmt.Errorf("%w: bio is longer than %d characters", ErrInvalidProfile, MaxBioLength)
+		}
+		updates["bio"] = bio
+	}
+	if update.Hidden != nil {
+		updates["profile_hidden"] = *update.Hidden
+	}
+
+	if len(updates) > 0 {
+		if err := db.DB.Model(&db.User{}).Where("id = ?", userID).Updates(updates).Error; err != nil {
+			return db.User{}, fmt.Errorf("failed to update profile: %w", err)
+		}
+	}
+	return LoadProfile(userID, userID)
+}
+
+// FollowUser makes followerID follow another user. Following someone twice is a no-op.
+func FollowUser(followerID, followeeID uuid.UUID) error {
+	if followerID == followeeID {
+		return ErrCannotFollowSelf
+	}
+	if _, err := LoadProfile(followeeID, followerID); err != nil {
+		return err
+	}
+	err := db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&db.UserFollow{
+		ID:         uuid.New(),
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+		CreatedAt:  time.Now(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to follow user: %w", err)
+	}
+	return nil
+}
+
+// UnfollowUser stops followerID following another user
+func UnfollowUser(followerID, followeeID uuid.UUID) error {
+	err := db.DB.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&db.UserFollow{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to unfollow user: %w", err)
+	}
+	return nil
+}
+
+// IsFollowing reports whether followerID follows followeeID
+func IsFollowing(followerID, followeeID uuid.UUID) bool {
+	var count int64
+	db.DB.Model(&db.UserFollow{}).Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Count(&count)
+	return count > 0
+}
+
+// visibleFollows counts or lists follows whose other side, joined on column, has a visible profile
+func visibleFollows(column string) *gorm.DB {
+	return db.DB.Model(&db.UserFollow{}).
+		Joins("JOIN users ON users.id = user_follows."+column+" AND users.profile_hidden = ? AND users.deleted_at IS NULL", false)
+}
+
+// FollowCounts returns how many users with visible profiles follow a user and are followed by them
+func FollowCounts(userID uuid.UUID) (followers, following int64, err error) {
+	if err = visibleFollows("follower_id").Where("user_follows.followee_id = ?", userID).Count(&followers).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to count followers: %w", err)
+	}
+	if err = visibleFollows("followee_id").Where("user_follows.follower_id = ?", userID).Count(&following).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to count followed users: %w", err)
+	}
+	return followers, following, nil
+}
+
+// FollowedUsers lists the users with visible profiles that a user follows, most recently followed first
+func FollowedUsers(userID uuid.UUID) ([]db.User, error) {
+	return followUsers("followee_id", "follower_id", userID)
+}
+
+// Followers lists the users with visible profiles that follow a user, most recent first
+func Followers(userID uuid.UUID) ([]db.User, error) {
+	return followUsers("follower_id", "followee_id", userID)
+}
+
+func followUsers(column, byColumn string, userID uuid.UUID) ([]db.User, error) {
+	var ids []uuid.UUID
+	err := visibleFollows(column).Where("user_follows."+byColumn+" = ?", userID).
+		Order("user_follows.created_at DESC").Pluck("user_follows."+column, &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch follows: %w", err)
+	}
+	if len(ids) == 0 {
+		return []db.User{}, nil
+	}
+
+	var users []db.User
+	if err := db.DB.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch users: %w", err)
+	}
+	byID := make(map[uuid.UUID]db.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	ordered := make([]db.User, 0, len(ids))
+	for _, id := range ids {
+		if u, ok := byID[id]; ok {
+			ordered = append(ordered, u)
+		}
+	}
+	return ordered, nil
+}
+
+// PublicProfiles returns the users with visible profiles among ids, keyed by ID
+func PublicProfiles(ids []uuid.UUID) (map[uuid.UUID]db.User, error) {
+	profiles := make(map[uuid.UUID]db.User)
+	if len(ids) == 0 {
+		return profiles, nil
+	}
+	var users []db.User
+	if err := db.DB.Where("id IN ? AND profile_hidden = ?", ids, false).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch profiles: %w", err)
+	}
+	for _, u := range users {
+		profiles[u.ID] = u
+	}
+	return profiles, nil
+}
+
+// FollowFeed returns a page of the public playlists of the users someone follows, most recently
+// published or updated first. Users who hid their profile are left out.
+func FollowFeed(userID uuid.UUID, cursor string, limit int) (FeedPage, error) {
+	page := FeedPage{Items: []FeedItem{}}
+	if limit <= 0 {
+		limit = DefaultPlaylistPageSize
+	}
+	limit = min(limit, MaxPlaylistPageSize)
+
+	query := db.DB.Model(&db.Playlist{}).
+		Select("playlists.id, "+publishedAtExpr+" AS published_at, "+feedActivityExpr+" AS activity_at").
+		Joins("JOIN user_follows ON user_follows.followee_id = playlists.owner_id AND user_follows.follower_id = ?", userID).
+		Joins("JOIN users ON users.id = playlists.owner_id AND users.profile_hidden = ? AND users.deleted_at IS NULL", false).
+		Where("playlists.is_public = ?", true)
+	if cursor != "" {
+		value, id, err := decodePlaylistCursor(cursor, feedCursorSort)
+		if err != nil {
+			return page, err
+		}
+		query = query.Where("("+feedActivityExpr+", playlists.id) < (?, ?)", value, id)
+	}
+
+	// One extra row tells whether there is a next page
+	var rows []struct {
+		ID          uuid.UUID
+		PublishedAt time.Time
+		ActivityAt  time.Time
+	}
+	err := query.Order("activity_at DESC, playlists.id DESC").Limit(limit + 1).Scan(&rows).Error
+	if err != nil {
+		return page, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+	if len(rows) == 0 {
+		return page, nil
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, r := range rows {
+		ids[i] = r.ID
+	}
+	var playlists []db.Playlist
+	if err := db.DB.Where("id IN ?", ids).Find(&playlists).Error; err != nil {
+		return page, fmt.Errorf("failed to fetch playlists: %w", err)
+	}
+	byID := make(map[uuid.UUID]db.Playlist, len(playlists))
+	ownerIDs := make([]uuid.UUID, 0, len(playlists))
+	for _, p := range playlists {
+		byID[p.ID] = p
+		ownerIDs = append(ownerIDs, p.OwnerID)
+	}
+	counts, _, err := playlistCounts(playlists)
+	if err != nil {
+		return page, err
+	}
+	owners, err := PublicProfiles(ownerIDs)
+	if err != nil {
+		return page, err
+	}
+
+	for _, r := range rows {
+		p, ok := byID[r.ID]
+		if !ok {
+			continue
+		}
+		kind := FeedItemPublished
+		if r.ActivityAt.After(r.PublishedAt) {
+			kind = FeedItemUpdated
+		}
+		page.Items = append(page.Items, FeedItem{
+			Playlist:   p,
+			Owner:      owners[p.OwnerID],
+			Kind:       kind,
+			At:         r.ActivityAt,
+			TrackCount: counts[p.ID],
+		})
+	}
+	if hasMore {
+		last := rows[len(rows)-1]
+		page.NextCursor = encodePlaylistCursor(feedCursorSort, last.ActivityAt, last.ID)
+	}
+	return page, nil
+}
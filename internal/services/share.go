@@ -0,0 +1,108 @@
+package services
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// --- SHARE LINKS ---
+
+const (
+	shareCodeAlphabet = "0123456789abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ" // no l, I, O
+	shareCodeLength   = 7
+)
+
+// NewShareCode returns a random short code that is not yet used by any share
+func NewShareCode() (string, error) {
+	max := big.NewInt(int64(len(shareCodeAlphabet)))
+	for attempt := 0; attempt < 5; attempt++ {
+		code := make([]byte, shareCodeLength)
+		for i := range code {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return "", fmt.Errorf("failed to generate share code: %w", err)
+			}
+			code[i] = shareCodeAlphabet[n.Int64()]
+		}
+
+		var count int64
+		if err := db.DB.Model(&db.Share{}).Where("code = ?", string(code)).Count(&count).Error; err != nil {
+			return "", fmt.Errorf("failed to check share code: %w", err)
+		}
+		if count == 0 {
+			return string(code), nil
+		}
+	}
+	return "", fmt.Errorf("failed to generate a unique share code")
+}
+
+// RecordShareView counts a landing page view
+func RecordShareView(shareID uuid.UUID) error {
+	return db.DB.Model(&db.Share{}).Where("id = ?", shareID).UpdateColumn("views", gorm.Expr("views + 1")).Error
+}
+
+// RecordShareClick counts a visitor following a share to a platform
+func RecordShareClick(shareID uuid.UUID, platform string) error {
+	return db.DB.Create(&db.ShareClick{
+		ID:        uuid.New(),
+		ShareID:   shareID,
+		Platform:  platform,
+		CreatedAt: time.Now(),
+	}).Error
+}
+
+// ShareClickCounts returns the number of clicks per platform for a share
+func ShareClickCounts(shareID uuid.UUID) (map[string]int64, error) {
+	var rows []struct {
+		Platform string
+		Count    int64
+	}
+	err := db.DB.Model(&db.ShareClick{}).
+		Select("platform, COUNT(*) AS count").
+		Where("share_id = ?", shareID).
+		Group("platform").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count share clicks: %w", err)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, r := range rows {
+		counts[r.Platform] = r.Count
+	}
+	return counts, nil
+}
+
+// TrackArtwork returns cover art for a track: the resolver cache first, then the playlist cover
+func TrackArtwork(track db.Track) string {
+	var resolved db.ResolvedTrack
+	query := db.DB.Where("artwork_url <> ''")
+	switch {
+	case track.SpotifyID != "":
+		query = query.Where("spotify_id = ?", track.SpotifyID)
+	case track.DeezerID != "":
+		query = query.Where("deezer_id = ?", track.DeezerID)
+	case track.AppleMusicID != "":
+		query = query.Where("applemusic_id = ?", track.AppleMusicID)
+	case track.ISRC != "":
+		query = query.Where("isrc = ?", track.ISRC)
+	default:
+		query = nil
+	}
+	if query != nil && query.First(&resolved).Error == nil {
+		return resolved.ArtworkURL
+	}
+
+	var playlist db.Playlist
+	if err := db.DB.Select("cover_image").Where("id = ?", track.PlaylistID).First(&playlist).Error; err == nil {
+		return playlist.CoverImage
+	}
+	return ""
+}
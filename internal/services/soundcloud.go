@@ -0,0 +1,482 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+)
+
+// --- CONFIGURATION ---
+
+var soundCloudAPIURL = "https://api.soundcloud.com"
+
+// SoundCloudLikesID is the SourceID used for a user's liked tracks, which SoundCloud
+// exposes as a collection rather than a playlist
+const SoundCloudLikesID = "likes"
+
+// GetSoundCloudOAuthConfig returns the SoundCloud OAuth config. SoundCloud requires PKCE
+// for the authorization code flow, see auth.SoundCloudLink.
+func GetSoundCloudOAuthConfig() *oauth2.Config {
+	redirectURL := os.Getenv("SOUNDCLOUD_REDIRECT_URL")
+	if redirectURL == "" {
+		redirectURL = "http://127.0.0.1:8000/callback/soundcloud"
+	}
+	return &oauth2.Config{
+		ClientID:     os.Getenv("SOUNDCLOUD_CLIENT_ID"),
+		ClientSecret: os.Getenv("SOUNDCLOUD_CLIENT_SECRET"),
+		RedirectURL:  redirectURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   "https://secure.soundcloud.com/authorize",
+			TokenURL:  "https://secure.soundcloud.com/oauth/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+}
+
+// SoundCloudClient calls the SoundCloud public API
+type SoundCloudClient struct {
+	httpClient *http.Client
+}
+
+type soundCloudUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type soundCloudTrack struct {
+	ID                int64          `json:"id"`
+	Title             string         `json:"title"`
+	Duration          int            `json:"duration"` // milliseconds
+	PermalinkURL      string         `json:"permalink_url"`
+	ArtworkURL        string         `json:"artwork_url"`
+	User              soundCloudUser `json:"user"`
+	PublisherMetadata *struct {
+		Artist     string `json:"artist"`
+		AlbumTitle string `json:"album_title"`
+		ISRC       string `json:"isrc"`
+	} `json:"publisher_metadata"`
+}
+
+type soundCloudPlaylist struct {
+	ID           int64  `json:"id"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	ArtworkURL   string `json:"artwork_url"`
+	PermalinkURL string `json:"permalink_url"`
+}
+
+// soundCloudPage is a linked_partitioning response
+type soundCloudPage[T any] struct {
+	Collection []T    `json:"collection"`
+	NextHref   string `json:"next_href"`
+}
+
+// --- SOUNDCLOUD FUNCTIONS ---
+
+// GetSoundCloudClient returns a SoundCloud client for a user
+func GetSoundCloudClient(ctx context.Context, user db.User) (*SoundCloudClient, error) {
+	if user.SoundCloudToken == "" {
+		return nil, fmt.Errorf("no SoundCloud token available")
+	}
+	var token oauth2.Token
+	if err := json.Unmarshal([]byte(user.SoundCloudToken), &token); err != nil {
+		return nil, fmt.Errorf("invalid SoundCloud token: %w", err)
+	}
+	return NewSoundCloudClient(GetSoundCloudOAuthConfig().Client(ctx, &token)), nil
+}
+
+// NewSoundCloudClient wraps an authenticated HTTP client
+func NewSoundCloudClient(httpClient *http.Client) *SoundCloudClient {
+	return &SoundCloudClient{httpClient: httpClient}
+}
+
+func (c *SoundCloudClient) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode SoundCloud request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	endpoint := path
+	if !strings.HasPrefix(endpoint, "http") {
+		endpoint = soundCloudAPIURL + path
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create SoundCloud request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json; charset=utf-8")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("SoundCloud request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("SoundCloud API returned %d: %s", resp.StatusCode, string(respBody))
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode SoundCloud response: %w", err)
+	}
+	return nil
+}
+
+// GetSoundCloudUser returns the ID and username of the authenticated user
+func GetSoundCloudUser(ctx context.Context, client *SoundCloudClient) (string, string, error) {
+	var me soundCloudUser
+	if err := client.do(ctx, "GET", "/me", nil, &me); err != nil {
+		return "", "", fmt.Errorf("failed to get SoundCloud user: %w", err)
+	}
+	return strconv.FormatInt(me.ID, 10), me.Username, nil
+}
+
+// soundCloudArtistTitle applies SoundCloud's uploader-as-artist convention. Label and
+// distributor uploads carry publisher metadata; otherwise many uploads are titled
+// "Artist - Title", and the rest are best attributed to the uploader.
+func soundCloudArtistTitle(t soundCloudTrack) (string, string) {
+	if t.PublisherMetadata != nil && t.PublisherMetadata.Artist != "" {
+		return t.PublisherMetadata.Artist, t.Title
+	}
+	if artist, title, ok := strings.Cut(t.Title, " - "); ok && artist != "" && title != "" {
+		return strings.TrimSpace(artist), strings.TrimSpace(title)
+	}
+	return t.User.Username, t.Title
+}
+
+func (t soundCloudTrack) toTrack() db.Track {
+	artist, title := soundCloudArtistTitle(t)
+	track := db.Track{
+		ID:            uuid.New(),
+		Title:         title,
+		Artist:        artist,
+		SoundCloudID:  strconv.FormatInt(t.ID, 10),
+		SoundCloudURL: t.PermalinkURL,
+		DurationMs:    t.Duration,
+		CreatedAt:     time.Now(),
+	}
+	if t.PublisherMetadata != nil {
+		track.Album = t.PublisherMetadata.AlbumTitle
+		track.ISRC = t.PublisherMetadata.ISRC
+	}
+	return track
+}
+
+func (p soundCloudPlaylist) toPlaylist() db.Playlist {
+	return db.Playlist{
+		ID:          uuid.New(),
+		Title:       p.Title,
+		Description: p.Description,
+		Platform:    "soundcloud",
+		SourceID:    strconv.FormatInt(p.ID, 10),
+		IsPublic:    false,
+		CoverImage:  p.ArtworkURL,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// soundCloudLikesPlaylist describes the user's likes as a playlist
+func soundCloudLikesPlaylist() db.Playlist {
+	return db.Playlist{
+		ID:          uuid.New(),
+		Title:       "SoundCloud Likes",
+		Description: "Tracks liked on SoundCloud",
+		Platform:    "soundcloud",
+		SourceID:    SoundCloudLikesID,
+		IsPublic:    false,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// GetSoundCloudPlaylists retrieves the user's sets, plus their likes as a pseudo-playlist
+func GetSoundCloudPlaylists(ctx context.Context, client *SoundCloudClient) ([]db.Playlist, error) {
+	playlists := []db.Playlist{soundCloudLikesPlaylist()}
+	next := "/me/playlists?show_tracks=false&linked_partitioning=true&limit=50"
+	for next != "" {
+		var page soundCloudPage[soundCloudPlaylist]
+		if err := client.do(ctx, "GET", next, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to get SoundCloud playlists: %w", err)
+		}
+		for _, p := range page.Collection {
+			playlists = append(playlists, p.toPlaylist())
+		}
+		next = page.NextHref
+	}
+	return playlists, nil
+}
+
+// GetSoundCloudPlaylist retrieves a single set's metadata
+func GetSoundCloudPlaylist(ctx context.Context, client *SoundCloudClient, playlistID string) (db.Playlist, error) {
+	if playlistID == SoundCloudLikesID {
+		return soundCloudLikesPlaylist(), nil
+	}
+	var p soundCloudPlaylist
+	if err := client.do(ctx, "GET", fmt.Sprintf("/playlists/%s?show_tracks=false", url.PathEscape(playlistID)), nil, &p); err != nil {
+		return db.Playlist{}, fmt.Errorf("failed to fetch SoundCloud playlist: %w", err)
+	}
+	return p.toPlaylist(), nil
+}
+
+// GetSoundCloudPlaylistTracks retrieves the tracks of a set, or the user's likes
+func GetSoundCloudPlaylistTracks(ctx context.Context, client *SoundCloudClient, playlistID string) ([]db.Track, error) {
+	next := fmt.Sprintf("/playlists/%s/tracks?linked_partitioning=true&limit=200", url.PathEscape(playlistID))
+	if playlistID == SoundCloudLikesID {
+		next = "/me/likes/tracks?linked_partitioning=true&limit=200"
+	}
+
+	var tracks []db.Track
+	for next != "" {
+		var page soundCloudPage[soundCloudTrack]
+		if err := client.do(ctx, "GET", next, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to get SoundCloud tracks: %w", err)
+		}
+		for _, t := range page.Collection {
+			tracks = append(tracks, t.toTrack())
+		}
+		next = page.NextHref
+	}
+	return tracks, nil
+}
+
+// SearchSoundCloudTrack finds a track. Since the artist may only appear as the uploader or
+// in an "Artist - Title" upload title, candidates are matched against all three. It returns ""
+// when no candidate matches, rather than guessing.
+func SearchSoundCloudTrack(ctx context.Context, client *SoundCloudClient, title, artist string) (string, error) {
+	params := url.Values{}
+	params.Set("q", fmt.Sprintf("%s %s", artist, title))
+	params.Set("limit", "10")
+	params.Set("linked_partitioning", "true")
+
+	var page soundCloudPage[soundCloudTrack]
+	if err := client.do(ctx, "GET", "/tracks?"+params.Encode(), nil, &page); err != nil {
+		return "", fmt.Errorf("failed to search SoundCloud track: %w", err)
+	}
+	if len(page.Collection) == 0 {
+		return "", nil
+	}
+
+	wantArtist := strings.ToLower(artist)
+	wantTitle := strings.ToLower(title)
+	if wantArtist == "" || wantTitle == "" {
+		return "", nil
+	}
+	for _, t := range page.Collection {
+		candArtist, candTitle := soundCloudArtistTitle(t)
+		if !strings.Contains(strings.ToLower(candTitle), wantTitle) {
+			continue
+		}
+		if (candArtist != "" && strings.Contains(wantArtist, strings.ToLower(candArtist))) ||
+			strings.Contains(strings.ToLower(t.User.Username), wantArtist) {
+			return strconv.FormatInt(t.ID, 10), nil
+		}
+	}
+	return "", nil
+}
+
+// CreateSoundCloudPlaylist creates a private set
+func CreateSoundCloudPlaylist(ctx context.Context, client *SoundCloudClient, title, description string) (string, error) {
+	body := map[string]interface{}{
+		"playlist": map[string]interface{}{
+			"title":       title,
+			"description": description,
+			"sharing":     "private",
+			"tracks":      []interface{}{},
+		},
+	}
+	var p soundCloudPlaylist
+	if err := client.do(ctx, "POST", "/playlists", body, &p); err != nil {
+		return "", fmt.Errorf("failed to create SoundCloud playlist: %w", err)
+	}
+	return strconv.FormatInt(p.ID, 10), nil
+}
+
+// AddSoundCloudPlaylistTracks appends tracks to a set. SoundCloud only supports replacing
+// the whole track list, so the current list is read first.
+func AddSoundCloudPlaylistTracks(ctx context.Context, client *SoundCloudClient, playlistID string, trackIDs ...string) error {
+	existing, err := GetSoundCloudPlaylistTracks(ctx, client, playlistID)
+	if err != nil {
+		return err
+	}
+
+	var list []map[string]string
+	for _, t := range existing {
+		list = append(list, map[string]string{"id": t.SoundCloudID})
+	}
+	for _, id := range trackIDs {
+		list = append(list, map[string]string{"id": id})
+	}
+
+	body := map[string]interface{}{
+		"playlist": map[string]interface{}{"tracks": list},
+	}
+	if err := client.do(ctx, "PUT", fmt.Sprintf("/playlists/%s", url.PathEscape(playlistID)), body, nil); err != nil {
+		return fmt.Errorf("failed to add tracks to SoundCloud playlist: %w", err)
+	}
+	return nil
+}
+
+// ImportToSoundCloud creates a SoundCloud set and adds tracks
+func ImportToSoundCloud(ctx context.Context, client *SoundCloudClient, playlist db.Playlist, tracks []db.Track) (string, error) {
+	return syncToSoundCloud(ctx, client, playlist, tracks, uuid.Nil)
+}
+
+// syncToSoundCloud matches tracks and appends them in chunks, since every append rewrites
+// the whole set
+func syncToSoundCloud(ctx context.Context, client *SoundCloudClient, playlist db.Playlist, tracks []db.Track, jobID uuid.UUID) (string, error) {
+	scPlaylistID := loadSyncDestination(jobID, "soundcloud")
+	if scPlaylistID == "" {
+		var err error
+		scPlaylistID, err = CreateSoundCloudPlaylist(ctx, client, playlist.Title, playlist.Description)
+		if err != nil {
+			return "", err
+		}
+		SaveSyncDestination(jobID, "soundcloud", scPlaylistID)
+	}
+
+	checkpoints, err := loadSyncCheckpoints(jobID, "soundcloud")
+	if err != nil {
+		return scPlaylistID, err
+	}
+
+	type match struct {
+		trackID uuid.UUID
+		scID    string
+	}
+	var pending []match
+	for _, track := range tracks {
+		if checkpoints.isDone(track.ID) {
+			continue
+		}
+		scID := track.SoundCloudID
+		if scID == "" {
+			scID, err = SearchSoundCloudTrack(ctx, client, track.Title, track.Artist)
+			if err != nil {
+				if strings.Contains(err.Error(), "429") {
+					return scPlaylistID, fmt.Errorf("SoundCloud rate limit reached: %w", err)
+				}
+				log.Printf("   ⚠️ SoundCloud search failed for %s - %s: %v", track.Title, track.Artist, err)
+				checkpoints.record(track.ID, "failed", "")
+				continue
+			}
+			if scID == "" {
+				log.Printf("   ⚠️ Not found on SoundCloud: %s - %s", track.Title, track.Artist)
+				checkpoints.record(track.ID, "not_found", "")
+				continue
+			}
+		}
+		pending = append(pending, match{trackID: track.ID, scID: scID})
+	}
+
+	chunkSize := 50
+	for i := 0; i < len(pending); i += chunkSize {
+		end := i + chunkSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		var ids []string
+		for _, m := range pending[i:end] {
+			ids = append(ids, m.scID)
+		}
+		if err := AddSoundCloudPlaylistTracks(ctx, client, scPlaylistID, ids...); err != nil {
+			return scPlaylistID, err
+		}
+		for _, m := range pending[i:end] {
+			checkpoints.record(m.trackID, "added", m.scID)
+		}
+	}
+	log.Printf("🏁 SoundCloud Sync Finished. Added %d/%d songs.", len(pending), len(tracks))
+	return scPlaylistID, nil
+}
+
+// ImportSoundCloudPlaylist imports a single SoundCloud set (or the likes) to DB
+func ImportSoundCloudPlaylist(ctx context.Context, client *SoundCloudClient, user db.User, sourceID string) error {
+	playlist, err := GetSoundCloudPlaylist(ctx, client, sourceID)
+	if err != nil {
+		return err
+	}
+	playlist.OwnerID = user.ID
+
+	var existing db.Playlist
+	if err := db.DB.Where("owner_id = ? AND source_id = ? AND platform = 'soundcloud'", user.ID, sourceID).First(&existing).Error; err == nil {
+		playlist = existing
+	} else {
+		if err := db.DB.Create(&playlist).Error; err != nil {
+			return fmt.Errorf("failed to create playlist in DB: %w", err)
+		}
+	}
+
+	tracks, err := GetSoundCloudPlaylistTracks(ctx, client, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch tracks: %w", err)
+	}
+
+	for _, t := range tracks {
+		t.PlaylistID = playlist.ID
+		db.DB.Create(&t)
+	}
+	RecordImportVersion(playlist.ID, user.ID)
+	return nil
+}
+
+// ImportAllSoundCloudPlaylists imports all sets and likes for a user
+func ImportAllSoundCloudPlaylists(ctx context.Context, user db.User) error {
+	client, err := GetSoundCloudClient(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	playlists, err := GetSoundCloudPlaylists(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range playlists {
+		p.OwnerID = user.ID
+		var existing db.Playlist
+		if err := db.DB.Where("owner_id = ? AND source_id = ? AND platform = 'soundcloud'", user.ID, p.SourceID).First(&existing).Error; err == nil {
+			continue
+		}
+
+		if err := db.DB.Create(&p).Error; err != nil {
+			log.Printf("Failed to create playlist %s: %v", p.Title, err)
+			continue
+		}
+
+		tracks, err := GetSoundCloudPlaylistTracks(ctx, client, p.SourceID)
+		if err != nil {
+			log.Printf("Failed to get tracks for %s: %v", p.Title, err)
+			continue
+		}
+
+		for _, t := range tracks {
+			t.PlaylistID = p.ID
+			db.DB.Create(&t)
+		}
+		RecordImportVersion(p.ID, user.ID)
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"EchoBridge/db"
@@ -15,6 +16,7 @@ import (
 	"github.com/zmb3/spotify/v2"
 	spotifyauth "github.com/zmb3/spotify/v2/auth"
 	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
 )
 
 // --- CONFIGURATION ---
@@ -54,6 +56,41 @@ func GetSpotifyClient(ctx context.Context, user db.User) (*spotify.Client, error
 	return spotify.New(spotifyAuth.Client(ctx, &token)), nil
 }
 
+var (
+	spotifyAppTokenOnce   sync.Once
+	spotifyAppTokenSource oauth2.TokenSource
+)
+
+// GetSpotifyAppClient returns a client authenticated as the app itself (client-credentials flow).
+// It can read public playlists but has no user context, so it cannot write. Spotify does not
+// serve its own editorial and algorithmic playlists to such clients.
+func GetSpotifyAppClient(ctx context.Context) (*spotify.Client, error) {
+	clientID := os.Getenv("SPOTIFY_CLIENT_ID")
+	clientSecret := os.Getenv("SPOTIFY_CLIENT_SECRET")
+	if clientID == "" || clientSecret == "" {
+		return nil, fmt.Errorf("Spotify app credentials not configured")
+	}
+	spotifyAppTokenOnce.Do(func() {
+		config := &clientcredentials.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			TokenURL:     spotifyauth.TokenURL,
+		}
+		// The token is shared by all requests, so it must not be tied to one request's context
+		spotifyAppTokenSource = config.TokenSource(context.Background())
+	})
+	return spotify.New(oauth2.NewClient(ctx, spotifyAppTokenSource)), nil
+}
+
+// GetSpotifyReadClient returns the user's client when Spotify is linked, otherwise the app
+// client, so public playlists can be imported without linking Spotify
+func GetSpotifyReadClient(ctx context.Context, user db.User) (*spotify.Client, error) {
+	if user.SpotifyToken != "" {
+		return GetSpotifyClient(ctx, user)
+	}
+	return GetSpotifyAppClient(ctx)
+}
+
 // GetSpotifyPlaylistTracks retrieves tracks from a Spotify playlist
 func GetSpotifyPlaylistTracks(ctx context.Context, client *spotify.Client, playlistID string) ([]db.Track, error) {
 	tracksPage, err := client.GetPlaylistTracks(ctx, spotify.ID(playlistID))
@@ -70,6 +107,8 @@ func GetSpotifyPlaylistTracks(ctx context.Context, client *spotify.Client, playl
 				Artist:     strings.Join(GetArtists(item.Track.Artists), ", "),
 				Album:      item.Track.Album.Name,
 				SpotifyID:  item.Track.ID.String(),
+				ISRC:       item.Track.ExternalIDs["isrc"],
+				DurationMs: int(item.Track.Duration),
 				PreviewURL: item.Track.PreviewURL,
 				CreatedAt:  time.Now(),
 			})
@@ -140,36 +179,100 @@ func ImportAllSpotifyPlaylists(ctx context.Context, user db.User) error {
 			t.PlaylistID = p.ID
 			db.DB.Create(&t)
 		}
+		RecordImportVersion(p.ID, user.ID)
 	}
 	return nil
 }
 
 // ImportToSpotify creates a Spotify playlist and adds tracks
 func ImportToSpotify(ctx context.Context, client *spotify.Client, user db.User, playlist db.Playlist, tracks []db.Track) (string, error) {
-	spPlaylist, err := client.CreatePlaylistForUser(ctx, user.SpotifyID, playlist.Title, playlist.Description, playlist.IsPublic, false)
+	return syncToSpotify(ctx, client, user, playlist, tracks, uuid.Nil)
+}
+
+// syncToSpotify adds tracks to the job's Spotify playlist, creating it on the first run.
+// Tracks checkpointed by an earlier run of the same job are skipped.
+func syncToSpotify(ctx context.Context, client *spotify.Client, user db.User, playlist db.Playlist, tracks []db.Track, jobID uuid.UUID) (string, error) {
+	spPlaylistID := loadSyncDestination(jobID, "spotify")
+	if spPlaylistID == "" {
+		spPlaylist, err := client.CreatePlaylistForUser(ctx, user.SpotifyID, playlist.Title, playlist.Description, playlist.IsPublic, false)
+		if err != nil {
+			return "", fmt.Errorf("failed to create Spotify playlist: %w", err)
+		}
+		spPlaylistID = spPlaylist.ID.String()
+		SaveSyncDestination(jobID, "spotify", spPlaylistID)
+	}
+
+	checkpoints, err := loadSyncCheckpoints(jobID, "spotify")
 	if err != nil {
-		return "", fmt.Errorf("failed to create Spotify playlist: %w", err)
+		return spPlaylistID, err
 	}
 
-	var trackIDs []spotify.ID
+	// Tracks from other sources (history playlists, files, other platforms) are matched first
+	var pending []db.Track
 	for _, t := range tracks {
-		if t.SpotifyID != "" {
-			trackIDs = append(trackIDs, spotify.ID(t.SpotifyID))
+		if checkpoints.isDone(t.ID) {
+			continue
+		}
+		if t.SpotifyID == "" {
+			spotifyID, err := SearchSpotifyTrack(ctx, client, t.Title, t.Artist, t.ISRC)
+			if err != nil {
+				if strings.Contains(err.Error(), "429") {
+					return spPlaylistID, fmt.Errorf("Spotify rate limit reached: %w", err)
+				}
+				log.Printf("   ⚠️ Spotify search failed for %s - %s: %v", t.Title, t.Artist, err)
+				checkpoints.record(t.ID, "failed", "")
+				continue
+			}
+			if spotifyID == "" {
+				checkpoints.record(t.ID, "not_found", "")
+				continue
+			}
+			t.SpotifyID = spotifyID
 		}
+		pending = append(pending, t)
 	}
 
 	chunkSize := 100
-	for i := 0; i < len(trackIDs); i += chunkSize {
+	for i := 0; i < len(pending); i += chunkSize {
 		end := i + chunkSize
-		if end > len(trackIDs) {
-			end = len(trackIDs)
+		if end > len(pending) {
+			end = len(pending)
+		}
+		var trackIDs []spotify.ID
+		for _, t := range pending[i:end] {
+			trackIDs = append(trackIDs, spotify.ID(t.SpotifyID))
+		}
+		if _, err := client.AddTracksToPlaylist(ctx, spotify.ID(spPlaylistID), trackIDs...); err != nil {
+			return spPlaylistID, fmt.Errorf("failed to add tracks to Spotify playlist: %w", err)
+		}
+		for _, t := range pending[i:end] {
+			checkpoints.record(t.ID, "added", t.SpotifyID)
 		}
-		_, err = client.AddTracksToPlaylist(ctx, spPlaylist.ID, trackIDs[i:end]...)
+	}
+	return spPlaylistID, nil
+}
+
+// SearchSpotifyTrack finds a track by ISRC when known, otherwise by title and artist
+func SearchSpotifyTrack(ctx context.Context, client *spotify.Client, title, artist, isrc string) (string, error) {
+	if isrc != "" {
+		results, err := client.Search(ctx, "isrc:"+isrc, spotify.SearchTypeTrack, spotify.Limit(1))
 		if err != nil {
-			return "", fmt.Errorf("failed to add tracks to Spotify playlist: %w", err)
+			return "", fmt.Errorf("failed to search Spotify track: %w", err)
+		}
+		if results.Tracks != nil && len(results.Tracks.Tracks) > 0 {
+			return results.Tracks.Tracks[0].ID.String(), nil
 		}
 	}
-	return spPlaylist.ID.String(), nil
+
+	query := fmt.Sprintf("track:%s artist:%s", title, artist)
+	results, err := client.Search(ctx, query, spotify.SearchTypeTrack, spotify.Limit(1))
+	if err != nil {
+		return "", fmt.Errorf("failed to search Spotify track: %w", err)
+	}
+	if results.Tracks != nil && len(results.Tracks.Tracks) > 0 {
+		return results.Tracks.Tracks[0].ID.String(), nil
+	}
+	return "", nil
 }
 
 // ImportSpotifyPlaylist imports a single Spotify playlist to DB
@@ -213,6 +316,7 @@ func ImportSpotifyPlaylist(ctx context.Context, client *spotify.Client, user db.
 		t.PlaylistID = playlist.ID
 		db.DB.Create(&t)
 	}
+	RecordImportVersion(playlist.ID, user.ID)
 	return nil
 }
 
@@ -0,0 +1,436 @@
+package services
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+)
+
+// --- CONFIGURATION ---
+
+const (
+	subsonicAPIVersion = "1.16.1"
+	subsonicClientName = "EchoBridge"
+)
+
+// SubsonicClient talks to a Subsonic-compatible server (Navidrome, Airsonic, Gonic, ...)
+// using token authentication. The password itself is never stored: only md5(password+salt)
+// and the salt, see NewSubsonicCredentials.
+type SubsonicClient struct {
+	httpClient *http.Client
+	serverURL  string
+	username   string
+	token      string
+	salt       string
+}
+
+// subsonicResponse is the envelope every endpoint returns with f=json
+type subsonicResponse struct {
+	Response struct {
+		Status string `json:"status"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+		Playlists *struct {
+			Playlist []subsonicPlaylist `json:"playlist"`
+		} `json:"playlists"`
+		Playlist      *subsonicPlaylist `json:"playlist"`
+		SearchResult3 *struct {
+			Song []subsonicSong `json:"song"`
+		} `json:"searchResult3"`
+	} `json:"subsonic-response"`
+}
+
+type subsonicPlaylist struct {
+	ID      string         `json:"id"`
+	Name    string         `json:"name"`
+	Comment string         `json:"comment"`
+	Public  bool           `json:"public"`
+	Entry   []subsonicSong `json:"entry"`
+}
+
+type subsonicSong struct {
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	Artist        string   `json:"artist"`
+	Album         string   `json:"album"`
+	Duration      int      `json:"duration"` // seconds
+	MusicBrainzID string   `json:"musicBrainzId"`
+	ISRC          []string `json:"isrc"` // OpenSubsonic extension
+}
+
+// NewSubsonicCredentials derives the token/salt pair for a password
+func NewSubsonicCredentials(password string) (token, salt string, err error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	salt = hex.EncodeToString(buf)
+	sum := md5.Sum([]byte(password + salt))
+	return hex.EncodeToString(sum[:]), salt, nil
+}
+
+// NormalizeSubsonicURL trims trailing slashes and a trailing /rest from a server URL
+func NormalizeSubsonicURL(serverURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(serverURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid server URL: %s", serverURL)
+	}
+	u.Path = strings.TrimSuffix(strings.TrimRight(u.Path, "/"), "/rest")
+	u.RawQuery = ""
+	return strings.TrimRight(u.String(), "/"), nil
+}
+
+// --- SUBSONIC FUNCTIONS ---
+
+// GetSubsonicClient returns a Subsonic client for a user
+func GetSubsonicClient(ctx context.Context, user db.User) (*SubsonicClient, error) {
+	if user.SubsonicURL == "" || user.SubsonicToken == "" {
+		return nil, fmt.Errorf("no Subsonic server linked")
+	}
+	return NewSubsonicClient(user.SubsonicURL, user.SubsonicUsername, user.SubsonicToken, user.SubsonicSalt), nil
+}
+
+// NewSubsonicClient builds a client from stored credentials
+func NewSubsonicClient(serverURL, username, token, salt string) *SubsonicClient {
+	return &SubsonicClient{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		serverURL:  serverURL,
+		username:   username,
+		token:      token,
+		salt:       salt,
+	}
+}
+
+// call invokes a Subsonic REST method. Repeated parameters (songId, songIdToAdd) are
+// passed through url.Values as-is.
+func (c *SubsonicClient) call(ctx context.Context, method string, params url.Values) (*subsonicResponse, error) {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("u", c.username)
+	params.Set("t", c.token)
+	params.Set("s", c.salt)
+	params.Set("v", subsonicAPIVersion)
+	params.Set("c", subsonicClientName)
+	params.Set("f", "json")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/rest/%s.view?%s", c.serverURL, method, params.Encode()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Subsonic request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Subsonic request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Subsonic server returned %d for %s", resp.StatusCode, method)
+	}
+
+	var result subsonicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode Subsonic response: %w", err)
+	}
+	if result.Response.Status != "ok" {
+		if result.Response.Error != nil {
+			return nil, fmt.Errorf("Subsonic error %d: %s", result.Response.Error.Code, result.Response.Error.Message)
+		}
+		return nil, fmt.Errorf("Subsonic %s failed", method)
+	}
+	return &result, nil
+}
+
+// PingSubsonic verifies the server URL and credentials
+func PingSubsonic(ctx context.Context, client *SubsonicClient) error {
+	_, err := client.call(ctx, "ping", nil)
+	return err
+}
+
+func (p subsonicPlaylist) toPlaylist() db.Playlist {
+	return db.Playlist{
+		ID:          uuid.New(),
+		Title:       p.Name,
+		Description: p.Comment,
+		Platform:    "subsonic",
+		SourceID:    p.ID,
+		IsPublic:    false,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// GetSubsonicPlaylists retrieves the playlists visible to the user (getPlaylists)
+func GetSubsonicPlaylists(ctx context.Context, client *SubsonicClient) ([]db.Playlist, error) {
+	result, err := client.call(ctx, "getPlaylists", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Subsonic playlists: %w", err)
+	}
+
+	var playlists []db.Playlist
+	if result.Response.Playlists != nil {
+		for _, p := range result.Response.Playlists.Playlist {
+			playlists = append(playlists, p.toPlaylist())
+		}
+	}
+	return playlists, nil
+}
+
+// getSubsonicPlaylist fetches a playlist with its entries (getPlaylist)
+func getSubsonicPlaylist(ctx context.Context, client *SubsonicClient, playlistID string) (*subsonicPlaylist, error) {
+	params := url.Values{}
+	params.Set("id", playlistID)
+	result, err := client.call(ctx, "getPlaylist", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Subsonic playlist: %w", err)
+	}
+	if result.Response.Playlist == nil {
+		return nil, fmt.Errorf("Subsonic playlist %s not found", playlistID)
+	}
+	return result.Response.Playlist, nil
+}
+
+// GetSubsonicPlaylist retrieves a single playlist's metadata
+func GetSubsonicPlaylist(ctx context.Context, client *SubsonicClient, playlistID string) (db.Playlist, error) {
+	p, err := getSubsonicPlaylist(ctx, client, playlistID)
+	if err != nil {
+		return db.Playlist{}, err
+	}
+	return p.toPlaylist(), nil
+}
+
+// GetSubsonicPlaylistTracks retrieves the entries of a playlist
+func GetSubsonicPlaylistTracks(ctx context.Context, client *SubsonicClient, playlistID string) ([]db.Track, error) {
+	p, err := getSubsonicPlaylist(ctx, client, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tracks []db.Track
+	for _, e := range p.Entry {
+		var isrc string
+		if len(e.ISRC) > 0 {
+			isrc = e.ISRC[0]
+		}
+		tracks = append(tracks, db.Track{
+			ID:            uuid.New(),
+			Title:         e.Title,
+			Artist:        e.Artist,
+			Album:         e.Album,
+			SubsonicID:    e.ID,
+			ISRC:          isrc,
+			MusicBrainzID: e.MusicBrainzID,
+			DurationMs:    e.Duration * 1000,
+			CreatedAt:     time.Now(),
+		})
+	}
+	return tracks, nil
+}
+
+// SearchSubsonicTrack finds a song in the library (search3). It returns "" when no result
+// has the same title and artist.
+func SearchSubsonicTrack(ctx context.Context, client *SubsonicClient, title, artist string) (string, error) {
+	params := url.Values{}
+	params.Set("query", fmt.Sprintf("%s %s", title, artist))
+	params.Set("songCount", "5")
+	params.Set("artistCount", "0")
+	params.Set("albumCount", "0")
+	result, err := client.call(ctx, "search3", params)
+	if err != nil {
+		return "", fmt.Errorf("failed to search Subsonic track: %w", err)
+	}
+	if result.Response.SearchResult3 == nil || len(result.Response.SearchResult3.Song) == 0 {
+		// Some servers match poorly on combined terms; retry with the title only
+		params.Set("query", title)
+		result, err = client.call(ctx, "search3", params)
+		if err != nil {
+			return "", fmt.Errorf("failed to search Subsonic track: %w", err)
+		}
+		if result.Response.SearchResult3 == nil || len(result.Response.SearchResult3.Song) == 0 {
+			return "", nil
+		}
+	}
+
+	// Only accept a song matching title and artist; a loose hit would add the wrong song
+	for _, s := range result.Response.SearchResult3.Song {
+		if strings.EqualFold(s.Title, title) && artist != "" && s.Artist != "" &&
+			strings.Contains(strings.ToLower(artist), strings.ToLower(s.Artist)) {
+			return s.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// CreateSubsonicPlaylist creates a playlist (createPlaylist)
+func CreateSubsonicPlaylist(ctx context.Context, client *SubsonicClient, title string) (string, error) {
+	params := url.Values{}
+	params.Set("name", title)
+	result, err := client.call(ctx, "createPlaylist", params)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Subsonic playlist: %w", err)
+	}
+	if result.Response.Playlist != nil {
+		return result.Response.Playlist.ID, nil
+	}
+
+	// Servers implementing API < 1.14 return an empty body; find the playlist by name
+	playlists, err := GetSubsonicPlaylists(ctx, client)
+	if err != nil {
+		return "", err
+	}
+	for i := len(playlists) - 1; i >= 0; i-- {
+		if playlists[i].Title == title {
+			return playlists[i].SourceID, nil
+		}
+	}
+	return "", fmt.Errorf("created Subsonic playlist %q but could not find its ID", title)
+}
+
+// AddSubsonicPlaylistTracks appends songs to a playlist (updatePlaylist)
+func AddSubsonicPlaylistTracks(ctx context.Context, client *SubsonicClient, playlistID string, songIDs ...string) error {
+	params := url.Values{}
+	params.Set("playlistId", playlistID)
+	for _, id := range songIDs {
+		params.Add("songIdToAdd", id)
+	}
+	if _, err := client.call(ctx, "updatePlaylist", params); err != nil {
+		return fmt.Errorf("failed to add tracks to Subsonic playlist: %w", err)
+	}
+	return nil
+}
+
+// ImportToSubsonic creates a Subsonic playlist and adds tracks
+func ImportToSubsonic(ctx context.Context, client *SubsonicClient, playlist db.Playlist, tracks []db.Track) (string, error) {
+	return syncToSubsonic(ctx, client, playlist, tracks, uuid.Nil)
+}
+
+// syncToSubsonic adds tracks to the job's Subsonic playlist, creating it on the first run.
+// Only songs already in the user's library can be added, so unmatched tracks are skipped.
+func syncToSubsonic(ctx context.Context, client *SubsonicClient, playlist db.Playlist, tracks []db.Track, jobID uuid.UUID) (string, error) {
+	ssPlaylistID := loadSyncDestination(jobID, "subsonic")
+	if ssPlaylistID == "" {
+		var err error
+		ssPlaylistID, err = CreateSubsonicPlaylist(ctx, client, playlist.Title)
+		if err != nil {
+			return "", err
+		}
+		SaveSyncDestination(jobID, "subsonic", ssPlaylistID)
+	}
+
+	checkpoints, err := loadSyncCheckpoints(jobID, "subsonic")
+	if err != nil {
+		return ssPlaylistID, err
+	}
+
+	successCount := 0
+	for _, track := range tracks {
+		if checkpoints.isDone(track.ID) {
+			continue
+		}
+		songID := track.SubsonicID
+		if songID == "" {
+			songID, err = SearchSubsonicTrack(ctx, client, track.Title, track.Artist)
+			if err != nil {
+				log.Printf("   ⚠️ Subsonic search failed for %s - %s: %v", track.Title, track.Artist, err)
+				checkpoints.record(track.ID, "failed", "")
+				continue
+			}
+			if songID == "" {
+				log.Printf("   ⚠️ Not in Subsonic library: %s - %s", track.Title, track.Artist)
+				checkpoints.record(track.ID, "not_found", "")
+				continue
+			}
+		}
+		if err := AddSubsonicPlaylistTracks(ctx, client, ssPlaylistID, songID); err != nil {
+			log.Printf("   ❌ Failed to add track to Subsonic: %s: %v", track.Title, err)
+			checkpoints.record(track.ID, "failed", songID)
+			continue
+		}
+		checkpoints.record(track.ID, "added", songID)
+		successCount++
+	}
+	log.Printf("🏁 Subsonic Sync Finished. Added %d/%d songs.", successCount, len(tracks))
+	return ssPlaylistID, nil
+}
+
+// ImportSubsonicPlaylist imports a single Subsonic playlist to DB
+func ImportSubsonicPlaylist(ctx context.Context, client *SubsonicClient, user db.User, sourceID string) error {
+	playlist, err := GetSubsonicPlaylist(ctx, client, sourceID)
+	if err != nil {
+		return err
+	}
+	playlist.OwnerID = user.ID
+
+	var existing db.Playlist
+	if err := db.DB.Where("owner_id = ? AND source_id = ? AND platform = 'subsonic'", user.ID, sourceID).First(&existing).Error; err == nil {
+		playlist = existing
+	} else {
+		if err := db.DB.Create(&playlist).Error; err != nil {
+			return fmt.Errorf("failed to create playlist in DB: %w", err)
+		}
+	}
+
+	tracks, err := GetSubsonicPlaylistTracks(ctx, client, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch tracks: %w", err)
+	}
+
+	for _, t := range tracks {
+		t.PlaylistID = playlist.ID
+		db.DB.Create(&t)
+	}
+	RecordImportVersion(playlist.ID, user.ID)
+	return nil
+}
+
+// ImportAllSubsonicPlaylists imports all playlists and tracks for a user
+func ImportAllSubsonicPlaylists(ctx context.Context, user db.User) error {
+	client, err := GetSubsonicClient(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	playlists, err := GetSubsonicPlaylists(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range playlists {
+		p.OwnerID = user.ID
+		var existing db.Playlist
+		if err := db.DB.Where("owner_id = ? AND source_id = ? AND platform = 'subsonic'", user.ID, p.SourceID).First(&existing).Error; err == nil {
+			continue
+		}
+
+		if err := db.DB.Create(&p).Error; err != nil {
+			log.Printf("Failed to create playlist %s: %v", p.Title, err)
+			continue
+		}
+
+		tracks, err := GetSubsonicPlaylistTracks(ctx, client, p.SourceID)
+		if err != nil {
+			log.Printf("Failed to get tracks for %s: %v", p.Title, err)
+			continue
+		}
+
+		for _, t := range tracks {
+			t.PlaylistID = p.ID
+			db.DB.Create(&t)
+		}
+		RecordImportVersion(p.ID, user.ID)
+	}
+	return nil
+}
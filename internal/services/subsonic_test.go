@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const fakeSubsonicPassword = "sesame"
+
+// fakeSubsonic serves a minimal Subsonic REST API for user "alice". Every request must carry
+// token auth (t = md5(password + s)) and f=json, as a real server would check.
+func fakeSubsonic(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var added []string
+
+	write := func(w http.ResponseWriter, body map[string]interface{}) {
+		if _, ok := body["status"]; !ok {
+			body["status"] = "ok"
+		}
+		body["version"] = "1.16.1"
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"subsonic-response": body})
+	}
+	fail := func(w http.ResponseWriter, code int, message string) {
+		write(w, map[string]interface{}{
+			"status": "failed",
+			"error":  map[string]interface{}{"code": code, "message": message},
+		})
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		sum := md5.Sum([]byte(fakeSubsonicPassword + q.Get("s")))
+		if q.Get("u") != "alice" || q.Get("s") == "" || q.Get("t") != hex.EncodeToString(sum[:]) {
+			fail(w, 40, "Wrong username or password")
+			return
+		}
+		if q.Get("f") != "json" || q.Get("v") == "" || q.Get("c") == "" {
+			t.Errorf("missing f/v/c parameters: %s", r.URL.RawQuery)
+		}
+
+		switch strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/rest/"), ".view") {
+		case "ping":
+			write(w, map[string]interface{}{})
+		case "getPlaylists":
+			write(w, map[string]interface{}{"playlists": map[string]interface{}{"playlist": []map[string]interface{}{
+				{"id": "1", "name": "Road trip", "comment": "summer", "public": true},
+				{"id": "2", "name": "Focus"},
+			}}})
+		case "getPlaylist":
+			if q.Get("id") != "1" {
+				fail(w, 70, "Playlist not found")
+				return
+			}
+			write(w, map[string]interface{}{"playlist": map[string]interface{}{
+				"id": "1", "name": "Road trip", "comment": "summer",
+				"entry": []map[string]interface{}{
+					{"id": "s1", "title": "Song A", "artist": "Artist A", "album": "Album A", "duration": 180,
+						"musicBrainzId": "mbid-a", "isrc": []string{"USAAA0000001"}},
+					{"id": "s2", "title": "Song B", "artist": "Artist B", "duration": 200},
+				},
+			}})
+		case "search3":
+			var songs []map[string]interface{}
+			switch q.Get("query") {
+			case "Song A Artist A":
+				songs = []map[string]interface{}{
+					{"id": "cover", "title": "Song A (Cover)", "artist": "Someone"},
+					{"id": "s1", "title": "Song A", "artist": "Artist A"},
+				}
+			case "Song B Artist B":
+				// No combined match; the client retries with the title only
+			case "Song B":
+				songs = []map[string]interface{}{{"id": "s2", "title": "song b", "artist": "Artist B"}}
+			case "Song C Artist C", "Song C":
+				songs = []map[string]interface{}{{"id": "s3", "title": "Song C", "artist": ""}}
+			case "Unknown Nobody", "Unknown":
+				songs = []map[string]interface{}{{"id": "s9", "title": "Something else", "artist": "Other"}}
+			}
+			write(w, map[string]interface{}{"searchResult3": map[string]interface{}{"song": songs}})
+		case "createPlaylist":
+			if q.Get("name") == "" {
+				fail(w, 10, "Required parameter is missing")
+				return
+			}
+			write(w, map[string]interface{}{"playlist": map[string]interface{}{"id": "99", "name": q.Get("name")}})
+		case "updatePlaylist":
+			if q.Get("playlistId") != "99" {
+				fail(w, 70, "Playlist not found")
+				return
+			}
+			added = append(added, q["songIdToAdd"]...)
+			write(w, map[string]interface{}{})
+		default:
+			fail(w, 0, "Unknown method")
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &added
+}
+
+func newFakeSubsonicClient(t *testing.T, serverURL, password string) *SubsonicClient {
+	t.Helper()
+	token, salt, err := NewSubsonicCredentials(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewSubsonicClient(serverURL, "alice", token, salt)
+}
+
+func TestSubsonicTokenAuth(t *testing.T) {
+	srv, _ := fakeSubsonic(t)
+	ctx := context.Background()
+
+	if err := PingSubsonic(ctx, newFakeSubsonicClient(t, srv.URL, fakeSubsonicPassword)); err != nil {
+		t.Fatalf("ping with valid credentials: %v", err)
+	}
+	err := PingSubsonic(ctx, newFakeSubsonicClient(t, srv.URL, "wrong"))
+	if err == nil || !strings.Contains(err.Error(), "Subsonic error 40") {
+		t.Fatalf("expected the failed envelope to surface as error 40, got %v", err)
+	}
+}
+
+func TestGetSubsonicPlaylists(t *testing.T) {
+	srv, _ := fakeSubsonic(t)
+	playlists, err := GetSubsonicPlaylists(context.Background(), newFakeSubsonicClient(t, srv.URL, fakeSubsonicPassword))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(playlists) != 2 {
+		t.Fatalf("got %d playlists, want 2", len(playlists))
+	}
+	p := playlists[0]
+	if p.SourceID != "1" || p.Title != "Road trip" || p.Description != "summer" || p.Platform != "subsonic" {
+		t.Errorf("unexpected playlist: %+v", p)
+	}
+	if p.IsPublic {
+		t.Error("imported playlists must be private even when public on the server")
+	}
+}
+
+func TestGetSubsonicPlaylistTracks(t *testing.T) {
+	srv, _ := fakeSubsonic(t)
+	client := newFakeSubsonicClient(t, srv.URL, fakeSubsonicPassword)
+	ctx := context.Background()
+
+	tracks, err := GetSubsonicPlaylistTracks(ctx, client, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 2 {
+		t.Fatalf("got %d tracks, want 2", len(tracks))
+	}
+	a := tracks[0]
+	if a.SubsonicID != "s1" || a.Title != "Song A" || a.Artist != "Artist A" || a.Album != "Album A" || a.DurationMs != 180000 {
+		t.Errorf("unexpected track: %+v", a)
+	}
+	if a.ISRC != "USAAA0000001" || a.MusicBrainzID != "mbid-a" {
+		t.Errorf("ISRC/MBID = %q/%q", a.ISRC, a.MusicBrainzID)
+	}
+	if tracks[1].ISRC != "" {
+		t.Errorf("track without ISRC got %q", tracks[1].ISRC)
+	}
+
+	_, err = GetSubsonicPlaylistTracks(ctx, client, "404")
+	if err == nil || !strings.Contains(err.Error(), "Playlist not found") {
+		t.Fatalf("expected the server's error message, got %v", err)
+	}
+}
+
+func TestSearchSubsonicTrack(t *testing.T) {
+	srv, _ := fakeSubsonic(t)
+	client := newFakeSubsonicClient(t, srv.URL, fakeSubsonicPassword)
+
+	tests := []struct {
+		name, title, artist, want string
+	}{
+		{"exact match among results", "Song A", "Artist A", "s1"},
+		{"title-only retry, case-insensitive", "Song B", "Artist B", "s2"},
+		{"no matching result", "Unknown", "Nobody", ""},
+		{"result without an artist", "Song C", "Artist C", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SearchSubsonicTrack(context.Background(), client, tt.title, tt.artist)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateSubsonicPlaylistAndAddTracks(t *testing.T) {
+	srv, added := fakeSubsonic(t)
+	client := newFakeSubsonicClient(t, srv.URL, fakeSubsonicPassword)
+	ctx := context.Background()
+
+	id, err := CreateSubsonicPlaylist(ctx, client, "New list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "99" {
+		t.Fatalf("playlist ID = %q, want 99", id)
+	}
+	if err := AddSubsonicPlaylistTracks(ctx, client, id, "s1", "s2"); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(*added, []string{"s1", "s2"}) {
+		t.Errorf("songIdToAdd = %v, want repeated s1, s2", *added)
+	}
+
+	if err := AddSubsonicPlaylistTracks(ctx, client, "404", "s1"); err == nil {
+		t.Error("expected an error for an unknown playlist")
+	}
+}
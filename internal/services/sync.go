@@ -1,4 +1,138 @@
 package services
 
-// This file is intentionally left empty.
-// Logic has been moved to spotify.go, youtube.go, and playlist.go
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// --- SYNC CHECKPOINTS ---
+
+// syncCheckpoints tracks per-track progress of a sync job on one platform.
+// A nil *syncCheckpoints (no job ID) records nothing and treats every track as pending.
+type syncCheckpoints struct {
+	jobID    uuid.UUID
+	platform string
+	done     map[uuid.UUID]bool
+}
+
+// loadSyncCheckpoints loads the tracks already handled by a previous run of the job
+func loadSyncCheckpoints(jobID uuid.UUID, platform string) (*syncCheckpoints, error) {
+	if jobID == uuid.Nil {
+		return nil, nil
+	}
+
+	var rows []db.SyncCheckpoint
+	if err := db.DB.Where("job_id = ? AND platform = ? AND status IN ?", jobID, platform, []string{"added", "not_found"}).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load sync checkpoints: %w", err)
+	}
+
+	cp := &syncCheckpoints{jobID: jobID, platform: platform, done: make(map[uuid.UUID]bool)}
+	for _, r := range rows {
+		cp.done[r.TrackID] = true
+	}
+	if len(rows) > 0 {
+		log.Printf("⏩ Resuming %s sync for job %s: %d tracks already handled", platform, jobID, len(rows))
+	}
+	return cp, nil
+}
+
+// isDone reports whether the track was added or definitively not found in an earlier run
+func (cp *syncCheckpoints) isDone(trackID uuid.UUID) bool {
+	if cp == nil {
+		return false
+	}
+	return cp.done[trackID]
+}
+
+// record stores the outcome for a track. "failed" tracks are retried on resume.
+func (cp *syncCheckpoints) record(trackID uuid.UUID, status, externalID string) {
+	if cp == nil {
+		return
+	}
+
+	checkpoint := db.SyncCheckpoint{
+		ID:         uuid.New(),
+		JobID:      cp.jobID,
+		Platform:   cp.platform,
+		TrackID:    trackID,
+		Status:     status,
+		ExternalID: externalID,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	err := db.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "job_id"}, {Name: "platform"}, {Name: "track_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "external_id", "updated_at"}),
+	}).Create(&checkpoint).Error
+	if err != nil {
+		log.Printf("Failed to save sync checkpoint for track %s: %v", trackID, err)
+		return
+	}
+	if status == "added" || status == "not_found" {
+		cp.done[trackID] = true
+	}
+}
+
+// loadSyncDestination returns the destination playlist a previous run of the job created, if any
+func loadSyncDestination(jobID uuid.UUID, platform string) string {
+	if jobID == uuid.Nil {
+		return ""
+	}
+	var job db.SyncJob
+	if err := db.DB.Select("result").Where("id = ?", jobID).First(&job).Error; err != nil || job.Result == "" {
+		return ""
+	}
+	var result map[string]string
+	if err := json.Unmarshal([]byte(job.Result), &result); err != nil {
+		return ""
+	}
+	return result[platform]
+}
+
+// SaveSyncDestination stores the destination playlist on the job as soon as it exists,
+// so a resumed job writes into the same playlist instead of creating a new one, and
+// restores can later be pushed to it
+func SaveSyncDestination(jobID uuid.UUID, platform, playlistID string) {
+	if jobID == uuid.Nil {
+		return
+	}
+	var job db.SyncJob
+	if err := db.DB.Select("result").Where("id = ?", jobID).First(&job).Error; err != nil {
+		log.Printf("Failed to load sync job %s: %v", jobID, err)
+		return
+	}
+	result := make(map[string]string)
+	if job.Result != "" {
+		json.Unmarshal([]byte(job.Result), &result)
+	}
+	result[platform] = playlistID
+	resultJSON, _ := json.Marshal(result)
+	if err := db.DB.Model(&db.SyncJob{}).Where("id = ?", jobID).Update("result", string(resultJSON)).Error; err != nil {
+		log.Printf("Failed to save sync destination for job %s: %v", jobID, err)
+	}
+}
+
+// MarkInterruptedSyncJobs flags worker pool jobs that were queued or running when the
+// process stopped. The in-memory queue is gone, so they can only be resumed explicitly.
+func MarkInterruptedSyncJobs() error {
+	result := db.DB.Model(&db.SyncJob{}).
+		Where("runner = ? AND status IN ?", "worker", []string{"pending", "processing"}).
+		Updates(map[string]interface{}{
+			"status":    "interrupted",
+			"error_msg": "server stopped before the sync finished",
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark interrupted sync jobs: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Marked %d unfinished sync jobs as interrupted", result.RowsAffected)
+	}
+	return nil
+}
@@ -0,0 +1,518 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+)
+
+// --- CONFIGURATION ---
+
+var tidalAPIURL = "https://openapi.tidal.com/v2"
+
+// GetTidalOAuthConfig returns the TIDAL OAuth config. TIDAL requires PKCE for the
+// authorization code flow, see auth.TidalLink.
+func GetTidalOAuthConfig() *oauth2.Config {
+	redirectURL := os.Getenv("TIDAL_REDIRECT_URL")
+	if redirectURL == "" {
+		redirectURL = "http://127.0.0.1:8000/callback/tidal"
+	}
+	return &oauth2.Config{
+		ClientID:     os.Getenv("TIDAL_CLIENT_ID"),
+		ClientSecret: os.Getenv("TIDAL_CLIENT_SECRET"),
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"user.read", "collection.read", "playlists.read", "playlists.write", "search.read"},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  "https://login.tidal.com/authorize",
+			TokenURL: "https://auth.tidal.com/v1/oauth2/token",
+		},
+	}
+}
+
+func getTidalCountryCode() string {
+	if cc := os.Getenv("TIDAL_COUNTRY_CODE"); cc != "" {
+		return cc
+	}
+	return "US"
+}
+
+// TidalClient calls the TIDAL v2 (JSON:API) endpoints
+type TidalClient struct {
+	httpClient  *http.Client
+	CountryCode string
+}
+
+// tidalResource is a JSON:API resource object
+type tidalResource struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	Attributes    json.RawMessage `json:"attributes"`
+	Relationships map[string]struct {
+		Data []struct {
+			ID   string `json:"id"`
+			Type string `json:"type"`
+		} `json:"data"`
+	} `json:"relationships"`
+}
+
+type tidalDocument struct {
+	Data     json.RawMessage `json:"data"`
+	Included []tidalResource `json:"included"`
+	Links    struct {
+		Next string `json:"next"`
+	} `json:"links"`
+}
+
+type tidalTrackAttributes struct {
+	Title    string `json:"title"`
+	ISRC     string `json:"isrc"`
+	Duration string `json:"duration"` // ISO 8601, e.g. "PT3M25S"
+}
+
+// --- TIDAL FUNCTIONS ---
+
+// GetTidalClient returns a TIDAL client for a user
+func GetTidalClient(ctx context.Context, user db.User) (*TidalClient, error) {
+	if user.TidalToken == "" {
+		return nil, fmt.Errorf("no TIDAL token available")
+	}
+	var token oauth2.Token
+	if err := json.Unmarshal([]byte(user.TidalToken), &token); err != nil {
+		return nil, fmt.Errorf("invalid TIDAL token: %w", err)
+	}
+	return NewTidalClient(GetTidalOAuthConfig().Client(ctx, &token)), nil
+}
+
+// NewTidalClient wraps an authenticated HTTP client
+func NewTidalClient(httpClient *http.Client) *TidalClient {
+	return &TidalClient{httpClient: httpClient, CountryCode: getTidalCountryCode()}
+}
+
+func (c *TidalClient) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode TIDAL request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	endpoint := path
+	if !strings.HasPrefix(endpoint, "http") {
+		endpoint = tidalAPIURL + path
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create TIDAL request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.api+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/vnd.api+json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("TIDAL request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("TIDAL API returned %d: %s", resp.StatusCode, string(respBody))
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode TIDAL response: %w", err)
+	}
+	return nil
+}
+
+// GetTidalUser returns the TIDAL user ID of the token's owner
+func GetTidalUser(ctx context.Context, client *TidalClient) (string, error) {
+	var doc struct {
+		Data tidalResource `json:"data"`
+	}
+	if err := client.do(ctx, "GET", "/users/me", nil, &doc); err != nil {
+		return "", fmt.Errorf("failed to get TIDAL user: %w", err)
+	}
+	return doc.Data.ID, nil
+}
+
+func tidalPlaylistFromResource(r tidalResource) db.Playlist {
+	var attrs struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	json.Unmarshal(r.Attributes, &attrs)
+	return db.Playlist{
+		ID:          uuid.New(),
+		Title:       attrs.Name,
+		Description: attrs.Description,
+		Platform:    "tidal",
+		SourceID:    r.ID,
+		IsPublic:    false,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// GetTidalPlaylists retrieves the playlists owned by the user
+func GetTidalPlaylists(ctx context.Context, client *TidalClient, tidalUserID string) ([]db.Playlist, error) {
+	params := url.Values{}
+	params.Set("countryCode", client.CountryCode)
+	params.Set("filter[r.owners.id]", tidalUserID)
+	next := "/playlists?" + params.Encode()
+
+	var playlists []db.Playlist
+	for next != "" {
+		var doc tidalDocument
+		if err := client.do(ctx, "GET", next, nil, &doc); err != nil {
+			return nil, fmt.Errorf("failed to get TIDAL playlists: %w", err)
+		}
+		var data []tidalResource
+		if err := json.Unmarshal(doc.Data, &data); err != nil {
+			return nil, fmt.Errorf("failed to decode TIDAL playlists: %w", err)
+		}
+		for _, r := range data {
+			playlists = append(playlists, tidalPlaylistFromResource(r))
+		}
+		next = doc.Links.Next
+	}
+	return playlists, nil
+}
+
+// GetTidalPlaylist retrieves a single playlist's metadata
+func GetTidalPlaylist(ctx context.Context, client *TidalClient, playlistID string) (db.Playlist, error) {
+	var doc struct {
+		Data tidalResource `json:"data"`
+	}
+	path := fmt.Sprintf("/playlists/%s?countryCode=%s", url.PathEscape(playlistID), client.CountryCode)
+	if err := client.do(ctx, "GET", path, nil, &doc); err != nil {
+		return db.Playlist{}, fmt.Errorf("failed to fetch TIDAL playlist: %w", err)
+	}
+	return tidalPlaylistFromResource(doc.Data), nil
+}
+
+// GetTidalPlaylistTracks retrieves tracks (with ISRC and duration) from a TIDAL playlist
+func GetTidalPlaylistTracks(ctx context.Context, client *TidalClient, playlistID string) ([]db.Track, error) {
+	params := url.Values{}
+	params.Set("countryCode", client.CountryCode)
+	params.Set("include", "items,items.artists,items.albums")
+	next := fmt.Sprintf("/playlists/%s/relationships/items?%s", url.PathEscape(playlistID), params.Encode())
+
+	var tracks []db.Track
+	for next != "" {
+		var doc tidalDocument
+		if err := client.do(ctx, "GET", next, nil, &doc); err != nil {
+			return nil, fmt.Errorf("failed to retrieve TIDAL playlist tracks: %w", err)
+		}
+		var items []tidalResource
+		if err := json.Unmarshal(doc.Data, &items); err != nil {
+			return nil, fmt.Errorf("failed to decode TIDAL playlist items: %w", err)
+		}
+
+		included := make(map[string]tidalResource)
+		for _, r := range doc.Included {
+			included[r.Type+"/"+r.ID] = r
+		}
+
+		for _, item := range items {
+			if item.Type != "tracks" {
+				continue
+			}
+			resource, ok := included["tracks/"+item.ID]
+			if !ok {
+				continue
+			}
+			tracks = append(tracks, tidalTrackFromResource(resource, included))
+		}
+		next = doc.Links.Next
+	}
+	return tracks, nil
+}
+
+func tidalTrackFromResource(r tidalResource, included map[string]tidalResource) db.Track {
+	var attrs tidalTrackAttributes
+	json.Unmarshal(r.Attributes, &attrs)
+
+	var artists []string
+	for _, ref := range r.Relationships["artists"].Data {
+		var a struct {
+			Name string `json:"name"`
+		}
+		json.Unmarshal(included["artists/"+ref.ID].Attributes, &a)
+		if a.Name != "" {
+			artists = append(artists, a.Name)
+		}
+	}
+	var album string
+	if refs := r.Relationships["albums"].Data; len(refs) > 0 {
+		var a struct {
+			Title string `json:"title"`
+		}
+		json.Unmarshal(included["albums/"+refs[0].ID].Attributes, &a)
+		album = a.Title
+	}
+
+	return db.Track{
+		ID:         uuid.New(),
+		Title:      attrs.Title,
+		Artist:     strings.Join(artists, ", "),
+		Album:      album,
+		TidalID:    r.ID,
+		ISRC:       attrs.ISRC,
+		DurationMs: parseISODurationMs(attrs.Duration),
+		CreatedAt:  time.Now(),
+	}
+}
+
+var isoDurationPattern = regexp.MustCompile(`^PT(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?$`)
+
+// parseISODurationMs converts an ISO 8601 duration like "PT3M25S" to milliseconds
+func parseISODurationMs(d string) int {
+	m := isoDurationPattern.FindStringSubmatch(d)
+	if m == nil {
+		return 0
+	}
+	hours, _ := strconv.Atoi(m[1])
+	minutes, _ := strconv.Atoi(m[2])
+	seconds, _ := strconv.ParseFloat(m[3], 64)
+	return (hours*3600+minutes*60)*1000 + int(seconds*1000)
+}
+
+// SearchTidalTrack finds a TIDAL track ID, preferring an exact ISRC lookup. Search results
+// must match title and artist; it returns "" when none does, rather than guessing.
+func SearchTidalTrack(ctx context.Context, client *TidalClient, title, artist, isrc string) (string, error) {
+	if isrc != "" {
+		params := url.Values{}
+		params.Set("countryCode", client.CountryCode)
+		params.Set("filter[isrc]", isrc)
+		var doc tidalDocument
+		if err := client.do(ctx, "GET", "/tracks?"+params.Encode(), nil, &doc); err == nil {
+			var data []tidalResource
+			if json.Unmarshal(doc.Data, &data) == nil && len(data) > 0 {
+				return data[0].ID, nil
+			}
+		}
+	}
+
+	params := url.Values{}
+	params.Set("countryCode", client.CountryCode)
+	params.Set("include", "tracks,tracks.artists")
+	query := url.PathEscape(fmt.Sprintf("%s %s", title, artist))
+	var doc tidalDocument
+	if err := client.do(ctx, "GET", fmt.Sprintf("/searchResults/%s/relationships/tracks?%s", query, params.Encode()), nil, &doc); err != nil {
+		return "", fmt.Errorf("failed to search TIDAL track: %w", err)
+	}
+	var data []tidalResource
+	if err := json.Unmarshal(doc.Data, &data); err != nil {
+		return "", fmt.Errorf("failed to decode TIDAL search response: %w", err)
+	}
+
+	included := make(map[string]tidalResource)
+	for _, r := range doc.Included {
+		included[r.Type+"/"+r.ID] = r
+	}
+	// Only accept the same title by the same artist; a loose hit would add the wrong song
+	for _, item := range data {
+		resource, ok := included["tracks/"+item.ID]
+		if !ok {
+			continue
+		}
+		var attrs tidalTrackAttributes
+		json.Unmarshal(resource.Attributes, &attrs)
+		if !strings.EqualFold(attrs.Title, title) {
+			continue
+		}
+		for _, ref := range resource.Relationships["artists"].Data {
+			var a struct {
+				Name string `json:"name"`
+			}
+			json.Unmarshal(included["artists/"+ref.ID].Attributes, &a)
+			if a.Name != "" && artist != "" && strings.Contains(strings.ToLower(artist), strings.ToLower(a.Name)) {
+				return item.ID, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// CreateTidalPlaylist creates a new unlisted playlist
+func CreateTidalPlaylist(ctx context.Context, client *TidalClient, title, description string) (string, error) {
+	body := map[string]interface{}{
+		"data": map[string]interface{}{
+			"type": "playlists",
+			"attributes": map[string]string{
+				"name":        title,
+				"description": description,
+				"accessType":  "UNLISTED",
+			},
+		},
+	}
+	var doc struct {
+		Data tidalResource `json:"data"`
+	}
+	if err := client.do(ctx, "POST", "/playlists?countryCode="+client.CountryCode, body, &doc); err != nil {
+		return "", fmt.Errorf("failed to create TIDAL playlist: %w", err)
+	}
+	return doc.Data.ID, nil
+}
+
+// AddTidalPlaylistTracks appends tracks to a playlist
+func AddTidalPlaylistTracks(ctx context.Context, client *TidalClient, playlistID string, trackIDs ...string) error {
+	var data []map[string]string
+	for _, id := range trackIDs {
+		data = append(data, map[string]string{"id": id, "type": "tracks"})
+	}
+	path := fmt.Sprintf("/playlists/%s/relationships/items", url.PathEscape(playlistID))
+	if err := client.do(ctx, "POST", path, map[string]interface{}{"data": data}, nil); err != nil {
+		return fmt.Errorf("failed to add tracks to TIDAL playlist: %w", err)
+	}
+	return nil
+}
+
+// ImportToTidal creates a TIDAL playlist and adds tracks
+func ImportToTidal(ctx context.Context, client *TidalClient, playlist db.Playlist, tracks []db.Track) (string, error) {
+	return syncToTidal(ctx, client, playlist, tracks, uuid.Nil)
+}
+
+// syncToTidal adds tracks to the job's TIDAL playlist, creating it on the first run.
+// Tracks without a TidalID are matched by ISRC, then by title and artist.
+func syncToTidal(ctx context.Context, client *TidalClient, playlist db.Playlist, tracks []db.Track, jobID uuid.UUID) (string, error) {
+	tdPlaylistID := loadSyncDestination(jobID, "tidal")
+	if tdPlaylistID == "" {
+		var err error
+		tdPlaylistID, err = CreateTidalPlaylist(ctx, client, playlist.Title, playlist.Description)
+		if err != nil {
+			return "", err
+		}
+		SaveSyncDestination(jobID, "tidal", tdPlaylistID)
+	}
+
+	checkpoints, err := loadSyncCheckpoints(jobID, "tidal")
+	if err != nil {
+		return tdPlaylistID, err
+	}
+
+	successCount := 0
+	for _, track := range tracks {
+		if checkpoints.isDone(track.ID) {
+			continue
+		}
+		trackID := track.TidalID
+		if trackID == "" {
+			trackID, err = SearchTidalTrack(ctx, client, track.Title, track.Artist, track.ISRC)
+			if err != nil {
+				if strings.Contains(err.Error(), "429") {
+					return tdPlaylistID, fmt.Errorf("rate limited during search: %w", err)
+				}
+				log.Printf("   ⚠️ TIDAL search failed for %s - %s: %v", track.Title, track.Artist, err)
+				checkpoints.record(track.ID, "failed", "")
+				continue
+			}
+			if trackID == "" {
+				log.Printf("   ⚠️ Not found on TIDAL: %s - %s", track.Title, track.Artist)
+				checkpoints.record(track.ID, "not_found", "")
+				continue
+			}
+		}
+		if err := AddTidalPlaylistTracks(ctx, client, tdPlaylistID, trackID); err != nil {
+			if strings.Contains(err.Error(), "429") {
+				return tdPlaylistID, fmt.Errorf("rate limited during add: %w", err)
+			}
+			log.Printf("   ❌ Failed to add track to TIDAL: %s: %v", track.Title, err)
+			checkpoints.record(track.ID, "failed", trackID)
+			continue
+		}
+		checkpoints.record(track.ID, "added", trackID)
+		successCount++
+	}
+	log.Printf("🏁 TIDAL Sync Finished. Added %d/%d songs.", successCount, len(tracks))
+	return tdPlaylistID, nil
+}
+
+// ImportTidalPlaylist imports a single TIDAL playlist to DB
+func ImportTidalPlaylist(ctx context.Context, client *TidalClient, user db.User, sourceID string) error {
+	playlist, err := GetTidalPlaylist(ctx, client, sourceID)
+	if err != nil {
+		return err
+	}
+	playlist.OwnerID = user.ID
+
+	var existing db.Playlist
+	if err := db.DB.Where("owner_id = ? AND source_id = ? AND platform = 'tidal'", user.ID, sourceID).First(&existing).Error; err == nil {
+		playlist = existing
+	} else {
+		if err := db.DB.Create(&playlist).Error; err != nil {
+			return fmt.Errorf("failed to create playlist in DB: %w", err)
+		}
+	}
+
+	tracks, err := GetTidalPlaylistTracks(ctx, client, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch tracks: %w", err)
+	}
+
+	for _, t := range tracks {
+		t.PlaylistID = playlist.ID
+		db.DB.Create(&t)
+	}
+	RecordImportVersion(playlist.ID, user.ID)
+	return nil
+}
+
+// ImportAllTidalPlaylists imports all playlists and tracks for a user
+func ImportAllTidalPlaylists(ctx context.Context, user db.User) error {
+	client, err := GetTidalClient(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	playlists, err := GetTidalPlaylists(ctx, client, user.TidalID)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range playlists {
+		p.OwnerID = user.ID
+		var existing db.Playlist
+		if err := db.DB.Where("owner_id = ? AND source_id = ? AND platform = 'tidal'", user.ID, p.SourceID).First(&existing).Error; err == nil {
+			continue
+		}
+
+		if err := db.DB.Create(&p).Error; err != nil {
+			log.Printf("Failed to create playlist %s: %v", p.Title, err)
+			continue
+		}
+
+		tracks, err := GetTidalPlaylistTracks(ctx, client, p.SourceID)
+		if err != nil {
+			log.Printf("Failed to get tracks for %s: %v", p.Title, err)
+			continue
+		}
+
+		for _, t := range tracks {
+			t.PlaylistID = p.ID
+			db.DB.Create(&t)
+		}
+		RecordImportVersion(p.ID, user.ID)
+	}
+	return nil
+}
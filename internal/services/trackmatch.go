@@ -0,0 +1,147 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"EchoBridge/db"
+)
+
+// --- TRACK IDENTITY ---
+
+// normalizeSongText lowercases and keeps only letters and digits, so spelling variants in
+// punctuation and spacing compare equal. Apostrophes are dropped rather than split on.
+func normalizeSongText(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		if r == '\'' || r == '’' {
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		} else {
+			space = true
+		}
+	}
+	return b.String()
+}
+
+var (
+	// Bracketed or dashed title suffixes that name a release variant of the same recording
+	titleVariantPattern = regexp.MustCompile(`(?i)\s*(\(|\[|\s-\s)[^)\]]*\b(feat|ft|featuring|remaster|remastered|album version|single version|original mix|radio edit|explicit|clean|mono|stereo)\b[^)\]]*(\)|\]|$)`)
+	// "feat. X" without brackets
+	titleFeaturingPattern = regexp.MustCompile(`(?i)\s+(feat\.?|ft\.|featuring)\s.*$`)
+	// Separators between credited artists; the first artist is the primary one
+	artistSeparatorPattern = regexp.MustCompile(`(?i)\s*(,|;|&|\bfeat\.?|\bft\.|\bfeaturing\b|\bx\b)\s*`)
+)
+
+// canonicalTitle strips featured artists and release variants such as "Remastered 2011",
+// so "Song - 2011 Remaster" and "Song (feat. X)" compare equal to "Song"
+func canonicalTitle(title string) string {
+	title = titleVariantPattern.ReplaceAllString(title, "")
+	title = titleFeaturingPattern.ReplaceAllString(title, "")
+	return normalizeSongText(title)
+}
+
+// primaryArtist returns the first credited artist, normalized
+func primaryArtist(artist string) string {
+	parts := artistSeparatorPattern.Split(strings.TrimSpace(artist), 2)
+	return normalizeSongText(parts[0])
+}
+
+// CanonicalSongKey identifies a song independently of platform spelling: primary artist and
+// canonical title. It is empty for tracks without a title.
+func CanonicalSongKey(t db.Track) string {
+	title := canonicalTitle(t.Title)
+	if title == "" {
+		return ""
+	}
+	return primaryArtist(t.Artist) + "|" + title
+}
+
+// exactTrackKeys returns the platform IDs and ISRC of a track, each identifying one recording
+func exactTrackKeys(t db.Track) []string {
+	var keys []string
+	add := func(prefix, value string) {
+		if value != "" {
+			keys = append(keys, prefix+":"+value)
+		}
+	}
+	add("isrc", strings.ToUpper(t.ISRC))
+	add("musicbrainz", t.MusicBrainzID)
+	add("spotify", t.SpotifyID)
+	add("youtube", t.YouTubeID)
+	add("applemusic", t.AppleMusicID)
+	add("deezer", t.DeezerID)
+	add("tidal", t.TidalID)
+	add("soundcloud", t.SoundCloudURL)
+	return keys
+}
+
+// trackIdentityKeys returns the keys under which two tracks count as the same song:
+// any shared platform ID or ISRC, or the same canonical song
+func trackIdentityKeys(t db.Track) []string {
+	keys := exactTrackKeys(t)
+	if song := CanonicalSongKey(t); song != "" {
+		keys = append(keys, "song:"+song)
+	}
+	return keys
+}
+
+// similarity returns 1 minus the normalized Levenshtein distance of two strings
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(max(len(ra), len(rb)))
+}
+
+// trackSet remembers songs by identity key
+type trackSet map[string]bool
+
+func newTrackSet(tracks []db.Track) trackSet {
+	set := make(trackSet)
+	for _, t := range tracks {
+		set.add(t)
+	}
+	return set
+}
+
+func (s trackSet) add(t db.Track) {
+	for _, key := range trackIdentityKeys(t) {
+		s[key] = true
+	}
+}
+
+// contains reports whether any identity key of the track was seen before
+func (s trackSet) contains(t db.Track) bool {
+	for _, key := range trackIdentityKeys(t) {
+		if s[key] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,403 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"EchoBridge/db"
+
+	"github.com/google/uuid"
+	"github.com/zmb3/spotify/v2"
+	"gorm.io/gorm"
+)
+
+// --- TRACK LINK RESOLVER ---
+
+// TrackURLNotTrack is returned (as a PlaylistURLError code) for links that are not a single track
+const TrackURLNotTrack = "not_a_track"
+
+// ResolvablePlatforms are the platforms a resolved track gets links for. Each is searched with
+// server credentials, so no user account is involved.
+var ResolvablePlatforms = []string{"spotify", "youtube", "applemusic", "deezer"}
+
+const (
+	// resolvedTrackTTL is how long a cached resolution is served before it is refreshed
+	resolvedTrackTTL = 30 * 24 * time.Hour
+	// resolvedTrackMissTTL is how long a link to an unknown track is answered from the cache
+	resolvedTrackMissTTL = 24 * time.Hour
+)
+
+// ErrResolvedTrackNotFound is returned for links to tracks their platform does not have
+var ErrResolvedTrackNotFound = errors.New("track not found")
+
+var (
+	youTubeVideoIDPattern = regexp.MustCompile(`^[0-9A-Za-z_-]{11}$`)
+	youTubeTitleNoise     = regexp.MustCompile(`(?i)\s*[(\[][^)\]]*(official|video|audio|lyric|visuali[sz]er|hd|4k|remaster)[^)\]]*[)\]]`)
+)
+
+// ResolvedTrackResult is a resolved song with a link per platform it was found on
+type ResolvedTrackResult struct {
+	Track  db.ResolvedTrack
+	Links  map[string]string
+	Cached bool
+}
+
+// ParseTrackURL infers platform and track ID from a track link or URI. Supported:
+// open.spotify.com/track/<id> and spotify:track:<id>; youtube.com/watch?v=, youtu.be/<id>,
+// music.youtube.com and /shorts/ links; music.apple.com song links and album links with ?i=;
+// deezer.com/<lang>/track/<id>.
+func ParseTrackURL(raw string) (string, string, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "spotify:") {
+		parts := strings.Split(raw, ":")
+		if id := segmentAfter(parts, "track"); spotifyIDPattern.MatchString(id) {
+			return "spotify", id, nil
+		}
+		return "", "", notATrack("Spotify")
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", "", &PlaylistURLError{PlaylistURLInvalid, "Not a valid URL"}
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+
+	switch host {
+	case "open.spotify.com", "play.spotify.com":
+		if id := segmentAfter(segments, "track"); spotifyIDPattern.MatchString(id) {
+			return "spotify", id, nil
+		}
+		return "", "", notATrack("Spotify")
+
+	case "youtube.com", "m.youtube.com", "music.youtube.com", "youtu.be":
+		id := u.Query().Get("v")
+		if host == "youtu.be" && len(segments) > 0 {
+			id = segments[0]
+		} else if shorts := segmentAfter(segments, "shorts"); shorts != "" {
+			id = shorts
+		}
+		if youTubeVideoIDPattern.MatchString(id) {
+			return "youtube", id, nil
+		}
+		return "", "", notATrack("YouTube")
+
+	case "music.apple.com", "itunes.apple.com":
+		// Album links select a song with ?i=<id>; song links end in /song/<name>/<id>
+		if id := u.Query().Get("i"); deezerIDPattern.MatchString(id) {
+			return "applemusic", id, nil
+		}
+		if segmentAfter(segments, "song") != "" && deezerIDPattern.MatchString(segments[len(segments)-1]) {
+			return "applemusic", segments[len(segments)-1], nil
+		}
+		return "", "", notATrack("Apple Music")
+
+	case "deezer.com":
+		if id := segmentAfter(segments, "track"); deezerIDPattern.MatchString(id) {
+			return "deezer", id, nil
+		}
+		return "", "", notATrack("Deezer")
+	}
+	return "", "", &PlaylistURLError{PlaylistURLUnsupportedHost, fmt.Sprintf("Links from %s are not supported", host)}
+}
+
+func notATrack(platform string) *PlaylistURLError {
+	return &PlaylistURLError{TrackURLNotTrack, fmt.Sprintf("This %s link does not point to a track", platform)}
+}
+
+// ResolveTrackURL resolves a track link to the same song on every platform in
+// ResolvablePlatforms. Results are cached by platform ID and refreshed after resolvedTrackTTL.
+func ResolveTrackURL(ctx context.Context, raw string) (ResolvedTrackResult, error) {
+	platform, id, err := ParseTrackURL(raw)
+	if err != nil {
+		return ResolvedTrackResult{}, err
+	}
+	return ResolveTrack(ctx, platform, id)
+}
+
+// ResolveTrack resolves a track ID on one of ResolvablePlatforms, like ResolveTrackURL
+func ResolveTrack(ctx context.Context, platform, id string) (ResolvedTrackResult, error) {
+	if !slices.Contains(ResolvablePlatforms, platform) {
+		return ResolvedTrackResult{}, fmt.Errorf("unsupported platform: %s", platform)
+	}
+
+	var cached db.ResolvedTrack
+	err := db.DB.Where(resolvedTrackColumn(platform)+" = ?", id).Order("updated_at DESC").First(&cached).Error
+	if err == nil && cached.NotFound && time.Since(cached.UpdatedAt) < resolvedTrackMissTTL {
+		return ResolvedTrackResult{}, fmt.Errorf("%w on %s", ErrResolvedTrackNotFound, platform)
+	}
+	if err == nil && !cached.NotFound && time.Since(cached.UpdatedAt) < resolvedTrackTTL {
+		return ResolvedTrackResult{Track: cached, Links: resolvedTrackLinks(cached), Cached: true}, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return ResolvedTrackResult{}, fmt.Errorf("failed to read resolver cache: %w", err)
+	}
+
+	track, err := lookupSourceTrack(ctx, platform, id)
+	if err == nil && track.Title == "" {
+		err = fmt.Errorf("%w on %s", ErrResolvedTrackNotFound, platform)
+	}
+	if errors.Is(err, ErrResolvedTrackNotFound) {
+		miss := db.ResolvedTrack{ID: cached.ID, CreatedAt: cached.CreatedAt, NotFound: true}
+		if miss.ID == uuid.Nil {
+			miss.ID = uuid.New()
+		}
+		setResolvedTrackID(&miss, platform, id)
+		if err := db.DB.Save(&miss).Error; err != nil {
+			log.Printf("Track resolver: failed to cache miss for %s %s: %v", platform, id, err)
+		}
+	}
+	if err != nil {
+		return ResolvedTrackResult{}, err
+	}
+
+	for _, target := range ResolvablePlatforms {
+		if target == platform {
+			continue
+		}
+		matchID, err := searchResolvablePlatform(ctx, target, track)
+		if err != nil {
+			log.Printf("Track resolver: %s search failed for %q: %v", target, track.Title, err)
+			continue
+		}
+		setResolvedTrackID(&track, target, matchID)
+	}
+
+	if cached.ID != uuid.Nil {
+		track.ID, track.CreatedAt = cached.ID, cached.CreatedAt
+	} else {
+		track.ID = uuid.New()
+	}
+	if err := db.DB.Save(&track).Error; err != nil {
+		log.Printf("Track resolver: failed to cache %q: %v", track.Title, err)
+	}
+	return ResolvedTrackResult{Track: track, Links: resolvedTrackLinks(track)}, nil
+}
+
+// PlaylistTrack converts a resolved song into a playlist track carrying its ID on every platform
+func (r ResolvedTrackResult) PlaylistTrack() db.Track {
+	return db.Track{
+		Title:        r.Track.Title,
+		Artist:       r.Track.Artist,
+		Album:        r.Track.Album,
+		ISRC:         r.Track.ISRC,
+		DurationMs:   r.Track.DurationMs,
+		SpotifyID:    r.Track.SpotifyID,
+		YouTubeID:    r.Track.YouTubeID,
+		AppleMusicID: r.Track.AppleMusicID,
+		DeezerID:     r.Track.DeezerID,
+	}
+}
+
+func resolvedTrackColumn(platform string) string {
+	switch platform {
+	case "spotify":
+		return "spotify_id"
+	case "youtube":
+		return "you_tube_id"
+	case "applemusic":
+		return "applemusic_id"
+	default:
+		return "deezer_id"
+	}
+}
+
+func setResolvedTrackID(track *db.ResolvedTrack, platform, id string) {
+	switch platform {
+	case "spotify":
+		track.SpotifyID = id
+	case "youtube":
+		track.YouTubeID = id
+	case "applemusic":
+		track.AppleMusicID = id
+	case "deezer":
+		track.DeezerID = id
+	}
+}
+
+func resolvedTrackLinks(t db.ResolvedTrack) map[string]string {
+	return TrackLinks(db.Track{
+		SpotifyID:    t.SpotifyID,
+		YouTubeID:    t.YouTubeID,
+		AppleMusicID: t.AppleMusicID,
+		DeezerID:     t.DeezerID,
+	}, defaultAppleMusicStorefront())
+}
+
+// lookupSourceTrack fetches the song behind the link from its own platform
+func lookupSourceTrack(ctx context.Context, platform, id string) (db.ResolvedTrack, error) {
+	switch platform {
+	case "spotify":
+		client, err := GetSpotifyAppClient(ctx)
+		if err != nil {
+			return db.ResolvedTrack{}, err
+		}
+		t, err := client.GetTrack(ctx, spotify.ID(id))
+		var spErr spotify.Error
+		if errors.As(err, &spErr) && (spErr.Status == 400 || spErr.Status == 404) {
+			return db.ResolvedTrack{}, fmt.Errorf("%w: Spotify track %s", ErrResolvedTrackNotFound, id)
+		}
+		if err != nil {
+			return db.ResolvedTrack{}, fmt.Errorf("failed to fetch Spotify track: %w", err)
+		}
+		track := db.ResolvedTrack{
+			Title:      t.Name,
+			Artist:     strings.Join(GetArtists(t.Artists), ", "),
+			Album:      t.Album.Name,
+			ISRC:       t.ExternalIDs["isrc"],
+			DurationMs: int(t.Duration),
+			SpotifyID:  id,
+		}
+		if len(t.Album.Images) > 0 {
+			track.ArtworkURL = t.Album.Images[0].URL
+		}
+		return track, nil
+
+	case "youtube":
+		client, err := GetYouTubeAPIKeyClient(ctx)
+		if err != nil {
+			return db.ResolvedTrack{}, err
+		}
+		response, err := client.Videos.List([]string{"snippet"}).Id(id).Context(ctx).Do()
+		if err != nil {
+			return db.ResolvedTrack{}, fmt.Errorf("failed to fetch YouTube video: %w", err)
+		}
+		if len(response.Items) == 0 {
+			return db.ResolvedTrack{}, fmt.Errorf("%w: YouTube video %s", ErrResolvedTrackNotFound, id)
+		}
+		snippet := response.Items[0].Snippet
+		artist, title := splitYouTubeTitle(snippet.Title, snippet.ChannelTitle)
+		track := db.ResolvedTrack{Title: title, Artist: artist, YouTubeID: id}
+		if snippet.Thumbnails != nil && snippet.Thumbnails.High != nil {
+			track.ArtworkURL = snippet.Thumbnails.High.Url
+		}
+		return track, nil
+
+	case "applemusic":
+		client, err := GetAppleMusicCatalogClient()
+		if err != nil {
+			return db.ResolvedTrack{}, err
+		}
+		var result struct {
+			Data []struct {
+				Attributes struct {
+					Name       string `json:"name"`
+					ArtistName string `json:"artistName"`
+					AlbumName  string `json:"albumName"`
+					ISRC       string `json:"isrc"`
+					DurationMs int    `json:"durationInMillis"`
+					Artwork    *struct {
+						URL string `json:"url"`
+					} `json:"artwork"`
+				} `json:"attributes"`
+			} `json:"data"`
+		}
+		path := fmt.Sprintf("/v1/catalog/%s/songs/%s", url.PathEscape(client.Storefront), url.PathEscape(id))
+		err = client.do(ctx, "GET", path, nil, &result)
+		if (err != nil && strings.Contains(err.Error(), "returned 404")) || (err == nil && len(result.Data) == 0) {
+			return db.ResolvedTrack{}, fmt.Errorf("%w: Apple Music song %s", ErrResolvedTrackNotFound, id)
+		}
+		if err != nil {
+			return db.ResolvedTrack{}, fmt.Errorf("failed to fetch Apple Music song: %w", err)
+		}
+		a := result.Data[0].Attributes
+		track := db.ResolvedTrack{
+			Title:        a.Name,
+			Artist:       a.ArtistName,
+			Album:        a.AlbumName,
+			ISRC:         a.ISRC,
+			DurationMs:   a.DurationMs,
+			AppleMusicID: id,
+		}
+		if a.Artwork != nil {
+			track.ArtworkURL = appleMusicArtworkURL(a.Artwork.URL)
+		}
+		return track, nil
+
+	case "deezer":
+		client := NewDeezerClient("")
+		var result struct {
+			ID       int64  `json:"id"`
+			Title    string `json:"title"`
+			ISRC     string `json:"isrc"`
+			Duration int    `json:"duration"` // seconds
+			Artist   struct {
+				Name string `json:"name"`
+			} `json:"artist"`
+			Album struct {
+				Title   string `json:"title"`
+				CoverXL string `json:"cover_xl"`
+			} `json:"album"`
+		}
+		// Deezer answers unknown IDs with error 800 ("no data")
+		err := client.do(ctx, "GET", "/track/"+url.PathEscape(id), nil, &result)
+		if err != nil && strings.Contains(err.Error(), "error 800") {
+			return db.ResolvedTrack{}, fmt.Errorf("%w: Deezer track %s", ErrResolvedTrackNotFound, id)
+		}
+		if err != nil {
+			return db.ResolvedTrack{}, fmt.Errorf("failed to fetch Deezer track: %w", err)
+		}
+		return db.ResolvedTrack{
+			Title:      result.Title,
+			Artist:     result.Artist.Name,
+			Album:      result.Album.Title,
+			ISRC:       result.ISRC,
+			DurationMs: result.Duration * 1000,
+			ArtworkURL: result.Album.CoverXL,
+			DeezerID:   strconv.FormatInt(result.ID, 10),
+		}, nil
+	}
+	return db.ResolvedTrack{}, fmt.Errorf("unsupported platform: %s", platform)
+}
+
+// searchResolvablePlatform finds the song on another platform using the sync search functions
+func searchResolvablePlatform(ctx context.Context, platform string, track db.ResolvedTrack) (string, error) {
+	switch platform {
+	case "spotify":
+		client, err := GetSpotifyAppClient(ctx)
+		if err != nil {
+			return "", err
+		}
+		return SearchSpotifyTrack(ctx, client, track.Title, track.Artist, track.ISRC)
+	case "youtube":
+		client, err := GetYouTubeAPIKeyClient(ctx)
+		if err != nil {
+			return "", err
+		}
+		return SearchYouTubeVideo(ctx, client, track.Title, track.Artist)
+	case "applemusic":
+		client, err := GetAppleMusicCatalogClient()
+		if err != nil {
+			return "", err
+		}
+		return SearchAppleMusicTrack(ctx, client, track.Title, track.Artist)
+	case "deezer":
+		return SearchDeezerTrack(ctx, NewDeezerClient(""), track.Title, track.Artist, track.ISRC)
+	}
+	return "", fmt.Errorf("unsupported platform: %s", platform)
+}
+
+// splitYouTubeTitle derives artist and title from a music video. Auto-generated
+// "Artist - Topic" channels use the plain song title; other uploads usually follow
+// "Artist - Title (Official Video)".
+func splitYouTubeTitle(title, channel string) (string, string) {
+	title = strings.TrimSpace(youTubeTitleNoise.ReplaceAllString(title, ""))
+	if strings.HasSuffix(channel, " - Topic") {
+		return strings.TrimSuffix(channel, " - Topic"), title
+	}
+	if artist, song, ok := strings.Cut(title, " - "); ok {
+		return strings.TrimSpace(artist), strings.TrimSpace(song)
+	}
+	return strings.TrimSuffix(channel, "VEVO"), title
+}
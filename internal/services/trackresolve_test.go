@@ -0,0 +1,47 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTrackURL(t *testing.T) {
+	tests := []struct {
+		raw, platform, id, code string
+	}{
+		{"https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC?si=abc", "spotify", "4uLU6hMCjMI75M1A2tKUQC", ""},
+		{"open.spotify.com/intl-fr/track/4uLU6hMCjMI75M1A2tKUQC", "spotify", "4uLU6hMCjMI75M1A2tKUQC", ""},
+		{"spotify:track:4uLU6hMCjMI75M1A2tKUQC", "spotify", "4uLU6hMCjMI75M1A2tKUQC", ""},
+		{"spotify:album:4uLU6hMCjMI75M1A2tKUQC", "", "", TrackURLNotTrack},
+		{"https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M", "", "", TrackURLNotTrack},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ&list=PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG", "youtube", "dQw4w9WgXcQ", ""},
+		{"https://m.youtube.com/watch?v=dQw4w9WgXcQ", "youtube", "dQw4w9WgXcQ", ""},
+		{"https://music.youtube.com/watch?v=dQw4w9WgXcQ", "youtube", "dQw4w9WgXcQ", ""},
+		{"https://youtu.be/dQw4w9WgXcQ?t=42", "youtube", "dQw4w9WgXcQ", ""},
+		{"https://www.youtube.com/shorts/dQw4w9WgXcQ", "youtube", "dQw4w9WgXcQ", ""},
+		{"https://www.youtube.com/watch?v=short", "", "", TrackURLNotTrack},
+		{"https://music.apple.com/us/album/never-gonna-give-you-up/1440857779?i=1440857781", "applemusic", "1440857781", ""},
+		{"https://music.apple.com/gb/song/never-gonna-give-you-up/1440857781", "applemusic", "1440857781", ""},
+		{"https://music.apple.com/us/album/never-gonna-give-you-up/1440857779", "", "", TrackURLNotTrack},
+		{"https://www.deezer.com/en/track/3135556", "deezer", "3135556", ""},
+		{"https://deezer.com/track/3135556", "deezer", "3135556", ""},
+		{"https://www.deezer.com/en/album/302127", "", "", TrackURLNotTrack},
+		{"https://open.spotify.com.evil.example/track/4uLU6hMCjMI75M1A2tKUQC", "", "", PlaylistURLUnsupportedHost},
+		{"https://", "", "", PlaylistURLInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			platform, id, err := ParseTrackURL(tt.raw)
+			var urlErr *PlaylistURLError
+			if tt.code != "" {
+				if !errors.As(err, &urlErr) || urlErr.Code != tt.code {
+					t.Fatalf("got %q, %q, %v; want error code %q", platform, id, err, tt.code)
+				}
+				return
+			}
+			if err != nil || platform != tt.platform || id != tt.id {
+				t.Errorf("got %q, %q, %v; want %q, %q", platform, id, err, tt.platform, tt.id)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"EchoBridge/db"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// --- TRACK SEARCH ---
+
+// TrackSearchResult is one candidate song from a platform search
+type TrackSearchResult struct {
+	Platform   string `json:"platform"`
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Artist     string `json:"artist"`
+	Album      string `json:"album,omitempty"`
+	DurationMs int    `json:"duration_ms,omitempty"`
+	ISRC       string `json:"isrc,omitempty"`
+	ArtworkURL string `json:"artwork_url,omitempty"`
+	URL        string `json:"url"`
+}
+
+// SearchPlatformsFor returns the searchable platforms the user has connected, or all of
+// ResolvablePlatforms when none is connected, since those can be searched with server credentials
+func SearchPlatformsFor(user db.User) []string {
+	var platforms []string
+	for _, p := range LinkedSyncPlatforms(user) {
+		if slices.Contains(ResolvablePlatforms, p) {
+			platforms = append(platforms, p)
+		}
+	}
+	if len(platforms) == 0 {
+		return ResolvablePlatforms
+	}
+	return platforms
+}
+
+// SearchTracks searches each platform for songs matching the query. A platform that fails
+// is reported in the errors map and does not fail the whole search.
+func SearchTracks(ctx context.Context, user db.User, query string, platforms []string, limit int) (map[string][]TrackSearchResult, map[string]string) {
+	results := make(map[string][]TrackSearchResult)
+	errs := make(map[string]string)
+	for _, platform := range platforms {
+		found, err := searchTracksOn(ctx, user, platform, query, limit)
+		if err != nil {
+			log.Printf("Track search on %s failed for %q: %v", platform, query, err)
+			errs[platform] = err.Error()
+			continue
+		}
+		for i := range found {
+			found[i].Platform = platform
+			found[i].URL = TrackLinks(searchResultTrack(found[i]), user.AppleMusicStorefront)[platform]
+		}
+		results[platform] = found
+	}
+	return results, errs
+}
+
+// searchResultTrack sets the platform ID of a result on a track, for building links
+func searchResultTrack(r TrackSearchResult) db.Track {
+	t := db.Track{Title: r.Title, Artist: r.Artist}
+	switch r.Platform {
+	case "spotify":
+		t.SpotifyID = r.ID
+	case "youtube":
+		t.YouTubeID = r.ID
+	case "applemusic":
+		t.AppleMusicID = r.ID
+	case "deezer":
+		t.DeezerID = r.ID
+	}
+	return t
+}
+
+func searchTracksOn(ctx context.Context, user db.User, platform, query string, limit int) ([]TrackSearchResult, error) {
+	switch platform {
+	case "spotify":
+		client, err := GetSpotifyReadClient(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		page, err := client.Search(ctx, query, spotify.SearchTypeTrack, spotify.Limit(limit))
+		if err != nil {
+			return nil, fmt.Errorf("failed to search Spotify: %w", err)
+		}
+		results := []TrackSearchResult{}
+		if page.Tracks == nil {
+			return results, nil
+		}
+		for _, t := range page.Tracks.Tracks {
+			r := TrackSearchResult{
+				ID:         t.ID.String(),
+				Title:      t.Name,
+				Artist:     strings.Join(GetArtists(t.Artists), ", "),
+				Album:      t.Album.Name,
+				DurationMs: int(t.Duration),
+				ISRC:       t.ExternalIDs["isrc"],
+			}
+			if len(t.Album.Images) > 0 {
+				r.ArtworkURL = t.Album.Images[0].URL
+			}
+			results = append(results, r)
+		}
+		return results, nil
+
+	case "youtube":
+		client, err := GetYouTubeReadClient(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		// Category 10 is Music
+		response, err := client.Search.List([]string{"snippet"}).Q(query).Type("video").VideoCategoryId("10").MaxResults(int64(limit)).Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to search YouTube: %w", err)
+		}
+		results := []TrackSearchResult{}
+		for _, item := range response.Items {
+			if item.Id == nil || item.Snippet == nil {
+				continue
+			}
+			artist, title := splitYouTubeTitle(item.Snippet.Title, item.Snippet.ChannelTitle)
+			r := TrackSearchResult{ID: item.Id.VideoId, Title: title, Artist: artist}
+			if item.Snippet.Thumbnails != nil && item.Snippet.Thumbnails.High != nil {
+				r.ArtworkURL = item.Snippet.Thumbnails.High.Url
+			}
+			results = append(results, r)
+		}
+		return results, nil
+
+	case "applemusic":
+		client, err := GetAppleMusicCatalogClient()
+		if err != nil {
+			return nil, err
+		}
+		if user.AppleMusicStorefront != "" {
+			client.Storefront = user.AppleMusicStorefront
+		}
+		params := url.Values{}
+		params.Set("term", query)
+		params.Set("types", "songs")
+		params.Set("limit", strconv.Itoa(limit))
+		var result struct {
+			Results struct {
+				Songs struct {
+					Data []struct {
+						ID         string `json:"id"`
+						Attributes struct {
+							Name       string `json:"name"`
+							ArtistName string `json:"artistName"`
+							AlbumName  string `json:"albumName"`
+							ISRC       string `json:"isrc"`
+							DurationMs int    `json:"durationInMillis"`
+							Artwork    *struct {
+								URL string `json:"url"`
+							} `json:"artwork"`
+						} `json:"attributes"`
+					} `json:"data"`
+				} `json:"songs"`
+			} `json:"results"`
+		}
+		path := fmt.Sprintf("/v1/catalog/%s/search?%s", url.PathEscape(client.Storefront), params.Encode())
+		if err := client.do(ctx, "GET", path, nil, &result); err != nil {
+			return nil, fmt.Errorf("failed to search Apple Music: %w", err)
+		}
+		results := []TrackSearchResult{}
+		for _, song := range result.Results.Songs.Data {
+			a := song.Attributes
+			r := TrackSearchResult{
+				ID:         song.ID,
+				Title:      a.Name,
+				Artist:     a.ArtistName,
+				Album:      a.AlbumName,
+				DurationMs: a.DurationMs,
+				ISRC:       a.ISRC,
+			}
+			if a.Artwork != nil {
+				r.ArtworkURL = appleMusicArtworkURL(a.Artwork.URL)
+			}
+			results = append(results, r)
+		}
+		return results, nil
+
+	case "deezer":
+		params := url.Values{}
+		params.Set("q", query)
+		params.Set("limit", strconv.Itoa(limit))
+		var result struct {
+			Data []struct {
+				ID       int64  `json:"id"`
+				Title    string `json:"title"`
+				Duration int    `json:"duration"` // seconds
+				Artist   struct {
+					Name string `json:"name"`
+				} `json:"artist"`
+				Album struct {
+					Title   string `json:"title"`
+					CoverXL string `json:"cover_xl"`
+				} `json:"album"`
+			} `json:"data"`
+		}
+		if err := NewDeezerClient("").do(ctx, "GET", "/search/track", params, &result); err != nil {
+			return nil, fmt.Errorf("failed to search Deezer: %w", err)
+		}
+		results := []TrackSearchResult{}
+		for _, t := range result.Data {
+			results = append(results, TrackSearchResult{
+				ID:         strconv.FormatInt(t.ID, 10),
+				Title:      t.Title,
+				Artist:     t.Artist.Name,
+				Album:      t.Album.Title,
+				DurationMs: t.Duration * 1000,
+				ArtworkURL: t.Album.CoverXL,
+			})
+		}
+		return results, nil
+	}
+	return nil, fmt.Errorf("searching %s is not supported", platform)
+}
+
+// FindTrack picks a song by free-text search: the best match on the first platform that has
+// one, resolved to its ID on every platform so the playlist can sync anywhere
+func FindTrack(ctx context.Context, user db.User, query string) (db.Track, error) {
+	for _, platform := range SearchPlatformsFor(user) {
+		found, err := searchTracksOn(ctx, user, platform, query, 1)
+		if err != nil {
+			log.Printf("Track search on %s failed for %q: %v", platform, query, err)
+			continue
+		}
+		if len(found) == 0 {
+			continue
+		}
+
+		hit := found[0]
+		resolved, err := ResolveTrack(ctx, platform, hit.ID)
+		if err != nil {
+			log.Printf("Track search: failed to resolve %s track %s: %v", platform, hit.ID, err)
+			hit.Platform = platform
+			track := searchResultTrack(hit)
+			track.Album, track.DurationMs, track.ISRC = hit.Album, hit.DurationMs, hit.ISRC
+			return track, nil
+		}
+		return resolved.PlaylistTrack(), nil
+	}
+	return db.Track{}, fmt.Errorf("no track found for %q", query)
+}
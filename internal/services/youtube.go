@@ -52,6 +52,25 @@ func GetYouTubeClient(ctx context.Context, user db.User) (*youtube.Service, erro
 	return youtube.NewService(ctx, option.WithTokenSource(tokenSource))
 }
 
+// GetYouTubeAPIKeyClient returns a client using the server's API key. It can read public and
+// unlisted playlists but cannot write.
+func GetYouTubeAPIKeyClient(ctx context.Context) (*youtube.Service, error) {
+	apiKey := os.Getenv("YOUTUBE_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("YouTube API key not configured")
+	}
+	return youtube.NewService(ctx, option.WithAPIKey(apiKey))
+}
+
+// GetYouTubeReadClient returns the user's client when YouTube is linked, otherwise the
+// API key client, so public playlists can be imported without linking YouTube
+func GetYouTubeReadClient(ctx context.Context, user db.User) (*youtube.Service, error) {
+	if user.YouTubeToken != "" {
+		return GetYouTubeClient(ctx, user)
+	}
+	return GetYouTubeAPIKeyClient(ctx)
+}
+
 // GetYouTubePlaylists retrieves user playlists
 func GetYouTubePlaylists(ctx context.Context, service *youtube.Service) ([]db.Playlist, error) {
 	call := service.Playlists.List([]string{"id", "snippet", "contentDetails"}).Mine(true)
@@ -116,25 +135,50 @@ func GetYouTubePlaylistTracks(ctx context.Context, service *youtube.Service, pla
 
 // ImportToYouTube creates a YouTube playlist and adds tracks
 func ImportToYouTube(ctx context.Context, client *youtube.Service, playlist db.Playlist, tracks []db.Track) (string, error) {
-	fmt.Printf("Creating YouTube playlist: %s\n", playlist.Title)
-	playlistID, err := CreateYouTubePlaylist(ctx, client, playlist.Title, playlist.Description)
+	return syncToYouTube(ctx, client, playlist, tracks, uuid.Nil)
+}
+
+// syncToYouTube adds tracks to the job's YouTube playlist, creating it on the first run.
+// Tracks checkpointed by an earlier run of the same job are skipped, and the run stops
+// on quotaExceeded so the job can be resumed once the quota resets.
+func syncToYouTube(ctx context.Context, client *youtube.Service, playlist db.Playlist, tracks []db.Track, jobID uuid.UUID) (string, error) {
+	playlistID := loadSyncDestination(jobID, "youtube")
+	if playlistID == "" {
+		fmt.Printf("Creating YouTube playlist: %s\n", playlist.Title)
+		var err error
+		playlistID, err = CreateYouTubePlaylist(ctx, client, playlist.Title, playlist.Description)
+		if err != nil {
+			fmt.Printf("Failed to create YouTube playlist: %v\n", err)
+			return "", fmt.Errorf("failed to create YouTube playlist: %w", err)
+		}
+		fmt.Printf("Created YouTube playlist ID: %s\n", playlistID)
+		SaveSyncDestination(jobID, "youtube", playlistID)
+	} else {
+		fmt.Printf("Resuming into existing YouTube playlist ID: %s\n", playlistID)
+	}
+
+	checkpoints, err := loadSyncCheckpoints(jobID, "youtube")
 	if err != nil {
-		fmt.Printf("Failed to create YouTube playlist: %v\n", err)
-		return "", fmt.Errorf("failed to create YouTube playlist: %w", err)
+		return playlistID, err
 	}
-	fmt.Printf("Created YouTube playlist ID: %s\n", playlistID)
 
+	successCount := 0
 	for _, track := range tracks {
+		if checkpoints.isDone(track.ID) {
+			continue
+		}
 		videoID, err := SearchYouTubeVideo(ctx, client, track.Title, track.Artist)
 		if err != nil {
 			if strings.Contains(err.Error(), "quotaExceeded") {
 				return playlistID, fmt.Errorf("quota exceeded during search: %w", err)
 			}
 			fmt.Printf("Skipping track %s - %s (search failed): %v\n", track.Title, track.Artist, err)
+			checkpoints.record(track.ID, "failed", "")
 			continue
 		}
 		if videoID == "" {
 			fmt.Printf("Skipping track %s - %s (not found)\n", track.Title, track.Artist)
+			checkpoints.record(track.ID, "not_found", "")
 			continue
 		}
 		if err := AddYouTubePlaylistItem(ctx, client, playlistID, videoID); err != nil {
@@ -142,11 +186,15 @@ func ImportToYouTube(ctx context.Context, client *youtube.Service, playlist db.P
 				return playlistID, fmt.Errorf("quota exceeded during add: %w", err)
 			}
 			fmt.Printf("Failed to add track to YouTube: %v\n", err)
+			checkpoints.record(track.ID, "failed", videoID)
 		} else {
 			fmt.Printf("Added track %s to YouTube playlist\n", track.Title)
+			checkpoints.record(track.ID, "added", videoID)
+			successCount++
 		}
 		time.Sleep(500 * time.Millisecond)
 	}
+	fmt.Printf("YouTube sync finished. Added %d/%d songs.\n", successCount, len(tracks))
 
 	return playlistID, nil
 }
@@ -245,6 +293,7 @@ func ImportAllYouTubePlaylists(ctx context.Context, user db.User) error {
 			t.PlaylistID = p.ID
 			db.DB.Create(&t)
 		}
+		RecordImportVersion(p.ID, user.ID)
 	}
 	return nil
 }
@@ -292,5 +341,6 @@ func ImportYouTubePlaylist(ctx context.Context, client *youtube.Service, user db
 		t.PlaylistID = playlist.ID
 		db.DB.Create(&t)
 	}
+	RecordImportVersion(playlist.ID, user.ID)
 	return nil
 }
@@ -22,19 +22,33 @@ func FetchUserActivity(ctx context.Context, userID uuid.UUID) (*db.User, error)
 }
 
 func FetchPlaylistActivity(ctx context.Context, playlistID, userID uuid.UUID) (*db.Playlist, error) {
-	var playlist db.Playlist
-	if err := db.DB.Where("id = ? AND owner_id = ?", playlistID, userID).First(&playlist).Error; err != nil {
-		return nil, fmt.Errorf("playlist not found: %w", err)
+	playlist, _, err := services.LoadPlaylistWithRole(playlistID, userID, services.PlaylistRoleViewer)
+	if err != nil {
+		return nil, err
 	}
 	return &playlist, nil
 }
 
 func FetchPlaylistTracksActivity(ctx context.Context, playlistID uuid.UUID) ([]db.Track, error) {
-	var tracks []db.Track
-	if err := db.DB.Where("playlist_id = ?", playlistID).Find(&tracks).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch tracks: %w", err)
+	return services.PlaylistTracks(playlistID)
+}
+
+func RecordSyncDestinationActivity(ctx context.Context, jobID uuid.UUID, platform, playlistID string) error {
+	services.SaveSyncDestination(jobID, platform, playlistID)
+	return nil
+}
+
+// FinishSyncJobActivity marks a sync job completed, or failed when errMsg is set. The result
+// keeps the destinations recorded while the workflow ran.
+func FinishSyncJobActivity(ctx context.Context, jobID uuid.UUID, errMsg string) error {
+	updates := map[string]interface{}{"status": "completed", "completed_at": time.Now()}
+	if errMsg != "" {
+		updates = map[string]interface{}{"status": "failed", "error_msg": errMsg}
+	}
+	if err := db.DB.Model(&db.SyncJob{}).Where("id = ?", jobID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update sync job: %w", err)
 	}
-	return tracks, nil
+	return nil
 }
 
 func CreateSpotifyPlaylistActivity(ctx context.Context, user db.User, playlist db.Playlist) (string, error) {
@@ -145,7 +159,7 @@ func AddVideoToYouTubePlaylistActivity(ctx context.Context, user db.User, playli
 }
 
 func ImportSpotifyPlaylistActivity(ctx context.Context, user db.User, sourceID string) error {
-	client, err := services.GetSpotifyClient(ctx, user)
+	client, err := services.GetSpotifyReadClient(ctx, user)
 	if err != nil {
 		return err
 	}
@@ -153,9 +167,399 @@ func ImportSpotifyPlaylistActivity(ctx context.Context, user db.User, sourceID s
 }
 
 func ImportYouTubePlaylistActivity(ctx context.Context, user db.User, sourceID string) error {
-	client, err := services.GetYouTubeClient(ctx, user)
+	client, err := services.GetYouTubeReadClient(ctx, user)
 	if err != nil {
 		return err
 	}
 	return services.ImportYouTubePlaylist(ctx, client, user, sourceID)
 }
+
+func CreateAppleMusicPlaylistActivity(ctx context.Context, user db.User, playlist db.Playlist) (string, error) {
+	client, err := services.GetAppleMusicClient(ctx, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Apple Music client: %w", err)
+	}
+
+	playlistID, err := services.CreateAppleMusicPlaylist(ctx, client, playlist.Title, playlist.Description)
+	if err != nil {
+		if strings.Contains(err.Error(), "429") {
+			time.Sleep(30 * time.Second)
+			return "", fmt.Errorf("rate limited, will retry: %w", err)
+		}
+		return "", fmt.Errorf("failed to create Apple Music playlist: %w", err)
+	}
+	return playlistID, nil
+}
+
+func SearchAppleMusicTrackActivity(ctx context.Context, user db.User, title, artist string) (string, error) {
+	client, err := services.GetAppleMusicClient(ctx, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Apple Music client: %w", err)
+	}
+
+	songID, err := services.SearchAppleMusicTrack(ctx, client, title, artist)
+	if err != nil {
+		if strings.Contains(err.Error(), "429") {
+			time.Sleep(30 * time.Second)
+			return "", fmt.Errorf("rate limited, will retry: %w", err)
+		}
+		return "", fmt.Errorf("search failed: %w", err)
+	}
+	return songID, nil
+}
+
+func AddTrackToAppleMusicActivity(ctx context.Context, user db.User, playlistID, songID string) error {
+	client, err := services.GetAppleMusicClient(ctx, user)
+	if err != nil {
+		return fmt.Errorf("failed to get Apple Music client: %w", err)
+	}
+
+	if err := services.AddAppleMusicPlaylistTracks(ctx, client, playlistID, songID); err != nil {
+		if strings.Contains(err.Error(), "429") {
+			time.Sleep(30 * time.Second)
+			return fmt.Errorf("rate limited, will retry: %w", err)
+		}
+		return fmt.Errorf("failed to add track: %w", err)
+	}
+	return nil
+}
+
+func ImportAppleMusicPlaylistActivity(ctx context.Context, user db.User, sourceID string) error {
+	client, err := services.GetAppleMusicClient(ctx, user)
+	if err != nil {
+		return err
+	}
+	return services.ImportAppleMusicPlaylist(ctx, client, user, sourceID)
+}
+
+func CreateDeezerPlaylistActivity(ctx context.Context, user db.User, playlist db.Playlist) (string, error) {
+	client, err := services.GetDeezerClient(ctx, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Deezer client: %w", err)
+	}
+
+	playlistID, err := services.CreateDeezerPlaylist(ctx, client, playlist.Title)
+	if err != nil {
+		if strings.Contains(err.Error(), "429") {
+			time.Sleep(10 * time.Second)
+			return "", fmt.Errorf("rate limited, will retry: %w", err)
+		}
+		return "", fmt.Errorf("failed to create Deezer playlist: %w", err)
+	}
+	return playlistID, nil
+}
+
+func SearchDeezerTrackActivity(ctx context.Context, user db.User, title, artist, isrc string) (string, error) {
+	client, err := services.GetDeezerClient(ctx, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Deezer client: %w", err)
+	}
+
+	trackID, err := services.SearchDeezerTrack(ctx, client, title, artist, isrc)
+	if err != nil {
+		if strings.Contains(err.Error(), "429") {
+			time.Sleep(10 * time.Second)
+			return "", fmt.Errorf("rate limited, will retry: %w", err)
+		}
+		return "", fmt.Errorf("search failed: %w", err)
+	}
+	return trackID, nil
+}
+
+func AddTrackToDeezerActivity(ctx context.Context, user db.User, playlistID, trackID string) error {
+	client, err := services.GetDeezerClient(ctx, user)
+	if err != nil {
+		return fmt.Errorf("failed to get Deezer client: %w", err)
+	}
+
+	if err := services.AddDeezerPlaylistTracks(ctx, client, playlistID, trackID); err != nil {
+		if strings.Contains(err.Error(), "429") {
+			time.Sleep(10 * time.Second)
+			return fmt.Errorf("rate limited, will retry: %w", err)
+		}
+		return fmt.Errorf("failed to add track: %w", err)
+	}
+	return nil
+}
+
+func ImportDeezerPlaylistActivity(ctx context.Context, user db.User, sourceID string) error {
+	client, err := services.GetDeezerClient(ctx, user)
+	if err != nil {
+		return err
+	}
+	return services.ImportDeezerPlaylist(ctx, client, user, sourceID)
+}
+
+func CreateTidalPlaylistActivity(ctx context.Context, user db.User, playlist db.Playlist) (string, error) {
+	client, err := services.GetTidalClient(ctx, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get TIDAL client: %w", err)
+	}
+
+	playlistID, err := services.CreateTidalPlaylist(ctx, client, playlist.Title, playlist.Description)
+	if err != nil {
+		if strings.Contains(err.Error(), "429") {
+			time.Sleep(10 * time.Second)
+			return "", fmt.Errorf("rate limited, will retry: %w", err)
+		}
+		return "", fmt.Errorf("failed to create TIDAL playlist: %w", err)
+	}
+	return playlistID, nil
+}
+
+func SearchTidalTrackActivity(ctx context.Context, user db.User, title, artist, isrc string) (string, error) {
+	client, err := services.GetTidalClient(ctx, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get TIDAL client: %w", err)
+	}
+
+	trackID, err := services.SearchTidalTrack(ctx, client, title, artist, isrc)
+	if err != nil {
+		if strings.Contains(err.Error(), "429") {
+			time.Sleep(10 * time.Second)
+			return "", fmt.Errorf("rate limited, will retry: %w", err)
+		}
+		return "", fmt.Errorf("search failed: %w", err)
+	}
+	return trackID, nil
+}
+
+func AddTrackToTidalActivity(ctx context.Context, user db.User, playlistID, trackID string) error {
+	client, err := services.GetTidalClient(ctx, user)
+	if err != nil {
+		return fmt.Errorf("failed to get TIDAL client: %w", err)
+	}
+
+	if err := services.AddTidalPlaylistTracks(ctx, client, playlistID, trackID); err != nil {
+		if strings.Contains(err.Error(), "429") {
+			time.Sleep(10 * time.Second)
+			return fmt.Errorf("rate limited, will retry: %w", err)
+		}
+		return fmt.Errorf("failed to add track: %w", err)
+	}
+	return nil
+}
+
+func ImportTidalPlaylistActivity(ctx context.Context, user db.User, sourceID string) error {
+	client, err := services.GetTidalClient(ctx, user)
+	if err != nil {
+		return err
+	}
+	return services.ImportTidalPlaylist(ctx, client, user, sourceID)
+}
+
+func CreateSubsonicPlaylistActivity(ctx context.Context, user db.User, playlist db.Playlist) (string, error) {
+	client, err := services.GetSubsonicClient(ctx, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Subsonic client: %w", err)
+	}
+
+	playlistID, err := services.CreateSubsonicPlaylist(ctx, client, playlist.Title)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Subsonic playlist: %w", err)
+	}
+	return playlistID, nil
+}
+
+func SearchSubsonicTrackActivity(ctx context.Context, user db.User, title, artist string) (string, error) {
+	client, err := services.GetSubsonicClient(ctx, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Subsonic client: %w", err)
+	}
+
+	songID, err := services.SearchSubsonicTrack(ctx, client, title, artist)
+	if err != nil {
+		return "", fmt.Errorf("search failed: %w", err)
+	}
+	return songID, nil
+}
+
+func AddTrackToSubsonicActivity(ctx context.Context, user db.User, playlistID, songID string) error {
+	client, err := services.GetSubsonicClient(ctx, user)
+	if err != nil {
+		return fmt.Errorf("failed to get Subsonic client: %w", err)
+	}
+
+	if err := services.AddSubsonicPlaylistTracks(ctx, client, playlistID, songID); err != nil {
+		return fmt.Errorf("failed to add track: %w", err)
+	}
+	return nil
+}
+
+func ImportSubsonicPlaylistActivity(ctx context.Context, user db.User, sourceID string) error {
+	client, err := services.GetSubsonicClient(ctx, user)
+	if err != nil {
+		return err
+	}
+	return services.ImportSubsonicPlaylist(ctx, client, user, sourceID)
+}
+
+func CreateJellyfinPlaylistActivity(ctx context.Context, user db.User, playlist db.Playlist) (string, error) {
+	client, err := services.GetJellyfinClient(ctx, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Jellyfin client: %w", err)
+	}
+
+	playlistID, err := services.CreateJellyfinPlaylist(ctx, client, playlist.Title)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Jellyfin playlist: %w", err)
+	}
+	return playlistID, nil
+}
+
+func SearchJellyfinTrackActivity(ctx context.Context, user db.User, title, artist, musicBrainzID string) (string, error) {
+	client, err := services.GetJellyfinClient(ctx, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Jellyfin client: %w", err)
+	}
+
+	itemID, err := services.SearchJellyfinTrack(ctx, client, title, artist, musicBrainzID)
+	if err != nil {
+		return "", fmt.Errorf("search failed: %w", err)
+	}
+	return itemID, nil
+}
+
+func AddTrackToJellyfinActivity(ctx context.Context, user db.User, playlistID, itemID string) error {
+	client, err := services.GetJellyfinClient(ctx, user)
+	if err != nil {
+		return fmt.Errorf("failed to get Jellyfin client: %w", err)
+	}
+
+	if err := services.AddJellyfinPlaylistTracks(ctx, client, playlistID, itemID); err != nil {
+		return fmt.Errorf("failed to add track: %w", err)
+	}
+	return nil
+}
+
+func ImportJellyfinPlaylistActivity(ctx context.Context, user db.User, sourceID string) error {
+	client, err := services.GetJellyfinClient(ctx, user)
+	if err != nil {
+		return err
+	}
+	return services.ImportJellyfinPlaylist(ctx, client, user, sourceID)
+}
+
+func CreateSoundCloudPlaylistActivity(ctx context.Context, user db.User, playlist db.Playlist) (string, error) {
+	client, err := services.GetSoundCloudClient(ctx, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get SoundCloud client: %w", err)
+	}
+
+	playlistID, err := services.CreateSoundCloudPlaylist(ctx, client, playlist.Title, playlist.Description)
+	if err != nil {
+		if strings.Contains(err.Error(), "429") {
+			time.Sleep(10 * time.Second)
+			return "", fmt.Errorf("rate limited, will retry: %w", err)
+		}
+		return "", fmt.Errorf("failed to create SoundCloud playlist: %w", err)
+	}
+	return playlistID, nil
+}
+
+func SearchSoundCloudTrackActivity(ctx context.Context, user db.User, title, artist string) (string, error) {
+	client, err := services.GetSoundCloudClient(ctx, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get SoundCloud client: %w", err)
+	}
+
+	trackID, err := services.SearchSoundCloudTrack(ctx, client, title, artist)
+	if err != nil {
+		if strings.Contains(err.Error(), "429") {
+			time.Sleep(10 * time.Second)
+			return "", fmt.Errorf("rate limited, will retry: %w", err)
+		}
+		return "", fmt.Errorf("search failed: %w", err)
+	}
+	return trackID, nil
+}
+
+func AddTrackToSoundCloudActivity(ctx context.Context, user db.User, playlistID, trackID string) error {
+	client, err := services.GetSoundCloudClient(ctx, user)
+	if err != nil {
+		return fmt.Errorf("failed to get SoundCloud client: %w", err)
+	}
+
+	if err := services.AddSoundCloudPlaylistTracks(ctx, client, playlistID, trackID); err != nil {
+		if strings.Contains(err.Error(), "429") {
+			time.Sleep(10 * time.Second)
+			return fmt.Errorf("rate limited, will retry: %w", err)
+		}
+		return fmt.Errorf("failed to add track: %w", err)
+	}
+	return nil
+}
+
+func ImportSoundCloudPlaylistActivity(ctx context.Context, user db.User, sourceID string) error {
+	client, err := services.GetSoundCloudClient(ctx, user)
+	if err != nil {
+		return err
+	}
+	return services.ImportSoundCloudPlaylist(ctx, client, user, sourceID)
+}
+
+func CreateListenBrainzPlaylistActivity(ctx context.Context, user db.User, playlist db.Playlist) (string, error) {
+	client, err := services.GetListenBrainzClient(ctx, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get ListenBrainz client: %w", err)
+	}
+
+	playlistID, err := services.CreateListenBrainzPlaylist(ctx, client, playlist.Title, playlist.Description)
+	if err != nil {
+		if strings.Contains(err.Error(), "429") {
+			time.Sleep(10 * time.Second)
+			return "", fmt.Errorf("rate limited, will retry: %w", err)
+		}
+		return "", fmt.Errorf("failed to create ListenBrainz playlist: %w", err)
+	}
+	return playlistID, nil
+}
+
+func SearchListenBrainzTrackActivity(ctx context.Context, user db.User, title, artist string) (string, error) {
+	client, err := services.GetListenBrainzClient(ctx, user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get ListenBrainz client: %w", err)
+	}
+
+	recordingMBID, err := services.SearchListenBrainzRecording(ctx, client, title, artist)
+	if err != nil {
+		if strings.Contains(err.Error(), "429") {
+			time.Sleep(10 * time.Second)
+			return "", fmt.Errorf("rate limited, will retry: %w", err)
+		}
+		return "", fmt.Errorf("search failed: %w", err)
+	}
+	return recordingMBID, nil
+}
+
+func AddTrackToListenBrainzActivity(ctx context.Context, user db.User, playlistID, recordingMBID string) error {
+	client, err := services.GetListenBrainzClient(ctx, user)
+	if err != nil {
+		return fmt.Errorf("failed to get ListenBrainz client: %w", err)
+	}
+
+	if err := services.AddListenBrainzPlaylistTracks(ctx, client, playlistID, recordingMBID); err != nil {
+		if strings.Contains(err.Error(), "429") {
+			time.Sleep(10 * time.Second)
+			return fmt.Errorf("rate limited, will retry: %w", err)
+		}
+		return fmt.Errorf("failed to add track: %w", err)
+	}
+	return nil
+}
+
+func ImportListenBrainzPlaylistActivity(ctx context.Context, user db.User, sourceID string) error {
+	client, err := services.GetListenBrainzClient(ctx, user)
+	if err != nil {
+		return err
+	}
+	return services.ImportListenBrainzPlaylist(ctx, client, user, sourceID)
+}
+
+func ImportLastFMPlaylistActivity(ctx context.Context, user db.User, sourceID string) error {
+	client, err := services.GetLastFMClient(ctx, user)
+	if err != nil {
+		return err
+	}
+	return services.ImportLastFMPlaylist(ctx, client, user, sourceID)
+}
@@ -20,6 +20,8 @@ func StartWorker() error {
 	w.RegisterActivity(FetchUserActivity)
 	w.RegisterActivity(FetchPlaylistActivity)
 	w.RegisterActivity(FetchPlaylistTracksActivity)
+	w.RegisterActivity(RecordSyncDestinationActivity)
+	w.RegisterActivity(FinishSyncJobActivity)
 	w.RegisterActivity(CreateSpotifyPlaylistActivity)
 	w.RegisterActivity(SearchSpotifyTrackActivity)
 	w.RegisterActivity(AddTrackToSpotifyActivity)
@@ -28,6 +30,35 @@ func StartWorker() error {
 	w.RegisterActivity(AddVideoToYouTubePlaylistActivity)
 	w.RegisterActivity(ImportSpotifyPlaylistActivity)
 	w.RegisterActivity(ImportYouTubePlaylistActivity)
+	w.RegisterActivity(CreateAppleMusicPlaylistActivity)
+	w.RegisterActivity(SearchAppleMusicTrackActivity)
+	w.RegisterActivity(AddTrackToAppleMusicActivity)
+	w.RegisterActivity(ImportAppleMusicPlaylistActivity)
+	w.RegisterActivity(CreateDeezerPlaylistActivity)
+	w.RegisterActivity(SearchDeezerTrackActivity)
+	w.RegisterActivity(AddTrackToDeezerActivity)
+	w.RegisterActivity(ImportDeezerPlaylistActivity)
+	w.RegisterActivity(CreateTidalPlaylistActivity)
+	w.RegisterActivity(SearchTidalTrackActivity)
+	w.RegisterActivity(AddTrackToTidalActivity)
+	w.RegisterActivity(ImportTidalPlaylistActivity)
+	w.RegisterActivity(CreateSubsonicPlaylistActivity)
+	w.RegisterActivity(SearchSubsonicTrackActivity)
+	w.RegisterActivity(AddTrackToSubsonicActivity)
+	w.RegisterActivity(ImportSubsonicPlaylistActivity)
+	w.RegisterActivity(CreateJellyfinPlaylistActivity)
+	w.RegisterActivity(SearchJellyfinTrackActivity)
+	w.RegisterActivity(AddTrackToJellyfinActivity)
+	w.RegisterActivity(ImportJellyfinPlaylistActivity)
+	w.RegisterActivity(CreateSoundCloudPlaylistActivity)
+	w.RegisterActivity(SearchSoundCloudTrackActivity)
+	w.RegisterActivity(AddTrackToSoundCloudActivity)
+	w.RegisterActivity(ImportSoundCloudPlaylistActivity)
+	w.RegisterActivity(CreateListenBrainzPlaylistActivity)
+	w.RegisterActivity(SearchListenBrainzTrackActivity)
+	w.RegisterActivity(AddTrackToListenBrainzActivity)
+	w.RegisterActivity(ImportListenBrainzPlaylistActivity)
+	w.RegisterActivity(ImportLastFMPlaylistActivity)
 
 	log.Println("🚀 Temporal worker started on queue:", PlaylistSyncTaskQueue)
 	return w.Run(worker.InterruptCh())
@@ -20,6 +20,7 @@ const (
 )
 
 type PlaylistSyncInput struct {
+	JobID      uuid.UUID // sync job to record destinations and the outcome on; uuid.Nil for none
 	UserID     uuid.UUID
 	PlaylistID uuid.UUID
 	Platforms  []string
@@ -27,10 +28,17 @@ type PlaylistSyncInput struct {
 }
 
 type PlaylistSyncResult struct {
-	SpotifyPlaylistID string
-	YouTubePlaylistID string
-	TracksProcessed   int
-	TracksFailed      int
+	SpotifyPlaylistID      string
+	YouTubePlaylistID      string
+	AppleMusicPlaylistID   string
+	DeezerPlaylistID       string
+	TidalPlaylistID        string
+	SubsonicPlaylistID     string
+	JellyfinPlaylistID     string
+	SoundCloudPlaylistID   string
+	ListenBrainzPlaylistID string
+	TracksProcessed        int
+	TracksFailed           int
 }
 
 type TrackSyncProgress struct {
@@ -58,23 +66,30 @@ func PlaylistSyncWorkflow(ctx workflow.Context, input PlaylistSyncInput) (*Playl
 	var user db.User
 	err := workflow.ExecuteActivity(ctx, FetchUserActivity, input.UserID).Get(ctx, &user)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch user: %w", err)
+		err = fmt.Errorf("failed to fetch user: %w", err)
+		finishSyncJob(ctx, input.JobID, err)
+		return nil, err
 	}
 
 	var playlist db.Playlist
 	err = workflow.ExecuteActivity(ctx, FetchPlaylistActivity, input.PlaylistID, input.UserID).Get(ctx, &playlist)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch playlist: %w", err)
+		err = fmt.Errorf("failed to fetch playlist: %w", err)
+		finishSyncJob(ctx, input.JobID, err)
+		return nil, err
 	}
 
 	var tracks []db.Track
 	err = workflow.ExecuteActivity(ctx, FetchPlaylistTracksActivity, input.PlaylistID).Get(ctx, &tracks)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch tracks: %w", err)
+		err = fmt.Errorf("failed to fetch tracks: %w", err)
+		finishSyncJob(ctx, input.JobID, err)
+		return nil, err
 	}
 
 	if len(tracks) == 0 {
 		logger.Warn("No tracks found in playlist")
+		finishSyncJob(ctx, input.JobID, nil)
 		return &PlaylistSyncResult{TracksProcessed: 0}, nil
 	}
 
@@ -103,6 +118,7 @@ func PlaylistSyncWorkflow(ctx workflow.Context, input PlaylistSyncInput) (*Playl
 				continue
 			}
 			result.SpotifyPlaylistID = spotifyPlaylistID
+			recordSyncDestination(ctx, input.JobID, "spotify", spotifyPlaylistID)
 			logger.Info("Created Spotify playlist", "playlistID", spotifyPlaylistID)
 
 			for i, track := range tracks {
@@ -155,6 +171,7 @@ func PlaylistSyncWorkflow(ctx workflow.Context, input PlaylistSyncInput) (*Playl
 				continue
 			}
 			result.YouTubePlaylistID = ytPlaylistID
+			recordSyncDestination(ctx, input.JobID, "youtube", ytPlaylistID)
 			logger.Info("Created YouTube playlist", "playlistID", ytPlaylistID)
 
 			for i, track := range tracks {
@@ -177,6 +194,322 @@ func PlaylistSyncWorkflow(ctx workflow.Context, input PlaylistSyncInput) (*Playl
 					result.TracksProcessed++
 				}
 
+				// TEST MODE: Simulate rate limit after every N tracks
+				if testMode && (i+1)%TestRateLimitAfter == 0 && i+1 < len(tracks) {
+					logger.Warn("🚦 TEST MODE: Simulated rate limit hit! Pausing workflow...", "tracksProcessed", i+1, "pauseDuration", TestRateLimitDuration)
+					workflow.Sleep(ctx, TestRateLimitDuration)
+					logger.Info("🟢 TEST MODE: Resuming after simulated rate limit pause")
+				}
+			}
+
+		case "applemusic":
+			if user.AppleMusicToken == "" {
+				logger.Warn("No Apple Music token, skipping")
+				continue
+			}
+
+			var amPlaylistID string
+			err = workflow.ExecuteActivity(ctx, CreateAppleMusicPlaylistActivity, user, playlist).Get(ctx, &amPlaylistID)
+			if err != nil {
+				logger.Error("Failed to create Apple Music playlist", "error", err)
+				continue
+			}
+			result.AppleMusicPlaylistID = amPlaylistID
+			recordSyncDestination(ctx, input.JobID, "applemusic", amPlaylistID)
+			logger.Info("Created Apple Music playlist", "playlistID", amPlaylistID)
+
+			for i, track := range tracks {
+				logger.Info("Processing track", "index", i+1, "total", len(tracks), "title", track.Title)
+
+				songID := track.AppleMusicID
+				if songID == "" {
+					err = workflow.ExecuteActivity(ctx, SearchAppleMusicTrackActivity, user, track.Title, track.Artist).Get(ctx, &songID)
+					if err != nil || songID == "" {
+						logger.Warn("Track not found on Apple Music", "track", track.Title)
+						result.TracksFailed++
+						continue
+					}
+				}
+
+				err = workflow.ExecuteActivity(ctx, AddTrackToAppleMusicActivity, user, amPlaylistID, songID).Get(ctx, nil)
+				if err != nil {
+					logger.Warn("Failed to add track to Apple Music", "track", track.Title, "error", err)
+					result.TracksFailed++
+				} else {
+					logger.Info("Added track to Apple Music", "track", track.Title)
+					result.TracksProcessed++
+				}
+
+				// TEST MODE: Simulate rate limit after every N tracks
+				if testMode && (i+1)%TestRateLimitAfter == 0 && i+1 < len(tracks) {
+					logger.Warn("🚦 TEST MODE: Simulated rate limit hit! Pausing workflow...", "tracksProcessed", i+1, "pauseDuration", TestRateLimitDuration)
+					workflow.Sleep(ctx, TestRateLimitDuration)
+					logger.Info("🟢 TEST MODE: Resuming after simulated rate limit pause")
+				}
+			}
+		case "deezer":
+			if user.DeezerToken == "" {
+				logger.Warn("No Deezer token, skipping")
+				continue
+			}
+
+			var dzPlaylistID string
+			err = workflow.ExecuteActivity(ctx, CreateDeezerPlaylistActivity, user, playlist).Get(ctx, &dzPlaylistID)
+			if err != nil {
+				logger.Error("Failed to create Deezer playlist", "error", err)
+				continue
+			}
+			result.DeezerPlaylistID = dzPlaylistID
+			recordSyncDestination(ctx, input.JobID, "deezer", dzPlaylistID)
+			logger.Info("Created Deezer playlist", "playlistID", dzPlaylistID)
+
+			for i, track := range tracks {
+				logger.Info("Processing track", "index", i+1, "total", len(tracks), "title", track.Title)
+
+				trackID := track.DeezerID
+				if trackID == "" {
+					err = workflow.ExecuteActivity(ctx, SearchDeezerTrackActivity, user, track.Title, track.Artist, track.ISRC).Get(ctx, &trackID)
+					if err != nil || trackID == "" {
+						logger.Warn("Track not found on Deezer", "track", track.Title)
+						result.TracksFailed++
+						continue
+					}
+				}
+
+				err = workflow.ExecuteActivity(ctx, AddTrackToDeezerActivity, user, dzPlaylistID, trackID).Get(ctx, nil)
+				if err != nil {
+					logger.Warn("Failed to add track to Deezer", "track", track.Title, "error", err)
+					result.TracksFailed++
+				} else {
+					logger.Info("Added track to Deezer", "track", track.Title)
+					result.TracksProcessed++
+				}
+
+				// TEST MODE: Simulate rate limit after every N tracks
+				if testMode && (i+1)%TestRateLimitAfter == 0 && i+1 < len(tracks) {
+					logger.Warn("🚦 TEST MODE: Simulated rate limit hit! Pausing workflow...", "tracksProcessed", i+1, "pauseDuration", TestRateLimitDuration)
+					workflow.Sleep(ctx, TestRateLimitDuration)
+					logger.Info("🟢 TEST MODE: Resuming after simulated rate limit pause")
+				}
+			}
+		case "tidal":
+			if user.TidalToken == "" {
+				logger.Warn("No TIDAL token, skipping")
+				continue
+			}
+
+			var tdPlaylistID string
+			err = workflow.ExecuteActivity(ctx, CreateTidalPlaylistActivity, user, playlist).Get(ctx, &tdPlaylistID)
+			if err != nil {
+				logger.Error("Failed to create TIDAL playlist", "error", err)
+				continue
+			}
+			result.TidalPlaylistID = tdPlaylistID
+			recordSyncDestination(ctx, input.JobID, "tidal", tdPlaylistID)
+			logger.Info("Created TIDAL playlist", "playlistID", tdPlaylistID)
+
+			for i, track := range tracks {
+				logger.Info("Processing track", "index", i+1, "total", len(tracks), "title", track.Title)
+
+				trackID := track.TidalID
+				if trackID == "" {
+					err = workflow.ExecuteActivity(ctx, SearchTidalTrackActivity, user, track.Title, track.Artist, track.ISRC).Get(ctx, &trackID)
+					if err != nil || trackID == "" {
+						logger.Warn("Track not found on TIDAL", "track", track.Title)
+						result.TracksFailed++
+						continue
+					}
+				}
+
+				err = workflow.ExecuteActivity(ctx, AddTrackToTidalActivity, user, tdPlaylistID, trackID).Get(ctx, nil)
+				if err != nil {
+					logger.Warn("Failed to add track to TIDAL", "track", track.Title, "error", err)
+					result.TracksFailed++
+				} else {
+					logger.Info("Added track to TIDAL", "track", track.Title)
+					result.TracksProcessed++
+				}
+
+				// TEST MODE: Simulate rate limit after every N tracks
+				if testMode && (i+1)%TestRateLimitAfter == 0 && i+1 < len(tracks) {
+					logger.Warn("🚦 TEST MODE: Simulated rate limit hit! Pausing workflow...", "tracksProcessed", i+1, "pauseDuration", TestRateLimitDuration)
+					workflow.Sleep(ctx, TestRateLimitDuration)
+					logger.Info("🟢 TEST MODE: Resuming after simulated rate limit pause")
+				}
+			}
+		case "subsonic":
+			if user.SubsonicToken == "" {
+				logger.Warn("No Subsonic token, skipping")
+				continue
+			}
+
+			var ssPlaylistID string
+			err = workflow.ExecuteActivity(ctx, CreateSubsonicPlaylistActivity, user, playlist).Get(ctx, &ssPlaylistID)
+			if err != nil {
+				logger.Error("Failed to create Subsonic playlist", "error", err)
+				continue
+			}
+			result.SubsonicPlaylistID = ssPlaylistID
+			recordSyncDestination(ctx, input.JobID, "subsonic", ssPlaylistID)
+			logger.Info("Created Subsonic playlist", "playlistID", ssPlaylistID)
+
+			for i, track := range tracks {
+				logger.Info("Processing track", "index", i+1, "total", len(tracks), "title", track.Title)
+
+				trackID := track.SubsonicID
+				if trackID == "" {
+					err = workflow.ExecuteActivity(ctx, SearchSubsonicTrackActivity, user, track.Title, track.Artist).Get(ctx, &trackID)
+					if err != nil || trackID == "" {
+						logger.Warn("Track not found on Subsonic", "track", track.Title)
+						result.TracksFailed++
+						continue
+					}
+				}
+
+				err = workflow.ExecuteActivity(ctx, AddTrackToSubsonicActivity, user, ssPlaylistID, trackID).Get(ctx, nil)
+				if err != nil {
+					logger.Warn("Failed to add track to Subsonic", "track", track.Title, "error", err)
+					result.TracksFailed++
+				} else {
+					logger.Info("Added track to Subsonic", "track", track.Title)
+					result.TracksProcessed++
+				}
+
+				// TEST MODE: Simulate rate limit after every N tracks
+				if testMode && (i+1)%TestRateLimitAfter == 0 && i+1 < len(tracks) {
+					logger.Warn("🚦 TEST MODE: Simulated rate limit hit! Pausing workflow...", "tracksProcessed", i+1, "pauseDuration", TestRateLimitDuration)
+					workflow.Sleep(ctx, TestRateLimitDuration)
+					logger.Info("🟢 TEST MODE: Resuming after simulated rate limit pause")
+				}
+			}
+		case "jellyfin":
+			if user.JellyfinToken == "" {
+				logger.Warn("No Jellyfin token, skipping")
+				continue
+			}
+
+			var jfPlaylistID string
+			err = workflow.ExecuteActivity(ctx, CreateJellyfinPlaylistActivity, user, playlist).Get(ctx, &jfPlaylistID)
+			if err != nil {
+				logger.Error("Failed to create Jellyfin playlist", "error", err)
+				continue
+			}
+			result.JellyfinPlaylistID = jfPlaylistID
+			recordSyncDestination(ctx, input.JobID, "jellyfin", jfPlaylistID)
+			logger.Info("Created Jellyfin playlist", "playlistID", jfPlaylistID)
+
+			for i, track := range tracks {
+				logger.Info("Processing track", "index", i+1, "total", len(tracks), "title", track.Title)
+
+				trackID := track.JellyfinID
+				if trackID == "" {
+					err = workflow.ExecuteActivity(ctx, SearchJellyfinTrackActivity, user, track.Title, track.Artist, track.MusicBrainzID).Get(ctx, &trackID)
+					if err != nil || trackID == "" {
+						logger.Warn("Track not found on Jellyfin", "track", track.Title)
+						result.TracksFailed++
+						continue
+					}
+				}
+
+				err = workflow.ExecuteActivity(ctx, AddTrackToJellyfinActivity, user, jfPlaylistID, trackID).Get(ctx, nil)
+				if err != nil {
+					logger.Warn("Failed to add track to Jellyfin", "track", track.Title, "error", err)
+					result.TracksFailed++
+				} else {
+					logger.Info("Added track to Jellyfin", "track", track.Title)
+					result.TracksProcessed++
+				}
+
+				// TEST MODE: Simulate rate limit after every N tracks
+				if testMode && (i+1)%TestRateLimitAfter == 0 && i+1 < len(tracks) {
+					logger.Warn("🚦 TEST MODE: Simulated rate limit hit! Pausing workflow...", "tracksProcessed", i+1, "pauseDuration", TestRateLimitDuration)
+					workflow.Sleep(ctx, TestRateLimitDuration)
+					logger.Info("🟢 TEST MODE: Resuming after simulated rate limit pause")
+				}
+			}
+		case "soundcloud":
+			if user.SoundCloudToken == "" {
+				logger.Warn("No SoundCloud token, skipping")
+				continue
+			}
+
+			var scPlaylistID string
+			err = workflow.ExecuteActivity(ctx, CreateSoundCloudPlaylistActivity, user, playlist).Get(ctx, &scPlaylistID)
+			if err != nil {
+				logger.Error("Failed to create SoundCloud playlist", "error", err)
+				continue
+			}
+			result.SoundCloudPlaylistID = scPlaylistID
+			recordSyncDestination(ctx, input.JobID, "soundcloud", scPlaylistID)
+			logger.Info("Created SoundCloud playlist", "playlistID", scPlaylistID)
+
+			for i, track := range tracks {
+				logger.Info("Processing track", "index", i+1, "total", len(tracks), "title", track.Title)
+
+				trackID := track.SoundCloudID
+				if trackID == "" {
+					err = workflow.ExecuteActivity(ctx, SearchSoundCloudTrackActivity, user, track.Title, track.Artist).Get(ctx, &trackID)
+					if err != nil || trackID == "" {
+						logger.Warn("Track not found on SoundCloud", "track", track.Title)
+						result.TracksFailed++
+						continue
+					}
+				}
+
+				err = workflow.ExecuteActivity(ctx, AddTrackToSoundCloudActivity, user, scPlaylistID, trackID).Get(ctx, nil)
+				if err != nil {
+					logger.Warn("Failed to add track to SoundCloud", "track", track.Title, "error", err)
+					result.TracksFailed++
+				} else {
+					logger.Info("Added track to SoundCloud", "track", track.Title)
+					result.TracksProcessed++
+				}
+
+				// TEST MODE: Simulate rate limit after every N tracks
+				if testMode && (i+1)%TestRateLimitAfter == 0 && i+1 < len(tracks) {
+					logger.Warn("🚦 TEST MODE: Simulated rate limit hit! Pausing workflow...", "tracksProcessed", i+1, "pauseDuration", TestRateLimitDuration)
+					workflow.Sleep(ctx, TestRateLimitDuration)
+					logger.Info("🟢 TEST MODE: Resuming after simulated rate limit pause")
+				}
+			}
+		case "listenbrainz":
+			if user.ListenBrainzToken == "" {
+				logger.Warn("No ListenBrainz token, skipping")
+				continue
+			}
+
+			var lbPlaylistID string
+			err = workflow.ExecuteActivity(ctx, CreateListenBrainzPlaylistActivity, user, playlist).Get(ctx, &lbPlaylistID)
+			if err != nil {
+				logger.Error("Failed to create ListenBrainz playlist", "error", err)
+				continue
+			}
+			result.ListenBrainzPlaylistID = lbPlaylistID
+			recordSyncDestination(ctx, input.JobID, "listenbrainz", lbPlaylistID)
+			logger.Info("Created ListenBrainz playlist", "playlistID", lbPlaylistID)
+
+			for i, track := range tracks {
+				logger.Info("Processing track", "index", i+1, "total", len(tracks), "title", track.Title)
+
+				trackID := track.MusicBrainzID
+				if trackID == "" {
+					err = workflow.ExecuteActivity(ctx, SearchListenBrainzTrackActivity, user, track.Title, track.Artist).Get(ctx, &trackID)
+					if err != nil || trackID == "" {
+						logger.Warn("Track not found on ListenBrainz", "track", track.Title)
+						result.TracksFailed++
+						continue
+					}
+				}
+
+				err = workflow.ExecuteActivity(ctx, AddTrackToListenBrainzActivity, user, lbPlaylistID, trackID).Get(ctx, nil)
+				if err != nil {
+					logger.Warn("Failed to add track to ListenBrainz", "track", track.Title, "error", err)
+					result.TracksFailed++
+				} else {
+					logger.Info("Added track to ListenBrainz", "track", track.Title)
+					result.TracksProcessed++
+				}
+
 				// TEST MODE: Simulate rate limit after every N tracks
 				if testMode && (i+1)%TestRateLimitAfter == 0 && i+1 < len(tracks) {
 					logger.Warn("🚦 TEST MODE: Simulated rate limit hit! Pausing workflow...", "tracksProcessed", i+1, "pauseDuration", TestRateLimitDuration)
@@ -188,9 +521,34 @@ func PlaylistSyncWorkflow(ctx workflow.Context, input PlaylistSyncInput) (*Playl
 	}
 
 	logger.Info("PlaylistSyncWorkflow completed", "processed", result.TracksProcessed, "failed", result.TracksFailed)
+	finishSyncJob(ctx, input.JobID, nil)
 	return result, nil
 }
 
+// recordSyncDestination stores a created platform playlist on the sync job, like the worker pool does
+func recordSyncDestination(ctx workflow.Context, jobID uuid.UUID, platform, playlistID string) {
+	if jobID == uuid.Nil {
+		return
+	}
+	if err := workflow.ExecuteActivity(ctx, RecordSyncDestinationActivity, jobID, platform, playlistID).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to record sync destination", "platform", platform, "error", err)
+	}
+}
+
+// finishSyncJob marks the sync job completed, or failed with syncErr
+func finishSyncJob(ctx workflow.Context, jobID uuid.UUID, syncErr error) {
+	if jobID == uuid.Nil {
+		return
+	}
+	errMsg := ""
+	if syncErr != nil {
+		errMsg = syncErr.Error()
+	}
+	if err := workflow.ExecuteActivity(ctx, FinishSyncJobActivity, jobID, errMsg).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to update sync job", "jobID", jobID, "error", err)
+	}
+}
+
 type ImportPlaylistInput struct {
 	UserID   uuid.UUID
 	Platform string
@@ -223,6 +581,22 @@ func ImportPlaylistWorkflow(ctx workflow.Context, input ImportPlaylistInput) err
 		err = workflow.ExecuteActivity(ctx, ImportSpotifyPlaylistActivity, user, input.SourceID).Get(ctx, nil)
 	case "youtube":
 		err = workflow.ExecuteActivity(ctx, ImportYouTubePlaylistActivity, user, input.SourceID).Get(ctx, nil)
+	case "applemusic":
+		err = workflow.ExecuteActivity(ctx, ImportAppleMusicPlaylistActivity, user, input.SourceID).Get(ctx, nil)
+	case "deezer":
+		err = workflow.ExecuteActivity(ctx, ImportDeezerPlaylistActivity, user, input.SourceID).Get(ctx, nil)
+	case "tidal":
+		err = workflow.ExecuteActivity(ctx, ImportTidalPlaylistActivity, user, input.SourceID).Get(ctx, nil)
+	case "subsonic":
+		err = workflow.ExecuteActivity(ctx, ImportSubsonicPlaylistActivity, user, input.SourceID).Get(ctx, nil)
+	case "jellyfin":
+		err = workflow.ExecuteActivity(ctx, ImportJellyfinPlaylistActivity, user, input.SourceID).Get(ctx, nil)
+	case "soundcloud":
+		err = workflow.ExecuteActivity(ctx, ImportSoundCloudPlaylistActivity, user, input.SourceID).Get(ctx, nil)
+	case "lastfm":
+		err = workflow.ExecuteActivity(ctx, ImportLastFMPlaylistActivity, user, input.SourceID).Get(ctx, nil)
+	case "listenbrainz":
+		err = workflow.ExecuteActivity(ctx, ImportListenBrainzPlaylistActivity, user, input.SourceID).Get(ctx, nil)
 	default:
 		return fmt.Errorf("unknown platform: %s", input.Platform)
 	}
@@ -79,8 +79,18 @@ func (wp *WorkerPool) handleSyncJob(job Job) {
 		return
 	}
 
-	// Perform sync
-	result, err := services.SyncPlaylist(context.Background(), user, job.PlaylistID, job.Platforms)
+	// Restores pushed to existing destinations empty them first
+	if err := services.PrepareSyncJob(context.Background(), user, job.JobID); err != nil {
+		log.Printf("Failed to prepare sync job %s: %v", job.JobID, err)
+		db.DB.Model(&db.SyncJob{}).Where("id = ?", job.JobID).Updates(map[string]interface{}{
+			"status":    "failed",
+			"error_msg": err.Error(),
+		})
+		return
+	}
+
+	// Perform sync (resumes from checkpoints if this job ran before)
+	result, err := services.SyncPlaylist(context.Background(), user, job.PlaylistID, job.Platforms, job.JobID)
 	if err != nil {
 		log.Printf("Sync failed for playlist %s: %v", job.PlaylistID, err)
 		// Update status to "failed"
@@ -139,6 +149,54 @@ func (wp *WorkerPool) handleImportAllJob(job Job) {
 			} else {
 				log.Printf("Successfully imported YouTube playlists for user %s", user.Username)
 			}
+		} else if platform == "applemusic" {
+			if err := services.ImportAllAppleMusicPlaylists(context.Background(), user); err != nil {
+				log.Printf("Failed to import Apple Music playlists: %v", err)
+			} else {
+				log.Printf("Successfully imported Apple Music playlists for user %s", user.Username)
+			}
+		} else if platform == "deezer" {
+			if err := services.ImportAllDeezerPlaylists(context.Background(), user); err != nil {
+				log.Printf("Failed to import Deezer playlists: %v", err)
+			} else {
+				log.Printf("Successfully imported Deezer playlists for user %s", user.Username)
+			}
+		} else if platform == "tidal" {
+			if err := services.ImportAllTidalPlaylists(context.Background(), user); err != nil {
+				log.Printf("Failed to import TIDAL playlists: %v", err)
+			} else {
+				log.Printf("Successfully imported TIDAL playlists for user %s", user.Username)
+			}
+		} else if platform == "subsonic" {
+			if err := services.ImportAllSubsonicPlaylists(context.Background(), user); err != nil {
+				log.Printf("Failed to import Subsonic playlists: %v", err)
+			} else {
+				log.Printf("Successfully imported Subsonic playlists for user %s", user.Username)
+			}
+		} else if platform == "jellyfin" {
+			if err := services.ImportAllJellyfinPlaylists(context.Background(), user); err != nil {
+				log.Printf("Failed to import Jellyfin playlists: %v", err)
+			} else {
+				log.Printf("Successfully imported Jellyfin playlists for user %s", user.Username)
+			}
+		} else if platform == "soundcloud" {
+			if err := services.ImportAllSoundCloudPlaylists(context.Background(), user); err != nil {
+				log.Printf("Failed to import SoundCloud playlists: %v", err)
+			} else {
+				log.Printf("Successfully imported SoundCloud playlists for user %s", user.Username)
+			}
+		} else if platform == "lastfm" {
+			if err := services.ImportAllLastFMPlaylists(context.Background(), user); err != nil {
+				log.Printf("Failed to import Last.fm playlists: %v", err)
+			} else {
+				log.Printf("Successfully imported Last.fm playlists for user %s", user.Username)
+			}
+		} else if platform == "listenbrainz" {
+			if err := services.ImportAllListenBrainzPlaylists(context.Background(), user); err != nil {
+				log.Printf("Failed to import ListenBrainz playlists: %v", err)
+			} else {
+				log.Printf("Successfully imported ListenBrainz playlists for user %s", user.Username)
+			}
 		}
 	}
 }
@@ -160,7 +218,7 @@ func (wp *WorkerPool) handleImportPlaylistJob(job Job) {
 	}
 
 	if platform == "spotify" {
-		client, err := services.GetSpotifyClient(context.Background(), user)
+		client, err := services.GetSpotifyReadClient(context.Background(), user)
 		if err != nil {
 			log.Printf("Failed to get Spotify client: %v", err)
 			return
@@ -169,7 +227,7 @@ func (wp *WorkerPool) handleImportPlaylistJob(job Job) {
 			log.Printf("Failed to import Spotify playlist: %v", err)
 		}
 	} else if platform == "youtube" {
-		client, err := services.GetYouTubeClient(context.Background(), user)
+		client, err := services.GetYouTubeReadClient(context.Background(), user)
 		if err != nil {
 			log.Printf("Failed to get YouTube client: %v", err)
 			return
@@ -177,6 +235,78 @@ func (wp *WorkerPool) handleImportPlaylistJob(job Job) {
 		if err := services.ImportYouTubePlaylist(context.Background(), client, user, sourceID); err != nil {
 			log.Printf("Failed to import YouTube playlist: %v", err)
 		}
+	} else if platform == "applemusic" {
+		client, err := services.GetAppleMusicClient(context.Background(), user)
+		if err != nil {
+			log.Printf("Failed to get Apple Music client: %v", err)
+			return
+		}
+		if err := services.ImportAppleMusicPlaylist(context.Background(), client, user, sourceID); err != nil {
+			log.Printf("Failed to import Apple Music playlist: %v", err)
+		}
+	} else if platform == "deezer" {
+		client, err := services.GetDeezerClient(context.Background(), user)
+		if err != nil {
+			log.Printf("Failed to get Deezer client: %v", err)
+			return
+		}
+		if err := services.ImportDeezerPlaylist(context.Background(), client, user, sourceID); err != nil {
+			log.Printf("Failed to import Deezer playlist: %v", err)
+		}
+	} else if platform == "tidal" {
+		client, err := services.GetTidalClient(context.Background(), user)
+		if err != nil {
+			log.Printf("Failed to get TIDAL client: %v", err)
+			return
+		}
+		if err := services.ImportTidalPlaylist(context.Background(), client, user, sourceID); err != nil {
+			log.Printf("Failed to import TIDAL playlist: %v", err)
+		}
+	} else if platform == "subsonic" {
+		client, err := services.GetSubsonicClient(context.Background(), user)
+		if err != nil {
+			log.Printf("Failed to get Subsonic client: %v", err)
+			return
+		}
+		if err := services.ImportSubsonicPlaylist(context.Background(), client, user, sourceID); err != nil {
+			log.Printf("Failed to import Subsonic playlist: %v", err)
+		}
+	} else if platform == "jellyfin" {
+		client, err := services.GetJellyfinClient(context.Background(), user)
+		if err != nil {
+			log.Printf("Failed to get Jellyfin client: %v", err)
+			return
+		}
+		if err := services.ImportJellyfinPlaylist(context.Background(), client, user, sourceID); err != nil {
+			log.Printf("Failed to import Jellyfin playlist: %v", err)
+		}
+	} else if platform == "soundcloud" {
+		client, err := services.GetSoundCloudClient(context.Background(), user)
+		if err != nil {
+			log.Printf("Failed to get SoundCloud client: %v", err)
+			return
+		}
+		if err := services.ImportSoundCloudPlaylist(context.Background(), client, user, sourceID); err != nil {
+			log.Printf("Failed to import SoundCloud playlist: %v", err)
+		}
+	} else if platform == "lastfm" {
+		client, err := services.GetLastFMClient(context.Background(), user)
+		if err != nil {
+			log.Printf("Failed to get Last.fm client: %v", err)
+			return
+		}
+		if err := services.ImportLastFMPlaylist(context.Background(), client, user, sourceID); err != nil {
+			log.Printf("Failed to import Last.fm playlist: %v", err)
+		}
+	} else if platform == "listenbrainz" {
+		client, err := services.GetListenBrainzClient(context.Background(), user)
+		if err != nil {
+			log.Printf("Failed to get ListenBrainz client: %v", err)
+			return
+		}
+		if err := services.ImportListenBrainzPlaylist(context.Background(), client, user, sourceID); err != nil {
+			log.Printf("Failed to import ListenBrainz playlist: %v", err)
+		}
 	}
 }
 
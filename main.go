@@ -8,6 +8,7 @@ import (
 	"EchoBridge/internal/auth"
 	"EchoBridge/internal/handlers"
 	"EchoBridge/internal/scheduler"
+	"EchoBridge/internal/services"
 	"EchoBridge/internal/temporal"
 	"EchoBridge/internal/worker"
 
@@ -38,6 +39,11 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// Worker pool jobs don't survive a restart; flag them so they can be resumed
+	if err := services.MarkInterruptedSyncJobs(); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+
 	// Initialize Temporal Client
 	if err := temporal.InitClient(); err != nil {
 		log.Printf("⚠️ Failed to connect to Temporal (running without workflows): %v", err)
@@ -53,6 +59,7 @@ func main() {
 
 	//  Start Scheduler
 	scheduler.StartCategorizationScheduler(syncWorker)
+	scheduler.StartPlaylistEventPruning()
 
 	r := gin.Default()
 	frontendURL := os.Getenv("FRONTEND_URL")
@@ -70,6 +77,8 @@ func main() {
 
 	handlers.RegisterAuthRoutes(r)
 	handlers.RegisterPlaylistRoutes(r)
+	handlers.RegisterShareRoutes(r)
+	handlers.RegisterProfileRoutes(r)
 
 	// Serve Frontend Static Files
 	r.Static("/_app", "./web/_app")